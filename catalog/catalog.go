@@ -0,0 +1,379 @@
+package catalog
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"idebug/plugin"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 分页方式
+const (
+	PaginationNone      = "none"       // 不分页
+	PaginationPageToken = "page_token" // 飞书page_token分页
+	PaginationCursor    = "cursor"     // 企业微信cursor分页
+)
+
+// 参数位置
+const (
+	InQuery = "query"
+	InPath  = "path"
+	InBody  = "body"
+)
+
+//go:embed builtin/*.yaml
+var builtin embed.FS
+
+type Param struct {
+	Name     string `yaml:"name"`
+	In       string `yaml:"in"`       // query、path、body,默认query
+	Required bool   `yaml:"required"` // 是否必填
+	Default  string `yaml:"default"`  // 默认值
+	Desc     string `yaml:"desc"`     // 说明
+}
+
+type Pagination struct {
+	Style        string `yaml:"style"`          // none、page_token、cursor,默认none
+	PageSize     int    `yaml:"page_size"`      // 分页大小,为0时不传
+	TokenParam   string `yaml:"token_param"`    // 请求中的分页标记参数名,page_token默认page_token,cursor默认cursor
+	SizeParam    string `yaml:"size_param"`     // 请求中的分页大小参数名,page_token默认page_size,cursor默认limit
+	NextField    string `yaml:"next_field"`     // 响应中下一页标记的路径,page_token默认data.page_token,cursor默认next_cursor
+	HasMoreField string `yaml:"has_more_field"` // 响应中是否还有下一页的路径,page_token默认data.has_more,cursor不使用
+}
+
+type Endpoint struct {
+	Name       string     `yaml:"name"`
+	Module     string     `yaml:"module"` // wechat、feishu
+	Desc       string     `yaml:"desc"`
+	Method     string     `yaml:"method"` // 默认GET
+	Path       string     `yaml:"path"`   // 接口路径,路径参数使用:name
+	Params     []*Param   `yaml:"params"`
+	Pagination Pagination `yaml:"pagination"`
+	ListField  string     `yaml:"list_field"` // 响应中列表的路径,如data.items,为空时不导出
+	Source     string     `yaml:"-"`          // 定义所在的文件
+}
+
+type file struct {
+	Endpoints []*Endpoint `yaml:"endpoints"`
+}
+
+type Catalog struct {
+	endpoints map[string]*Endpoint // 键为module/name
+}
+
+// Dir 返回用户自定义目录的路径,目录下的*.yaml和*.yml会在内置目录之后加载,同名接口覆盖内置接口
+func Dir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join(".idebug", "catalog")
+	}
+	return filepath.Join(dir, "idebug", "catalog")
+}
+
+// Load 加载内置目录和用户自定义目录,解析失败的文件会跳过并返回错误
+func Load() (*Catalog, []error) {
+	c := &Catalog{endpoints: map[string]*Endpoint{}}
+	var errs []error
+	entries, _ := builtin.ReadDir("builtin")
+	for _, entry := range entries {
+		data, err := builtin.ReadFile("builtin/" + entry.Name())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err = c.add(data, "内置:"+entry.Name()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	var files []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, _ := filepath.Glob(filepath.Join(Dir(), pattern))
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err = c.add(data, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return c, errs
+}
+
+func (c *Catalog) add(data []byte, source string) error {
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	for i, endpoint := range f.Endpoints {
+		if endpoint.Name == "" || endpoint.Path == "" {
+			return fmt.Errorf("%s: 第%d个接口缺少name或者path", source, i+1)
+		}
+		// 只允许接口域名下的路径,目录文件可能来自他人,完整URL会将凭证发送至其它主机
+		if !strings.HasPrefix(endpoint.Path, "/") || strings.HasPrefix(endpoint.Path, "//") || strings.Contains(endpoint.Path, "://") {
+			return fmt.Errorf("%s: 接口%s的path必须以/开头,不能包含协议或者主机", source, endpoint.Name)
+		}
+		if endpoint.Module != "wechat" && endpoint.Module != "feishu" {
+			return fmt.Errorf("%s: 接口%s的module只能为wechat或者feishu", source, endpoint.Name)
+		}
+		if endpoint.Name == "ls" || endpoint.Name == "reload" {
+			return fmt.Errorf("%s: 接口名称%s为保留名称", source, endpoint.Name)
+		}
+		endpoint.Method = strings.ToUpper(endpoint.Method)
+		if endpoint.Method == "" {
+			endpoint.Method = "GET"
+		}
+		endpoint.Pagination.fillDefault(endpoint.Method)
+		endpoint.Source = source
+		c.endpoints[endpoint.Module+"/"+endpoint.Name] = endpoint
+	}
+	return nil
+}
+
+func (p *Pagination) fillDefault(method string) {
+	switch p.Style {
+	case PaginationPageToken:
+		if p.TokenParam == "" {
+			p.TokenParam = "page_token"
+		}
+		if p.SizeParam == "" {
+			p.SizeParam = "page_size"
+		}
+		if p.NextField == "" {
+			p.NextField = "data.page_token"
+		}
+		if p.HasMoreField == "" {
+			p.HasMoreField = "data.has_more"
+		}
+	case PaginationCursor:
+		if p.TokenParam == "" {
+			p.TokenParam = "cursor"
+		}
+		if p.SizeParam == "" {
+			p.SizeParam = "limit"
+		}
+		if p.NextField == "" {
+			p.NextField = "next_cursor"
+		}
+	default:
+		p.Style = PaginationNone
+	}
+}
+
+// Find 根据模块和名称查找接口
+func (c *Catalog) Find(module, name string) *Endpoint {
+	return c.endpoints[module+"/"+name]
+}
+
+// Endpoints 返回模块下的所有接口,按名称排序
+func (c *Catalog) Endpoints(module string) []*Endpoint {
+	var endpoints []*Endpoint
+	for _, endpoint := range c.endpoints {
+		if endpoint.Module == module {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		return endpoints[i].Name < endpoints[j].Name
+	})
+	return endpoints
+}
+
+// Names 返回模块下的所有接口名称,用于补全
+func (c *Catalog) Names(module string) []string {
+	var names []string
+	for _, endpoint := range c.Endpoints(module) {
+		names = append(names, endpoint.Name)
+	}
+	return names
+}
+
+// Fetch 按参数调用接口并自动翻页,maxPages为0时获取所有页,返回列表和最后一页的响应
+func (e *Endpoint) Fetch(args map[string]string, maxPages int, call plugin.CallFunc) ([]any, map[string]any, error) {
+	path := e.Path
+	var query [][2]string
+	body := map[string]any{}
+	for _, param := range e.Params {
+		value, ok := args[param.Name]
+		if !ok {
+			value = param.Default
+		}
+		if value == "" {
+			if param.Required {
+				return nil, nil, fmt.Errorf("缺少参数%s", param.Name)
+			}
+			continue
+		}
+		switch param.In {
+		case InPath:
+			path = strings.Replace(path, ":"+param.Name, value, 1)
+		case InBody:
+			body[param.Name] = parseValue(value)
+		default:
+			query = append(query, [2]string{param.Name, value})
+		}
+	}
+	for name := range args {
+		if e.param(name) == nil {
+			return nil, nil, fmt.Errorf("未知参数%s", name)
+		}
+	}
+	pagination := e.Pagination
+	// cursor分页的POST接口分页参数位于请求体,其它情况位于查询参数
+	inBody := pagination.Style == PaginationCursor && e.Method != "GET"
+	if pagination.Style != PaginationNone && pagination.PageSize > 0 {
+		if inBody {
+			body[pagination.SizeParam] = pagination.PageSize
+		} else {
+			query = append(query, [2]string{pagination.SizeParam, strconv.Itoa(pagination.PageSize)})
+		}
+	}
+	var items []any
+	var token string
+	for page := 1; ; page++ {
+		pageQuery := query
+		if token != "" {
+			if inBody {
+				body[pagination.TokenParam] = token
+			} else {
+				pageQuery = append(append([][2]string{}, query...), [2]string{pagination.TokenParam, token})
+			}
+		}
+		var data []byte
+		if e.Method != "GET" {
+			var err error
+			if data, err = json.Marshal(body); err != nil {
+				return items, nil, err
+			}
+		}
+		response, err := call(e.Method, path, pageQuery, data)
+		if err != nil {
+			return items, nil, err
+		}
+		var result map[string]any
+		if err = json.Unmarshal(response.Body, &result); err != nil {
+			return items, nil, fmt.Errorf("%s: %s", response.Status, strings.TrimSpace(string(response.Body)))
+		}
+		if err = checkResult(result, response); err != nil {
+			return items, result, err
+		}
+		if e.ListField != "" {
+			list, _ := Lookup(result, e.ListField).([]any)
+			items = append(items, list...)
+		}
+		if pagination.Style == PaginationNone || (maxPages > 0 && page >= maxPages) {
+			return items, result, nil
+		}
+		token, _ = Lookup(result, pagination.NextField).(string)
+		if pagination.HasMoreField != "" {
+			if hasMore, _ := Lookup(result, pagination.HasMoreField).(bool); !hasMore {
+				return items, result, nil
+			}
+		}
+		if token == "" {
+			return items, result, nil
+		}
+	}
+}
+
+func (e *Endpoint) param(name string) *Param {
+	for _, param := range e.Params {
+		if param.Name == name {
+			return param
+		}
+	}
+	return nil
+}
+
+// checkResult 检查飞书的code和企业微信的errcode,不为0时返回*plugin.APIError
+func checkResult(result map[string]any, response *plugin.RawResponse) error {
+	for _, field := range [][3]string{{"code", "msg", plugin.PlatformFeishu}, {"errcode", "errmsg", plugin.PlatformWechat}} {
+		if code, ok := result[field[0]].(float64); ok && code != 0 {
+			msg, _ := result[field[1]].(string)
+			return plugin.NewRawAPIError(field[2], int(code), msg, response)
+		}
+	}
+	return nil
+}
+
+// parseValue 请求体参数为合法JSON时按JSON解析,否则作为字符串
+func parseValue(value string) any {
+	var v any
+	if err := json.Unmarshal([]byte(value), &v); err == nil {
+		return v
+	}
+	return value
+}
+
+// Lookup 根据以点分隔的路径获取字段值
+func Lookup(v any, path string) any {
+	for _, key := range strings.Split(path, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+// Flatten 将列表元素展开为以点分隔的键,数组序列化为JSON字符串,非对象元素的键为value
+func Flatten(item any) map[string]string {
+	result := map[string]string{}
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		if m, ok := v.(map[string]any); ok {
+			for key, value := range m {
+				if prefix != "" {
+					key = prefix + "." + key
+				}
+				walk(key, value)
+			}
+			return
+		}
+		if prefix == "" {
+			prefix = "value"
+		}
+		switch value := v.(type) {
+		case nil:
+			result[prefix] = ""
+		case string:
+			result[prefix] = value
+		case float64:
+			result[prefix] = strconv.FormatFloat(value, 'f', -1, 64)
+		case bool:
+			result[prefix] = strconv.FormatBool(value)
+		default:
+			data, _ := json.Marshal(value)
+			result[prefix] = string(data)
+		}
+	}
+	walk("", item)
+	return result
+}
+
+// Columns 返回所有列表元素展开后的键,按字母顺序排序
+func Columns(items []any) []string {
+	set := map[string]bool{}
+	for _, item := range items {
+		for key := range Flatten(item) {
+			set[key] = true
+		}
+	}
+	var columns []string
+	for key := range set {
+		columns = append(columns, key)
+	}
+	sort.Strings(columns)
+	return columns
+}
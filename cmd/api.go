@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"idebug/catalog"
+	"idebug/logger"
+	"idebug/plugin"
+	"strings"
+	"sync"
+)
+
+var (
+	apiCatalog     *catalog.Catalog
+	apiCatalogLock sync.Mutex
+)
+
+// getCatalog 加载接口目录,同一进程内只加载一次,执行api reload后重新加载
+func getCatalog() *catalog.Catalog {
+	apiCatalogLock.Lock()
+	defer apiCatalogLock.Unlock()
+	if apiCatalog == nil {
+		var errs []error
+		apiCatalog, errs = catalog.Load()
+		for _, err := range errs {
+			logger.Warning("接口目录加载失败: " + err.Error())
+		}
+	}
+	return apiCatalog
+}
+
+// CatalogNames 返回模块下的接口名称,用于命令补全
+func CatalogNames(module Module) []string {
+	return getCatalog().Names(string(module))
+}
+
+// newApi 根据接口目录生成api命令,每个接口为一个子命令,call负责注入凭证并发送请求
+func newApi(module Module, call plugin.CallFunc) *cobra.Command {
+	api := &cobra.Command{
+		Use:   `api`,
+		Short: `调用接口目录中的接口`,
+	}
+	ls := &cobra.Command{
+		Use:   `ls`,
+		Short: `查看接口目录`,
+		Run: func(cmd *cobra.Command, args []string) {
+			endpoints := getCatalog().Endpoints(string(module))
+			if len(endpoints) == 0 {
+				logger.Info("无可用接口")
+				return
+			}
+			for _, endpoint := range endpoints {
+				fmt.Printf("  -%s %s %s [%s]\n", endpoint.Name, endpoint.Method, endpoint.Path, endpoint.Desc)
+				for _, param := range endpoint.Params {
+					in := param.In
+					if in == "" {
+						in = catalog.InQuery
+					}
+					var attrs []string
+					if param.Required {
+						attrs = append(attrs, "必填")
+					}
+					if param.Default != "" {
+						attrs = append(attrs, "默认值: "+param.Default)
+					}
+					fmt.Printf("      %s(%s) %s %s\n", param.Name, in, param.Desc, strings.Join(attrs, ","))
+				}
+			}
+			logger.Info(fmt.Sprintf("自定义接口目录: %s", catalog.Dir()))
+		},
+	}
+	reload := &cobra.Command{
+		Use:   `reload`,
+		Short: `重新加载接口目录`,
+		Run: func(cmd *cobra.Command, args []string) {
+			apiCatalogLock.Lock()
+			apiCatalog = nil
+			apiCatalogLock.Unlock()
+			logger.Success(fmt.Sprintf("已加载%d个接口", len(getCatalog().Endpoints(string(module)))))
+		},
+	}
+	api.AddCommand(ls, reload)
+	for _, endpoint := range getCatalog().Endpoints(string(module)) {
+		api.AddCommand(newApiEndpoint(module, endpoint, call))
+	}
+	return api
+}
+
+func newApiEndpoint(module Module, endpoint *catalog.Endpoint, call plugin.CallFunc) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   endpoint.Name,
+		Short: endpoint.Desc,
+		Run: func(cmd *cobra.Command, args []string) {
+			params := map[string]string{}
+			for _, kv := range args {
+				k, v, ok := strings.Cut(kv, "=")
+				if !ok {
+					logger.Error(fmt.Errorf("错误的参数 %s,格式为k=v", kv))
+					return
+				}
+				params[k] = v
+			}
+			items, result, err := endpoint.Fetch(params, apiMaxPages, call)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				logger.Error(logger.FormatError(err))
+				if len(items) == 0 {
+					return
+				}
+				logger.Warning("查询中断,会保存已获取数据")
+			}
+			if HttpCanceled {
+				return
+			}
+			if endpoint.ListField == "" {
+				data, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(data))
+				return
+			}
+			if len(items) == 0 {
+				logger.Info("无可用数据")
+				return
+			}
+			for _, item := range items {
+				data, _ := json.Marshal(item)
+				fmt.Printf("  -%s\n", data)
+			}
+			logger.Info(fmt.Sprintf("共%d条数据", len(items)))
+			saveExport(items, fmt.Sprintf("%s_api_%s", module, endpoint.Name), func() ([]any, [][]any) {
+				columns := catalog.Columns(items)
+				headers := []any{"id"}
+				for _, column := range columns {
+					headers = append(headers, column)
+				}
+				var data [][]any
+				for i, item := range items {
+					flat := catalog.Flatten(item)
+					row := []any{i + 1}
+					for _, column := range columns {
+						row = append(row, flat[column])
+					}
+					data = append(data, row)
+				}
+				return headers, data
+			})
+		},
+	}
+	cmd.Flags().IntVar(&apiMaxPages, "pages", 0, "最多获取的页数,默认获取所有页")
+	return cmd
+}
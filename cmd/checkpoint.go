@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	fs "idebug/plugin/feishu"
+	"idebug/plugin/wechat"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// checkpointInterval 获取部门时保存断点的最小间隔
+const checkpointInterval = 3 * time.Second
+
+// dumpCheckpoint 导出的断点,记录已获取的部门,中断后通过dump --resume继续时不再重复获取
+type dumpCheckpoint struct {
+	Platform    string                    `json:"platform"`
+	Key         string                    `json:"key"` // 飞书为app_id,企业微信为corpid(suite模式为授权企业corpid)
+	DeptIds     []string                  `json:"dept_ids"`
+	DeptIdType  string                    `json:"dept_id_type"`
+	UserIdType  string                    `json:"user_id_type"`
+	AllScope    bool                      `json:"all_scope"`             // 未指定部门,导出通讯录授权范围
+	Roots       map[string]*crawlRecord   `json:"roots"`                 // 起始部门的详情、主管和直属用户
+	Visited     map[string]*crawlRecord   `json:"visited"`               // 已获取子部门的部门,企业微信为已获取直属成员的部门
+	Departments []*wechat.DepartmentEntry `json:"departments,omitempty"` // 企业微信的部门列表,继续时不再重新获取
+	UpdatedAt   time.Time                 `json:"updated_at"`
+	lock        sync.Mutex
+	savedAt     time.Time
+}
+
+// crawlRecord 已获取的部门,Department只有起始部门会保存
+type crawlRecord struct {
+	Department     *fs.DepartmentEntry   `json:"department,omitempty"`
+	LeaderUserName string                `json:"leader_user_name,omitempty"`
+	Users          []*fs.UserEntry       `json:"users,omitempty"`
+	Children       []*fs.DepartmentEntry `json:"children,omitempty"`
+	WechatUsers    []*wechat.UserEntry   `json:"wechat_users,omitempty"` // 企业微信部门的直属成员
+}
+
+func newDumpCheckpoint(platform, key string, deptIds []string, deptIdType, userIdType string, allScope bool) *dumpCheckpoint {
+	return &dumpCheckpoint{
+		Platform:   platform,
+		Key:        key,
+		DeptIds:    deptIds,
+		DeptIdType: deptIdType,
+		UserIdType: userIdType,
+		AllScope:   allScope,
+		Roots:      map[string]*crawlRecord{},
+		Visited:    map[string]*crawlRecord{},
+		savedAt:    time.Now(),
+	}
+}
+
+// loadDumpCheckpoint 加载平台和企业或应用对应的断点
+func loadDumpCheckpoint(platform, key string) (*dumpCheckpoint, error) {
+	data, err := os.ReadFile(checkpointFile(platform, key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New("没有可以继续的导出,请先执行dump")
+		}
+		return nil, err
+	}
+	c := &dumpCheckpoint{}
+	if err = json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("断点文件格式错误: %w", err)
+	}
+	if c.Roots == nil {
+		c.Roots = map[string]*crawlRecord{}
+	}
+	if c.Visited == nil {
+		c.Visited = map[string]*crawlRecord{}
+	}
+	c.savedAt = time.Now()
+	return c, nil
+}
+
+func checkpointFile(platform, key string) string {
+	return filepath.Join(configDir("dump"), fmt.Sprintf("%s_%s.json", platform, key))
+}
+
+// root 返回已获取的起始部门,c为nil时表示不使用断点
+func (c *dumpCheckpoint) root(id string) (*crawlRecord, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	rec, ok := c.Roots[id]
+	return rec, ok
+}
+
+// visited 返回已获取子部门的部门
+func (c *dumpCheckpoint) visited(id string) (*crawlRecord, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	rec, ok := c.Visited[id]
+	return rec, ok
+}
+
+// putDepartments 记录企业微信的部门列表
+func (c *dumpCheckpoint) putDepartments(depts []*wechat.DepartmentEntry) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.Departments = depts
+	c.UpdatedAt = time.Now()
+}
+
+func (c *dumpCheckpoint) putRoot(id string, rec *crawlRecord) {
+	c.put(c.Roots, id, rec)
+}
+
+func (c *dumpCheckpoint) putVisited(id string, rec *crawlRecord) {
+	c.put(c.Visited, id, rec)
+}
+
+// put 记录部门,距离上次保存超过checkpointInterval时保存至磁盘,保存失败时在结束时重试
+func (c *dumpCheckpoint) put(m map[string]*crawlRecord, id string, rec *crawlRecord) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	m[id] = rec
+	c.UpdatedAt = time.Now()
+	if time.Since(c.savedAt) >= checkpointInterval {
+		_ = c.save()
+	}
+}
+
+// Count 返回已获取的部门数量
+func (c *dumpCheckpoint) Count() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.Visited)
+}
+
+func (c *dumpCheckpoint) Save() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.save()
+}
+
+// save 先写入临时文件再重命名,中断时不会留下不完整的断点
+func (c *dumpCheckpoint) save() error {
+	c.savedAt = time.Now()
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(configDir("dump"), 0700); err != nil {
+		return err
+	}
+	file := checkpointFile(c.Platform, c.Key)
+	if err = os.WriteFile(file+".tmp", data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+// Remove 导出完成后删除断点
+func (c *dumpCheckpoint) Remove() error {
+	if err := os.Remove(checkpointFile(c.Platform, c.Key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"idebug/logger"
+	fs "idebug/plugin/feishu"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultWorkers 并发获取部门的默认数量
+const defaultWorkers = 5
+
+// departmentCrawler 并发获取飞书部门树,最多workers个部门同时获取,请求共享客户端的限速器,
+// checkpoint不为nil时跳过断点中已获取的部门,并记录新获取的部门
+type departmentCrawler struct {
+	cli        *feiShuCli
+	deptIdType string
+	userIdType string
+	checkpoint *dumpCheckpoint
+	sem        chan struct{}
+	wg         sync.WaitGroup
+	count      int64 // 已获取的部门数量
+	errOnce    sync.Once
+	err        error
+	failed     int32
+	canceled   int32 // 取消后run返回context.Canceled,调用方据此判断部门树不完整
+}
+
+// crawlTask 获取部门的子部门,detail为true时同时获取直属用户,主管姓名在创建节点时已获取
+type crawlTask struct {
+	node   *FeiShuDepartmentNode
+	id     string
+	detail bool
+}
+
+func newDepartmentCrawler(cli *feiShuCli, deptIdType, userIdType string, workers int, checkpoint *dumpCheckpoint) *departmentCrawler {
+	if workers < 1 {
+		workers = 1
+	}
+	return &departmentCrawler{
+		cli:        cli,
+		deptIdType: deptIdType,
+		userIdType: userIdType,
+		checkpoint: checkpoint,
+		sem:        make(chan struct{}, workers),
+	}
+}
+
+// run 从node开始获取所有下级部门,node本身的主管和直属用户由调用方获取,
+// 子部门按接口返回的顺序挂载,结果与并发数无关
+func (c *departmentCrawler) run(node *FeiShuDepartmentNode, deptId string) error {
+	c.spawn(crawlTask{node: node, id: deptId})
+	c.wg.Wait()
+	if c.err != nil {
+		return c.err
+	}
+	if atomic.LoadInt32(&c.canceled) == 1 {
+		return context.Canceled
+	}
+	logger.Info(fmt.Sprintf("共获取%d个子部门", atomic.LoadInt64(&c.count)))
+	return nil
+}
+
+func (c *departmentCrawler) spawn(task crawlTask) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.sem <- struct{}{}
+		children, err := c.visit(task)
+		<-c.sem
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		for _, child := range children {
+			c.spawn(child)
+		}
+	}()
+}
+
+// stopped 出现错误或者取消时不再发送新的请求
+func (c *departmentCrawler) stopped() bool {
+	if interrupted() {
+		atomic.StoreInt32(&c.canceled, 1)
+		return true
+	}
+	return atomic.LoadInt32(&c.failed) == 1 || atomic.LoadInt32(&c.canceled) == 1
+}
+
+func (c *departmentCrawler) fail(err error) {
+	if errors.Is(err, context.Canceled) || interrupted() {
+		atomic.StoreInt32(&c.canceled, 1)
+		return
+	}
+	c.errOnce.Do(func() {
+		c.err = err
+		atomic.StoreInt32(&c.failed, 1)
+	})
+}
+
+func (c *departmentCrawler) visit(task crawlTask) ([]crawlTask, error) {
+	if c.stopped() {
+		return nil, nil
+	}
+	node := task.node
+	if rec, ok := c.checkpoint.visited(task.id); ok {
+		if task.detail {
+			node.LeaderUserName = rec.LeaderUserName
+			node.User = append(node.User, rec.Users...)
+			atomic.AddInt64(&c.count, 1)
+		}
+		return c.attach(node, rec.Children), nil
+	}
+	rec := &crawlRecord{}
+	if task.detail {
+		logger.Info(fmt.Sprintf("正在获取部门[%s]直属用户和子部门...", task.id))
+		req := fs.NewGetUsersByDepartmentIdReqBuilder(FeiShuClient).
+			DepartmentId(task.id).
+			DepartmentIdType(c.deptIdType).UserIdType(c.userIdType).Build()
+		users, err := FeiShuClient.User.GetUsersByDepartmentId(req)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) && !interrupted() {
+				logger.Info(fmt.Sprintf("部门[%s]直属用户列表获取失败,终止获取", task.id))
+			}
+			return nil, err
+		}
+		indexFeiShuUsers(users)
+		node.User = append(node.User, users...)
+		rec.LeaderUserName, rec.Users = node.LeaderUserName, users
+		atomic.AddInt64(&c.count, 1)
+	}
+	if c.stopped() {
+		return nil, nil
+	}
+	req := fs.NewGetDepartmentChildrenReqBuilder(FeiShuClient).
+		DepartmentId(task.id).
+		DepartmentIdType(c.deptIdType).
+		UserIdType(c.userIdType).
+		Fetch(false).
+		PageSize(50).
+		Build()
+	deptChildren, err := FeiShuClient.Department.Children(req)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) && !interrupted() {
+			logger.Info(fmt.Sprintf("部门[%s]的子部门获取失败,终止获取", task.id))
+		}
+		return nil, err
+	}
+	for _, child := range deptChildren {
+		indexFeiShuDepartment(child)
+	}
+	rec.Children = deptChildren
+	// 直属用户和子部门都获取后才记录,中断时该部门会重新获取
+	c.checkpoint.putVisited(task.id, rec)
+	return c.attach(node, deptChildren), nil
+}
+
+// attach 将子部门挂载至node,子部门列表已包含部门详情,无需再逐个获取
+func (c *departmentCrawler) attach(node *FeiShuDepartmentNode, children []*fs.DepartmentEntry) []crawlTask {
+	var tasks []crawlTask
+	for _, child := range children {
+		id := child.OpenDepartmentID
+		if c.deptIdType == "department_id" {
+			id = child.DepartmentID
+		}
+		childNode := c.cli.newDepartmentNode(child, c.deptIdType, c.userIdType)
+		childNode.ParentDepartmentName = node.Name
+		node.Children = append(node.Children, childNode)
+		tasks = append(tasks, crawlTask{node: childNode, id: id, detail: true})
+	}
+	return tasks
+}
+
+// interrupted 当前命令是否已取消,Ctrl+C时先取消上下文再设置HttpCanceled,只判断HttpCanceled可能遗漏
+func interrupted() bool {
+	return HttpCanceled || (Context != nil && Context.Err() != nil)
+}
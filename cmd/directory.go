@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"idebug/logger"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// directoryIndex 会话内的部门和用户名称索引,由列表接口填充,获取详情前优先查询
+type directoryIndex struct {
+	Platform    string            `json:"platform"`
+	Key         string            `json:"key"`         // 企业微信为corpid,飞书为app_id
+	Departments map[string]string `json:"departments"` // 部门ID => 部门名称,同一部门的多种ID均会保存
+	Users       map[string]string `json:"users"`       // 用户ID => 用户姓名,同一用户的多种ID均会保存
+	UpdatedAt   time.Time         `json:"updated_at"`
+	lock        sync.RWMutex
+	dirty       bool
+}
+
+var (
+	directories     = map[string]*directoryIndex{}
+	directoriesLock sync.Mutex
+	cachePersist    bool // 是否将索引保存至磁盘,开启后切换企业或应用时会加载对应的索引
+)
+
+// directoryFor 返回平台和企业或应用对应的索引,不存在时创建,开启持久化时从磁盘加载
+func directoryFor(platform, key string) *directoryIndex {
+	directoriesLock.Lock()
+	defer directoriesLock.Unlock()
+	id := platform + "_" + key
+	if d, ok := directories[id]; ok {
+		return d
+	}
+	d := &directoryIndex{Platform: platform, Key: key, Departments: map[string]string{}, Users: map[string]string{}}
+	if d.persistent() {
+		_ = d.load()
+	}
+	directories[id] = d
+	return d
+}
+
+// configDir 返回用户配置目录下idebug的子目录
+func configDir(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join(".idebug", name)
+	}
+	return filepath.Join(dir, "idebug", name)
+}
+
+// directoryDir 索引的保存目录
+func directoryDir() string {
+	return configDir("cache")
+}
+
+// persistent 未设置corpid或app_id时无法区分企业,索引只保存在内存中
+func (d *directoryIndex) persistent() bool {
+	return cachePersist && d.Key != ""
+}
+
+func (d *directoryIndex) file() string {
+	return filepath.Join(directoryDir(), fmt.Sprintf("%s_%s.json", d.Platform, d.Key))
+}
+
+func (d *directoryIndex) Department(id string) (string, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	name, ok := d.Departments[id]
+	return name, ok
+}
+
+func (d *directoryIndex) User(id string) (string, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	name, ok := d.Users[id]
+	return name, ok
+}
+
+// AddDepartment 保存部门名称,ids为同一部门的多种ID,空ID会忽略
+func (d *directoryIndex) AddDepartment(name string, ids ...string) {
+	d.add(d.Departments, name, ids)
+}
+
+// AddUser 保存用户姓名,ids为同一用户的多种ID,空ID会忽略
+func (d *directoryIndex) AddUser(name string, ids ...string) {
+	d.add(d.Users, name, ids)
+}
+
+func (d *directoryIndex) add(m map[string]string, name string, ids []string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for _, id := range ids {
+		if id == "" || m[id] == name {
+			continue
+		}
+		m[id] = name
+		d.dirty = true
+		d.UpdatedAt = time.Now()
+	}
+}
+
+// Count 返回部门和用户的ID数量
+func (d *directoryIndex) Count() (int, int) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return len(d.Departments), len(d.Users)
+}
+
+// LastUpdated 返回索引的最后更新时间,从未更新时为零值
+func (d *directoryIndex) LastUpdated() time.Time {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.UpdatedAt
+}
+
+// Clear 清空索引,开启持久化时同时删除磁盘文件
+func (d *directoryIndex) Clear() error {
+	d.lock.Lock()
+	d.Departments = map[string]string{}
+	d.Users = map[string]string{}
+	d.UpdatedAt = time.Time{}
+	d.dirty = false
+	d.lock.Unlock()
+	if !d.persistent() {
+		return nil
+	}
+	if err := os.Remove(d.file()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (d *directoryIndex) load() error {
+	data, err := os.ReadFile(d.file())
+	if err != nil {
+		return err
+	}
+	var saved directoryIndex
+	if err = json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	// 与内存中的索引合并,内存中的数据更新,优先保留
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for id, name := range saved.Departments {
+		if _, ok := d.Departments[id]; !ok {
+			d.Departments[id] = name
+		}
+	}
+	for id, name := range saved.Users {
+		if _, ok := d.Users[id]; !ok {
+			d.Users[id] = name
+		}
+	}
+	if saved.UpdatedAt.After(d.UpdatedAt) {
+		d.UpdatedAt = saved.UpdatedAt
+	}
+	return nil
+}
+
+func (d *directoryIndex) save() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if !d.dirty || !d.persistent() {
+		return nil
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(directoryDir(), 0700); err != nil {
+		return err
+	}
+	if err = os.WriteFile(d.file(), data, 0600); err != nil {
+		return err
+	}
+	d.dirty = false
+	return nil
+}
+
+// saveDirectories 开启持久化时保存有变化的索引,在每个命令执行后调用
+func saveDirectories() error {
+	if !cachePersist {
+		return nil
+	}
+	directoriesLock.Lock()
+	defer directoriesLock.Unlock()
+	for _, d := range directories {
+		if err := d.save(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newCache dir在执行时获取,企业或应用可能在会话中切换;refresh通过列表接口重新填充索引
+func newCache(dir func() *directoryIndex, refresh func(*directoryIndex) error) *cobra.Command {
+	cache := &cobra.Command{
+		Use:   `cache`,
+		Short: `部门和用户名称索引`,
+	}
+	ls := &cobra.Command{
+		Use:   `ls`,
+		Short: `查看索引`,
+		Run: func(cmd *cobra.Command, args []string) {
+			d := dir()
+			depts, users := d.Count()
+			updatedAt := ""
+			if t := d.LastUpdated(); !t.IsZero() {
+				updatedAt = t.Format("2006-01-02 15:04:05")
+			}
+			file := ""
+			if d.persistent() {
+				file = d.file()
+			}
+			fmt.Printf("%s\n", strings.Repeat("=", 20))
+			fmt.Println(fmt.Sprintf("%-17s: %s", "key", d.Key))
+			fmt.Println(fmt.Sprintf("%-17s: %s", "persist", cacheSetting()))
+			fmt.Println(fmt.Sprintf("%-17s: %s", "file", file))
+			fmt.Println(fmt.Sprintf("%-17s: %d", "departments", depts))
+			fmt.Println(fmt.Sprintf("%-17s: %d", "users", users))
+			fmt.Println(fmt.Sprintf("%-17s: %s", "updated_at", updatedAt))
+			fmt.Printf("%s\n", strings.Repeat("=", 20))
+		},
+	}
+	clear := &cobra.Command{
+		Use:   `clear`,
+		Short: `清空索引`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := dir().Clear(); err != nil {
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			logger.Success("索引已清空")
+		},
+	}
+	refreshCmd := &cobra.Command{
+		Use:   `refresh`,
+		Short: `清空索引并通过列表接口重新获取`,
+		Run: func(cmd *cobra.Command, args []string) {
+			d := dir()
+			if err := d.Clear(); err != nil {
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			if err := refresh(d); err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			if HttpCanceled {
+				return
+			}
+			depts, users := d.Count()
+			logger.Success(fmt.Sprintf("索引已刷新,共%d个部门ID,%d个用户ID", depts, users))
+		},
+	}
+	persist := &cobra.Command{
+		Use:   `persist`,
+		Short: `设置是否将索引保存至磁盘`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				logger.Info("persist => " + cacheSetting())
+				return
+			}
+			switch args[0] {
+			case "on":
+				cachePersist = true
+				// 合并磁盘中已有的索引,并在命令执行后保存
+				d := dir()
+				_ = d.load()
+				d.lock.Lock()
+				d.dirty = true
+				d.lock.Unlock()
+			case "off":
+				cachePersist = false
+			default:
+				logger.Error(errors.New("可选值: on、off"))
+				return
+			}
+			logger.Success("persist => " + cacheSetting())
+		},
+	}
+	cache.AddCommand(ls, clear, refreshCmd, persist)
+	return cache
+}
+
+// directorySetting 返回索引的描述
+func directorySetting(d *directoryIndex) string {
+	depts, users := d.Count()
+	return fmt.Sprintf("%d个部门ID,%d个用户ID,persist %s", depts, users, cacheSetting())
+}
+
+// cacheSetting 返回索引持久化的描述
+func cacheSetting() string {
+	if cachePersist {
+		return "on (" + directoryDir() + ")"
+	}
+	return "off"
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"idebug/logger"
+	"idebug/plugin"
+	"idebug/utils"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxSessionCalls 会话最多保留的请求记录数,请求记录包含响应体
+const maxSessionCalls = 2000
+
+var (
+	sessionCalls     []*plugin.Call // 本次会话的请求记录
+	sessionCallsLock sync.Mutex
+	emitFormat       string // set emit设置的会话默认格式,为空时不输出
+	emitUnmask       bool   // set emit --unmask设置的会话默认值
+)
+
+func init() {
+	plugin.AddHook(onCall)
+}
+
+// onCall 记录请求,并按--emit或者set emit设置输出可复现请求的命令或者代码
+func onCall(call *plugin.Call) {
+	sessionCallsLock.Lock()
+	sessionCalls = append(sessionCalls, call)
+	if len(sessionCalls) > maxSessionCalls {
+		sessionCalls = sessionCalls[len(sessionCalls)-maxSessionCalls:]
+	}
+	sessionCallsLock.Unlock()
+	format, show := emitFormat, emitUnmask
+	if emitOnce != "" {
+		format, show = emitOnce, unmask
+	}
+	if format == "" {
+		return
+	}
+	if !show {
+		call = plugin.MaskCall(call)
+	}
+	snippet, err := plugin.Snippet(format, call)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	fmt.Println(snippet)
+}
+
+// emitSetting 返回emit设置的描述
+func emitSetting() string {
+	if emitFormat == "" {
+		return "off"
+	}
+	if emitUnmask {
+		return emitFormat + "(不隐藏凭证)"
+	}
+	return emitFormat
+}
+
+func newEmit() *cobra.Command {
+	return &cobra.Command{
+		Use:   `emit`,
+		Short: `设置每个请求输出的命令或者代码格式`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 || args[0] == "off" {
+				emitFormat = ""
+				emitUnmask = false
+				logger.Success("emit => off")
+				return
+			}
+			if !utils.StringInList(args[0], append([]string{}, plugin.SnippetFormats...)) {
+				logger.Error(fmt.Errorf("不支持的格式: %s,可选值: %s、off", args[0], strings.Join(plugin.SnippetFormats, "、")))
+				return
+			}
+			emitFormat = args[0]
+			emitUnmask = unmask
+			logger.Success("emit => " + emitSetting())
+		},
+	}
+}
+
+func newCalls() *cobra.Command {
+	calls := &cobra.Command{
+		Use:   `calls`,
+		Short: `本次会话的请求记录`,
+	}
+	ls := &cobra.Command{
+		Use:   `ls`,
+		Short: `查看请求记录`,
+		Run: func(cmd *cobra.Command, args []string) {
+			records := getSessionCalls()
+			if len(records) == 0 {
+				logger.Info("无请求记录")
+				return
+			}
+			for i, call := range records {
+				if !unmask {
+					call = plugin.MaskCall(call)
+				}
+				status := fmt.Sprint(call.StatusCode)
+				if call.Err != nil {
+					status = call.Err.Error()
+				}
+				fmt.Printf("  -[%d] %s %s %s [%s] %dms\n", i+1, call.Time.Format("15:04:05"), call.Method, call.Url, status,
+					call.Duration.Milliseconds())
+			}
+		},
+	}
+	export := &cobra.Command{
+		Use:   `export`,
+		Short: `导出请求记录为Postman Collection或者HAR`,
+		Run: func(cmd *cobra.Command, args []string) {
+			records := getSessionCalls()
+			if len(records) == 0 {
+				logger.Info("无请求记录")
+				return
+			}
+			if !unmask {
+				for i, call := range records {
+					records[i] = plugin.MaskCall(call)
+				}
+			}
+			var data []byte
+			var err error
+			filename := "idebug_calls.postman_collection.json"
+			if harExport {
+				data, err = plugin.HAR(records)
+				filename = "idebug_calls.har"
+			} else {
+				data, err = plugin.PostmanCollection("idebug "+time.Now().Format("2006-01-02 15:04:05"), records)
+			}
+			if err != nil {
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			if utils.IsFileExists(filename) {
+				filename = generateNewFilename(filename)
+			}
+			if err = os.WriteFile(filename, data, 0644); err != nil {
+				logger.Error(errors.New("保存 JSON 文件失败: " + err.Error()))
+				return
+			}
+			logger.Success(fmt.Sprintf("共%d个请求,文件已保存至 %s", len(records), filename))
+		},
+	}
+	clear := &cobra.Command{
+		Use:   `clear`,
+		Short: `清空请求记录`,
+		Run: func(cmd *cobra.Command, args []string) {
+			sessionCallsLock.Lock()
+			sessionCalls = nil
+			sessionCallsLock.Unlock()
+			logger.Success("已清空请求记录")
+		},
+	}
+	export.Flags().BoolVar(&harExport, "har", false, "导出为HAR文件")
+	calls.AddCommand(ls, export, clear)
+	return calls
+}
+
+// getSessionCalls 返回请求记录的副本
+func getSessionCalls() []*plugin.Call {
+	sessionCallsLock.Lock()
+	defer sessionCallsLock.Unlock()
+	return append([]*plugin.Call{}, sessionCalls...)
+}
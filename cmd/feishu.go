@@ -1,45 +1,142 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
 	"idebug/logger"
+	"idebug/plugin"
 	fs "idebug/plugin/feishu"
 	"idebug/utils"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
 const feishuUsage = mainUsage + `feishu Module:
-    关于--dt和--ut说明,飞书部门和用户一般都有两种类型id,用户为user_id和open_id,部门为department_id和open_department_id,下面统一简化为了id和openid,互不影响。注意--dt或者--ut要和提供的<did>或者<uid>实际类型对应,如没有与之对应的<did>或者<uid>参数则表示设置的是返回的部门id或者用户id的类型,例如dp <did> --dt <type> --ut <type>表示:根据did查看部门详情,如果提供的did实际类型为id,则--dt的值应该为id,那么此时的--ut则表示返回的用户类型,按需赋值即可。如果某个id类型获取不到数据请更换类型。
+    关于--dt和--ut说明,飞书用户ID类型有user_id、open_id和union_id,部门ID类型有department_id和open_department_id,下面统一简化为了id、openid和unionid。--dt和--ut均为可选参数:提供的<did>或者<uid>会根据前缀自动识别类型(ou_为open_id,on_为union_id,od-为open_department_id,其余为id,0为根部门),识别出的类型请求失败时会自动尝试其它类型;没有对应输入参数的--dt或者--ut表示返回的部门ID或者用户ID的类型,未指定时使用set dt和set ut设置的会话默认值(默认openid)。
     set appid     <appid>                         设置appid
     set appsecret <appsecret>                     设置appsecret
+    set dt        <type>                          设置返回的部门ID类型会话默认值,可选值: id、openid
+    set ut        <type>                          设置返回的用户ID类型会话默认值,可选值: id、openid、unionid
+    set qps       [class] <n|reset>               设置接口类别(auth、contact、im、bitable、corehr、default)的QPS,不指定类别时设置全部,0:不限速,reset:恢复默认值
+    set retry     <n|reset> [deadline]            设置网络错误、5xx和频率限制的重试次数(默认3)和包含重试的总时长(默认1m),0:不重试或不限制
     run     --dt <type> --ut <type>               获取tenant_access_token
     dp      <did> --dt <type> --ut <type>         根据<did>查看部门详情
     dp ls   <did> --dt <type> --ut <type> [-r]    根据<did>查看子部门列表,-r:递归获取(默认false)
+    dp tree <did> --dt <type> --ut <type>         根据<did>递归获取部门树(不含用户)并导出HTML和XLSX,不提供<did>则获取授权范围内所有部门
     user    <uid> --dt <type> --ut <type>         根据<uid>查看用户详情
-    user ls <did> --dt <type> --ut <type>         根据<did>查看部门直属用户列表,暂不提供递归获取,可使用dump命令代替
+    user ls <did> --dt <type> --ut <type> [-r]    根据<did>查看部门直属用户列表,-r:递归获取所有子部门用户(默认false)
+    user find --mobile <m,...> --email <e,...> [-f <file>] --ut <type>
+                                                  根据手机号或邮箱批量查询用户ID并导出XLSX,-f:按行读取手机号或邮箱
+    id convert <uid...> --from <type> --to <type> [-f <file>]
+                                                  批量转换用户ID并导出user_id、open_id、union_id对照表,-f:按行读取用户ID
+    group         <gid>                           根据<gid>查看用户组详情
+    group ls                                      查看授权范围内的用户组列表
+    group members <gid> --ut <type> --dt <type>   根据<gid>查看用户组成员
+    chat         <cid> --ut <type>                根据<cid>查看群详情
+    chat ls      --ut <type>                      查看机器人所在的群列表
+    chat members <cid> --ut <type>                根据<cid>查看群成员列表
+    chat dump    <cid...> --ut <type>             导出群信息及群成员至XLSX,不提供<cid>则导出机器人所在的所有群
+    send --to <id> --id-type <type> --type <msg_type> [--text <text>] [-f <file>]
+                                                  发送消息,--id-type可选值: open_id、user_id、union_id、chat_id、email(默认open_id),
+                                                  --type可选值: text、post、image、interactive(默认text),-f:post和interactive为JSON文件
+                                                  (支持卡片搭建工具导出的卡片JSON),image为图片文件
+    send --reply <message_id> --type <msg_type> [--text <text>] [-f <file>]
+                                                  回复指定消息
+    send --update <message_id> --type <msg_type> [--text <text>] [-f <file>]
+                                                  更新已发送的消息,interactive为更新卡片,text和post为编辑消息
+    audit ls --from <time> --to <time> [--user <uid>] [--event <name>] --ut <type>
+                                                  查询审计日志并导出XLSX和JSON,时间格式为yyyy-mm-dd[ hh:mm:ss]或者秒级时间戳,默认最近7天
+    stats dept <did> --date <date>[,<date>] [--child] --dt <type>
+                                                  查询部门维度的活跃和功能使用数据并导出XLSX和JSON,不提供<did>则为根部门
+    stats user <did> --date <date>[,<date>] [--uid <uid>] --dt <type> --ut <type>
+                                                  查询用户维度的活跃和功能使用数据并导出XLSX和JSON,不提供<did>则为根部门
+    corehr employees --ut <type> --dt <type> [--fields <f,...>]
+                                                  查询人事员工并导出XLSX和JSON,--fields:返回的字段,默认返回常用字段
+    corehr persons <pid...> [-f <file>] --ut <type>
+                                                  根据个人信息ID批量查询人事个人信息并导出XLSX和JSON,-f:按行读取个人信息ID
+    corehr jobs  --ut <type> --dt <type>          查询人事任职信息并导出XLSX和JSON
+    corehr depts --ut <type> --dt <type>          查询人事部门并导出XLSX和JSON
+    corehr join  <did> --dt <type> [--fields <f,...>]
+                                                  根据<did>递归获取通讯录用户并按user_id关联人事员工信息,导出XLSX和JSON,不提供<did>则为根部门
+    call <METHOD> <path> [--query k=v] [--body @file.json]
+                                                  调用任意接口,自动注入tenant_access_token,<path>为/open-apis下的路径或者接口域名下的完整URL,
+                                                  --query可多次指定,--body为JSON字符串或者@文件,打印状态、响应头、耗时和格式化后的响应
+    api ls                                        查看接口目录,包含内置接口和自定义目录下YAML文件中的接口
+    api reload                                    重新加载接口目录
+    api <name> [k=v ...] [--pages <n>]            调用接口目录中的接口,自动翻页并导出XLSX和JSON,--pages:最多获取的页数(默认所有页)
+    cache ls                                      查看部门和用户名称索引,索引由部门和用户列表填充,显示名称时优先查询索引
+    cache clear                                   清空索引
+    cache refresh                                 清空索引并获取通讯录授权范围内的部门和用户重新填充
+    cache persist <on|off>                        设置是否将索引按app_id保存至磁盘(默认off)
     email update --uid <uid> --pass --ut <type>   根据<uid>更新[企业邮箱]密码
-    dump         <did> --dt <type> --ut <type>    根据<did>递归导出部门用户,如果能确定授权范围为所有部门请手动赋值为0
+    dump         <did> --dt <type> --ut <type> [--group] [--workers <n>]
+                                                  根据<did>递归导出部门用户,如果能确定授权范围为所有部门请手动赋值为0,--group:同时导出仅通过用户组授权的用户,
+                                                  不提供<did>时单独授权的用户会导出至"单独授权用户"节点,--workers:并发获取的部门数量(默认5)
+    dump --resume                                 从上次中断(Ctrl+C、网络错误或者凭证过期)的位置继续导出,已获取的部门不再重复获取,
+                                                  中断时已获取的数据会导出至feishu_dump_partial.html和feishu_dump_partial.xlsx
+    dump         <did> --to-bitable <app_token> [--table <name>]
+                                                  导出的同时按部门和用户同步至多维表格数据表(默认"通讯录"),数据表和字段不存在时自动创建,
+                                                  已有记录按"同步键"更新,并导出与上次同步相比的变化报告,已不在本次导出的记录不会被删除
 `
 
 type feiShuCli struct {
-	Root                *cobra.Command
-	info                *cobra.Command
-	set                 *cobra.Command
-	appId               *cobra.Command
-	appSecret           *cobra.Command
-	run                 *cobra.Command
-	dp                  *cobra.Command
-	dpLs                *cobra.Command
-	user                *cobra.Command
-	userLs              *cobra.Command
-	email               *cobra.Command
-	emailPasswordUpdate *cobra.Command
-	dump                *cobra.Command
+	Root                     *cobra.Command
+	info                     *cobra.Command
+	set                      *cobra.Command
+	appId                    *cobra.Command
+	appSecret                *cobra.Command
+	run                      *cobra.Command
+	dp                       *cobra.Command
+	dpLs                     *cobra.Command
+	dpTree                   *cobra.Command
+	user                     *cobra.Command
+	userLs                   *cobra.Command
+	userFind                 *cobra.Command
+	id                       *cobra.Command
+	idConvert                *cobra.Command
+	group                    *cobra.Command
+	groupLs                  *cobra.Command
+	groupMembers             *cobra.Command
+	chat                     *cobra.Command
+	chatLs                   *cobra.Command
+	chatMembers              *cobra.Command
+	chatDump                 *cobra.Command
+	send                     *cobra.Command
+	audit                    *cobra.Command
+	auditLs                  *cobra.Command
+	stats                    *cobra.Command
+	statsDept                *cobra.Command
+	statsUser                *cobra.Command
+	coreHR                   *cobra.Command
+	coreHREmployees          *cobra.Command
+	coreHRPersons            *cobra.Command
+	coreHRJobs               *cobra.Command
+	coreHRDepts              *cobra.Command
+	coreHRJoin               *cobra.Command
+	call                     *cobra.Command
+	api                      *cobra.Command
+	emit                     *cobra.Command
+	trace                    *cobra.Command
+	qps                      *cobra.Command
+	retry                    *cobra.Command
+	calls                    *cobra.Command
+	cache                    *cobra.Command
+	email                    *cobra.Command
+	emailPasswordUpdate      *cobra.Command
+	dump                     *cobra.Command
+	defaultDt                *cobra.Command
+	defaultUt                *cobra.Command
+	customAttrs              []*fs.CustomAttrEntry // 企业自定义用户字段,同一命令内只获取一次
+	customAttrsFetched       bool
+	employeeTypeNames        map[int]string // 租户的人员类型,同一命令内只获取一次
+	employeeTypeNamesFetched bool
 }
 
 func NewFeiShuCli() *feiShuCli {
@@ -52,46 +149,137 @@ func NewFeiShuCli() *feiShuCli {
 	cli.run = cli.newRun()
 	cli.dp = cli.newDp()
 	cli.dpLs = cli.newDpLs()
+	cli.dpTree = cli.newDpTree()
 	cli.user = cli.newUser()
 	cli.userLs = cli.newUserLs()
+	cli.userFind = cli.newUserFind()
+	cli.id = cli.newId()
+	cli.idConvert = cli.newIdConvert()
+	cli.group = cli.newGroup()
+	cli.groupLs = cli.newGroupLs()
+	cli.groupMembers = cli.newGroupMembers()
+	cli.chat = cli.newChat()
+	cli.chatLs = cli.newChatLs()
+	cli.chatMembers = cli.newChatMembers()
+	cli.chatDump = cli.newChatDump()
+	cli.send = cli.newSend()
+	cli.audit = cli.newAudit()
+	cli.auditLs = cli.newAuditLs()
+	cli.stats = cli.newStats()
+	cli.statsDept = cli.newStatsDept()
+	cli.statsUser = cli.newStatsUser()
+	cli.coreHR = cli.newCoreHR()
+	cli.coreHREmployees = cli.newCoreHREmployees()
+	cli.coreHRPersons = cli.newCoreHRPersons()
+	cli.coreHRJobs = cli.newCoreHRJobs()
+	cli.coreHRDepts = cli.newCoreHRDepts()
+	cli.coreHRJoin = cli.newCoreHRJoin()
+	cli.call = newCall(cli.callApi)
+	cli.api = newApi(FeiShuModule, cli.callApi)
+	cli.emit = newEmit()
+	cli.trace = newTrace()
+	cli.qps = newQps(func() *plugin.RateLimiter { return FeiShuClient.Limiter() })
+	cli.retry = newRetry(func() *plugin.RetryPolicy { return FeiShuClient.RetryPolicy() })
+	cli.calls = newCalls()
+	cli.cache = newCache(feiShuDirectory, cli.refreshDirectory)
 	cli.email = cli.newEmail()
 	cli.emailPasswordUpdate = cli.newEmailPasswordUpdate()
 	cli.dump = cli.newDump()
+	cli.defaultDt = cli.newDefaultDepartmentIdType()
+	cli.defaultUt = cli.newDefaultUserIdType()
 	cli.init()
 	return cli
 }
 
 func (cli *feiShuCli) init() {
-	cli.dp.PersistentFlags().StringVar(&departmentIdType, "dt", "", "用户ID类型,可选值: id、openid")
-	cli.dp.PersistentFlags().StringVar(&userIdType, "ut", "", "用户ID类型,可选值: id、openid")
+	cli.dp.PersistentFlags().StringVar(&departmentIdType, "dt", "", "部门ID类型,可选值: id、openid")
+	cli.dp.PersistentFlags().StringVar(&userIdType, "ut", "", "用户ID类型,可选值: id、openid、unionid")
 	cli.dpLs.Flags().BoolVarP(&recurse, "re", "r", false, "是否递归获取,默认false")
 
-	cli.user.PersistentFlags().StringVar(&departmentIdType, "dt", "", "用户IID类型,可选值: id、openid")
-	cli.user.PersistentFlags().StringVar(&userIdType, "ut", "", "用户ID类型,可选值: id、openid")
-	//TODO: cli.userLs.Flags().BoolVarP(&recurse, "re", "r", false, "是否递归获取,默认false")
+	cli.user.PersistentFlags().StringVar(&departmentIdType, "dt", "", "部门ID类型,可选值: id、openid")
+	cli.user.PersistentFlags().StringVar(&userIdType, "ut", "", "用户ID类型,可选值: id、openid、unionid")
+	cli.userLs.Flags().BoolVarP(&recurse, "re", "r", false, "是否递归获取,默认false")
+	cli.userFind.Flags().StringSliceVar(&mobiles, "mobile", nil, "手机号,多个用逗号分隔")
+	cli.userFind.Flags().StringSliceVar(&emails, "email", nil, "邮箱,多个用逗号分隔")
+	cli.userFind.Flags().StringVarP(&inputFile, "file", "f", "", "按行读取手机号或邮箱的文件")
 
-	cli.emailPasswordUpdate.Flags().StringVar(&userIdType, "ut", "", "用户ID类型,可选值: id、openid")
+	cli.idConvert.Flags().StringVar(&fromIdType, "from", "", "源用户ID类型,可选值: id、openid、unionid,未指定时自动识别")
+	cli.idConvert.Flags().StringVar(&toIdType, "to", "", "目标用户ID类型,可选值: id、openid、unionid")
+	cli.idConvert.Flags().StringVarP(&inputFile, "file", "f", "", "按行读取用户ID的文件")
+
+	cli.groupMembers.Flags().StringVar(&userIdType, "ut", "", "用户ID类型,可选值: id、openid、unionid")
+	cli.groupMembers.Flags().StringVar(&departmentIdType, "dt", "", "部门ID类型,可选值: id、openid")
+
+	cli.chat.PersistentFlags().StringVar(&userIdType, "ut", "", "用户ID类型,可选值: id、openid、unionid")
+
+	cli.send.Flags().StringVar(&receiveId, "to", "", "接收者ID")
+	cli.send.Flags().StringVar(&receiveIdType, "id-type", "open_id", "接收者ID类型,可选值: open_id、user_id、union_id、chat_id、email")
+	cli.send.Flags().StringVar(&msgType, "type", "text", "消息类型,可选值: text、post、image、interactive")
+	cli.send.Flags().StringVar(&msgText, "text", "", "文本消息内容")
+	cli.send.Flags().StringVarP(&msgFile, "file", "f", "", "消息内容JSON文件或者图片文件")
+	cli.send.Flags().StringVar(&replyMessageId, "reply", "", "回复的消息ID")
+	cli.send.Flags().StringVar(&updateMessageId, "update", "", "更新的消息ID")
+
+	cli.auditLs.Flags().StringVar(&fromTime, "from", "", "起始时间,格式为yyyy-mm-dd[ hh:mm:ss]或者秒级时间戳")
+	cli.auditLs.Flags().StringVar(&toTime, "to", "", "结束时间,格式为yyyy-mm-dd[ hh:mm:ss]或者秒级时间戳")
+	cli.auditLs.Flags().StringVar(&userId, "user", "", "操作人用户ID")
+	cli.auditLs.Flags().StringVar(&eventName, "event", "", "事件名称")
+	cli.auditLs.Flags().StringVar(&userIdType, "ut", "", "用户ID类型,可选值: id、openid、unionid")
+
+	cli.stats.PersistentFlags().StringVar(&statsDate, "date", "", "日期,格式为yyyy-mm-dd,范围用逗号分隔,默认昨天")
+	cli.stats.PersistentFlags().StringVar(&departmentIdType, "dt", "", "部门ID类型,可选值: id、openid")
+	cli.statsDept.Flags().BoolVar(&containsChild, "child", false, "是否包含子部门,默认false")
+	cli.statsUser.Flags().StringVar(&userId, "uid", "", "用户ID")
+	cli.statsUser.Flags().StringVar(&userIdType, "ut", "", "用户ID类型,可选值: id、openid、unionid")
+
+	cli.coreHR.PersistentFlags().StringVar(&userIdType, "ut", "", "用户ID类型,可选值: id、openid、unionid")
+	cli.coreHR.PersistentFlags().StringVar(&departmentIdType, "dt", "", "部门ID类型,可选值: id、openid")
+	cli.coreHREmployees.Flags().StringSliceVar(&corehrFields, "fields", nil, "返回的字段,多个用逗号分隔,默认返回常用字段")
+	cli.coreHRJoin.Flags().StringSliceVar(&corehrFields, "fields", nil, "返回的字段,多个用逗号分隔,默认返回常用字段")
+	cli.coreHRPersons.Flags().StringVarP(&inputFile, "file", "f", "", "按行读取个人信息ID的文件")
+
+	cli.call.Flags().StringArrayVar(&callQuery, "query", nil, "查询参数,格式为k=v,可多次指定")
+	cli.call.Flags().StringVar(&callBody, "body", "", "请求体JSON,@开头为文件,如@body.json")
+
+	cli.emailPasswordUpdate.Flags().StringVar(&userIdType, "ut", "", "用户ID类型,可选值: id、openid、unionid")
 	cli.emailPasswordUpdate.Flags().StringVar(&password, "pass", "", "企业邮箱新密码")
 	cli.emailPasswordUpdate.Flags().StringVar(&userId, "uid", "", "用户ID")
 	cli.emailPasswordUpdate.MarkFlagRequired("pass")
 	cli.emailPasswordUpdate.MarkFlagRequired("uid")
-	cli.emailPasswordUpdate.MarkFlagRequired("ut")
 
-	cli.run.Flags().StringVar(&userIdType, "ut", "", "用户ID类型,可选值: id、openid")
+	cli.run.Flags().StringVar(&userIdType, "ut", "", "用户ID类型,可选值: id、openid、unionid")
 	cli.run.Flags().StringVar(&departmentIdType, "dt", "", "部门ID类型,可选值: id、openid")
 
-	cli.dump.Flags().StringVar(&userIdType, "ut", "", "用户ID类型,可选值: id、openid")
+	cli.dump.Flags().StringVar(&userIdType, "ut", "", "用户ID类型,可选值: id、openid、unionid")
 	cli.dump.Flags().StringVar(&departmentIdType, "dt", "", "部门ID类型,可选值: id、openid")
-	cli.dump.MarkFlagRequired("uid")
-	cli.dump.MarkFlagRequired("ut")
+	cli.dump.Flags().BoolVar(&includeGroup, "group", false, "同时导出仅通过用户组授权的用户,默认false")
+	cli.dump.Flags().StringVar(&bitableAppToken, "to-bitable", "", "同步至多维表格的app_token")
+	cli.dump.Flags().StringVar(&bitableTable, "table", "通讯录", "同步的多维表格数据表名称,不存在时自动创建")
+	cli.dump.Flags().IntVar(&dumpWorkers, "workers", defaultWorkers, "并发获取的部门数量,请求仍受set qps限制")
+	cli.dump.Flags().BoolVar(&dumpResume, "resume", false, "从上次中断的位置继续导出,使用上次的部门ID和ID类型")
 
-	cli.set.AddCommand(cli.appId, cli.appSecret, newProxy())
-	cli.dp.AddCommand(cli.dpLs)
-	cli.user.AddCommand(cli.userLs)
+	cli.Root.PersistentFlags().StringVar(&emitOnce, "emit", "", "当前命令输出的请求格式,可选值: curl、httpie、go")
+	cli.Root.PersistentFlags().BoolVar(&unmask, "unmask", false, "不隐藏凭证")
+
+	cli.set.AddCommand(cli.appId, cli.appSecret, cli.defaultDt, cli.defaultUt, newProxy(), cli.emit, cli.trace, cli.qps, cli.retry)
+	cli.dp.AddCommand(cli.dpLs, cli.dpTree)
+	cli.user.AddCommand(cli.userLs, cli.userFind)
+	cli.id.AddCommand(cli.idConvert)
+	cli.group.AddCommand(cli.groupLs, cli.groupMembers)
+	cli.chat.AddCommand(cli.chatLs, cli.chatMembers, cli.chatDump)
+	cli.audit.AddCommand(cli.auditLs)
+	cli.stats.AddCommand(cli.statsDept, cli.statsUser)
+	cli.coreHR.AddCommand(cli.coreHREmployees, cli.coreHRPersons, cli.coreHRJobs, cli.coreHRDepts, cli.coreHRJoin)
 	cli.email.AddCommand(cli.emailPasswordUpdate)
-	cli.Root.AddCommand(cli.set, cli.run, cli.info, cli.dp, cli.user, cli.email, cli.dump)
+	cli.Root.AddCommand(cli.set, cli.run, cli.info, cli.dp, cli.user, cli.id, cli.group, cli.chat, cli.send, cli.audit, cli.stats, cli.coreHR, cli.call, cli.api, cli.calls, cli.cache, cli.email, cli.dump)
 
-	cli.setHelpV1(cli.Root, cli.info, cli.set, cli.appId, cli.appSecret, cli.run, cli.dp, cli.dpLs, cli.user, cli.userLs, cli.email, cli.emailPasswordUpdate, cli.dump)
+	cli.setHelpV1(cli.api)
+	cli.setHelpV1(cli.api.Commands()...)
+	cli.setHelpV1(cli.emit, cli.trace, cli.qps, cli.retry, cli.calls)
+	cli.setHelpV1(cli.calls.Commands()...)
+	cli.setHelpV1(cli.cache)
+	cli.setHelpV1(cli.cache.Commands()...)
+	cli.setHelpV1(cli.Root, cli.info, cli.set, cli.appId, cli.appSecret, cli.defaultDt, cli.defaultUt, cli.run, cli.dp, cli.dpLs, cli.dpTree, cli.user, cli.userLs, cli.userFind, cli.id, cli.idConvert, cli.group, cli.groupLs, cli.groupMembers, cli.chat, cli.chatLs, cli.chatMembers, cli.chatDump, cli.send, cli.audit, cli.auditLs, cli.stats, cli.statsDept, cli.statsUser, cli.coreHR, cli.coreHREmployees, cli.coreHRPersons, cli.coreHRJobs, cli.coreHRDepts, cli.coreHRJoin, cli.call, cli.email, cli.emailPasswordUpdate, cli.dump)
 }
 
 func (cli *feiShuCli) newRoot() *cobra.Command {
@@ -105,6 +293,9 @@ func (cli *feiShuCli) newRoot() *cobra.Command {
 			return nil
 		},
 		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			if err := saveDirectories(); err != nil {
+				logger.Error(logger.FormatError(err))
+			}
 			reset()
 		},
 	}
@@ -115,6 +306,12 @@ func (cli *feiShuCli) newInfo() *cobra.Command {
 		Use:   "info",
 		Short: `查看设置`,
 		Run: func(cmd *cobra.Command, args []string) {
+			if cli.hasTenantAccessToken() {
+				FeiShuClient.RefreshIdentity()
+			}
+			if HttpCanceled {
+				return
+			}
 			cli.showClientConfig()
 		},
 	}
@@ -158,6 +355,44 @@ func (cli *feiShuCli) newAppSecret() *cobra.Command {
 
 }
 
+func (cli *feiShuCli) newDefaultDepartmentIdType() *cobra.Command {
+	return &cobra.Command{
+		Use:   "dt",
+		Short: `设置返回的部门ID类型会话默认值`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) < 1 {
+				logger.Warning("请提供一个值")
+				return
+			}
+			if _, ok := departmentIdTypeMap[args[0]]; !ok {
+				logger.Warning("错误的部门ID类型,可选值：" + strings.Join(sortedKeys(departmentIdTypeMap), "、"))
+				return
+			}
+			departmentIdTypeCache = args[0]
+			logger.Success("dt => " + args[0])
+		},
+	}
+}
+
+func (cli *feiShuCli) newDefaultUserIdType() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ut",
+		Short: `设置返回的用户ID类型会话默认值`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) < 1 {
+				logger.Warning("请提供一个值")
+				return
+			}
+			if _, ok := userIdTypeMap[args[0]]; !ok {
+				logger.Warning("错误的用户ID类型,可选值：" + strings.Join(sortedKeys(userIdTypeMap), "、"))
+				return
+			}
+			userIdTypeCache = args[0]
+			logger.Success("ut => " + args[0])
+		},
+	}
+}
+
 func (cli *feiShuCli) newRun() *cobra.Command {
 	return &cobra.Command{
 		Use:   "run",
@@ -177,6 +412,7 @@ func (cli *feiShuCli) newRun() *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
+			cli.fillDefaultIdType()
 			logger.Info("获取信息中,请稍等...")
 			req := fs.NewGetAuthScopeReqBuilder(FeiShuClient).
 				UserIdType(userIdTypeMap[userIdType]).
@@ -196,6 +432,10 @@ func (cli *feiShuCli) newRun() *cobra.Command {
 			}
 			departmentIdTypeCache = departmentIdType
 			userIdTypeCache = userIdType
+			FeiShuClient.RefreshIdentity()
+			if HttpCanceled {
+				return
+			}
 			cli.showClientConfig()
 		},
 	}
@@ -218,10 +458,8 @@ func (cli *feiShuCli) newDp() *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			var userIdTypeValue = strings.ToUpper(userIdTypeMap[userIdTypeCache])
-			var deptIdTypeValue = strings.ToUpper(departmentIdTypeMap[departmentIdTypeCache])
-			var uidType = userIdTypeMap[userIdType]
-			var didType = departmentIdTypeMap[departmentIdType]
+			auto := cli.resolveDepartmentIdType(args[0])
+			cli.fillDefaultIdType()
 			var (
 				status             string
 				name               string
@@ -238,14 +476,7 @@ func (cli *feiShuCli) newDp() *cobra.Command {
 				primaryMemberCount int
 				hrbps              []string
 			)
-			var deptInfo fs.DepartmentEntry
-			var err error
-			req := fs.NewGetDepartmentReqBuilder(FeiShuClient).
-				DepartmentId(args[0]).
-				DepartmentIdType(didType).
-				UserIdType(uidType).
-				Build()
-			deptInfo, err = FeiShuClient.Department.Get(req)
+			deptInfo, err := cli.getDepartmentWithFallback(args[0], auto)
 			if err != nil {
 				if errors.Is(err, context.Canceled) {
 					return
@@ -256,6 +487,10 @@ func (cli *feiShuCli) newDp() *cobra.Command {
 			if HttpCanceled {
 				return
 			}
+			var userIdTypeValue = strings.ToUpper(userIdTypeMap[userIdType])
+			var deptIdTypeValue = strings.ToUpper(departmentIdTypeMap[departmentIdType])
+			var uidType = userIdTypeMap[userIdType]
+			var didType = departmentIdTypeMap[departmentIdType]
 			if deptInfo.Status.IsDeleted {
 				status = "已删除"
 			} else {
@@ -269,32 +504,18 @@ func (cli *feiShuCli) newDp() *cobra.Command {
 			leaderUserId = deptInfo.LeaderUserID
 			parentId = deptInfo.ParentDepartmentID
 
+			indexFeiShuDepartment(&deptInfo)
+
 			//获取上级部门信息
-			req = fs.NewGetDepartmentReqBuilder(FeiShuClient).
-				DepartmentId(deptInfo.ParentDepartmentID).
-				DepartmentIdType(departmentIdTypeMap["id"]).
-				UserIdType(uidType).
-				Build()
-			parentDeptInfo, err := FeiShuClient.Department.Get(req)
-			if err != nil {
-				if errors.Is(err, context.Canceled) {
-					return
-				}
-			} else {
-				parentName = parentDeptInfo.Name
+			parentName, err = feiShuDepartmentName(deptInfo.ParentDepartmentID, didType, uidType)
+			if errors.Is(err, context.Canceled) {
+				return
 			}
 			if HttpCanceled {
 				return
 			}
 			if leaderUserId != "" {
-				req := fs.NewGetUserReqBuilder(FeiShuClient).
-					UserId(leaderUserId).
-					UserIdType(uidType).
-					Build()
-				leaderUserInfo, err := FeiShuClient.User.Get(req)
-				if err == nil {
-					leaderUserName = leaderUserInfo.Name
-				}
+				leaderUserName, err = feiShuUserName(leaderUserId, uidType, didType)
 				if errors.Is(err, context.Canceled) {
 					return
 				}
@@ -303,11 +524,7 @@ func (cli *feiShuCli) newDp() *cobra.Command {
 				return
 			}
 			for _, leader := range deptInfo.Leaders {
-				req := fs.NewGetUserReqBuilder(FeiShuClient).
-					UserId(leader.LeaderID).
-					UserIdType(uidType).
-					Build()
-				leaderInfo, err := FeiShuClient.User.Get(req)
+				leaderName, err := feiShuUserName(leader.LeaderID, uidType, didType)
 				if err != nil {
 					if errors.Is(err, context.Canceled) {
 						return
@@ -315,7 +532,7 @@ func (cli *feiShuCli) newDp() *cobra.Command {
 					leaders = append(leaders, fmt.Sprintf("%s: %s", userIdTypeValue, leader.LeaderID))
 					continue
 				} else {
-					leaders = append(leaders, fmt.Sprintf("%s(%s: %s)", leaderInfo.Name, userIdTypeValue, leader.LeaderID))
+					leaders = append(leaders, fmt.Sprintf("%s(%s: %s)", leaderName, userIdTypeValue, leader.LeaderID))
 				}
 				if HttpCanceled {
 					return
@@ -375,6 +592,18 @@ func (cli *feiShuCli) newDpLs() *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
+			auto := cli.resolveDepartmentIdType(args[0])
+			cli.fillDefaultIdType()
+			if auto {
+				// 先确认自动识别的部门ID类型是否可用
+				if _, err := cli.getDepartmentWithFallback(args[0], auto); err != nil {
+					if errors.Is(err, context.Canceled) {
+						return
+					}
+					logger.Error(logger.FormatError(err))
+					return
+				}
+			}
 			var index = 0
 			var depts []*fs.DepartmentEntry
 			err := cli.recursePrintDept(depts, args[0], departmentIdTypeMap[departmentIdType], userIdTypeMap[userIdType], 0, &index)
@@ -389,6 +618,90 @@ func (cli *feiShuCli) newDpLs() *cobra.Command {
 	}
 }
 
+func (cli *feiShuCli) newDpTree() *cobra.Command {
+	return &cobra.Command{
+		Use:   "tree",
+		Short: `根据部门ID获取部门树`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if !cli.hasTenantAccessToken() {
+				return fmt.Errorf("请先执行run获取tenant_access_token")
+			}
+			if err := cli.checkIdType(); err != nil {
+				return err
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) > 0 {
+				auto := cli.resolveDepartmentIdType(args[0])
+				if auto {
+					if _, err := cli.getDepartmentWithFallback(args[0], auto); err != nil {
+						if errors.Is(err, context.Canceled) {
+							return
+						}
+						logger.Error(logger.FormatError(err))
+						return
+					}
+				}
+			}
+			cli.fillDefaultIdType()
+			var uidType = userIdTypeMap[userIdType]
+			var didType = departmentIdTypeMap[departmentIdType]
+			var deptIds []string
+			if len(args) == 0 {
+				for deptId := range FeiShuClient.GetAuthScopeFromCache().DepartmentScope {
+					deptIds = append(deptIds, deptId)
+				}
+				sort.Strings(deptIds)
+			} else {
+				deptIds = append(deptIds, args[0])
+			}
+			if len(deptIds) == 0 {
+				logger.Warning("无可用部门信息")
+				return
+			}
+			var tree []*FeiShuDepartmentNode
+			for _, deptId := range deptIds {
+				logger.Info(fmt.Sprintf("正在获取部门[%s]的部门树...", deptId))
+				node, err := cli.fetchDepartmentTree(deptId, didType, uidType)
+				if err != nil {
+					if errors.Is(err, context.Canceled) {
+						return
+					}
+					logger.Error(logger.FormatError(err))
+					logger.Info(fmt.Sprintf("部门[%s]的部门树获取失败,将会继续执行...", deptId))
+					continue
+				}
+				if HttpCanceled {
+					return
+				}
+				tree = append(tree, node)
+			}
+			if len(tree) == 0 {
+				logger.Warning("无可用部门信息")
+				return
+			}
+			cli.printDepartmentTree(tree, 0)
+			logger.Info("正在保存至HTML文件...")
+			msg, err := cli.saveDepartmentTreeToHTML(tree, "feishu_dept.html")
+			if err != nil {
+				logger.Error(logger.FormatError(err))
+				logger.Info("保存文件失败")
+			} else {
+				logger.Success(msg)
+			}
+			logger.Info("正在保存至XLSX文件...")
+			msg, err = cli.saveDepartmentTreeToExcel(tree, "feishu_dept.xlsx")
+			if err != nil {
+				logger.Error(logger.FormatError(err))
+				logger.Info("保存文件失败")
+			} else {
+				logger.Success(msg)
+			}
+		},
+	}
+}
+
 func (cli *feiShuCli) newUser() *cobra.Command {
 	return &cobra.Command{
 		Use:   "user",
@@ -409,10 +722,9 @@ func (cli *feiShuCli) newUser() *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			req := fs.NewGetUserReqBuilder(FeiShuClient).UserId(args[0]).
-				UserIdType(userIdTypeMap[userIdType]).
-				DepartmentIdType(departmentIdTypeMap[departmentIdType]).Build()
-			userInfo, err := FeiShuClient.User.Get(req)
+			auto := cli.resolveUserIdType(args[0])
+			cli.fillDefaultIdType()
+			userInfo, err := cli.getUserWithFallback(args[0], auto)
 			if err != nil {
 				if errors.Is(err, context.Canceled) {
 					return
@@ -447,693 +759,2970 @@ func (cli *feiShuCli) newUserLs() *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			req := fs.NewGetUsersByDepartmentIdReqBuilder(FeiShuClient).
-				DepartmentId(args[0]).
-				DepartmentIdType(departmentIdTypeMap[departmentIdType]).
-				UserIdType(userIdTypeMap[userIdType]).
-				PageSize(50).
-				Build()
-			userList, err := FeiShuClient.User.GetUsersByDepartmentId(req)
-			if err != nil {
-				if errors.Is(err, context.Canceled) {
+			auto := cli.resolveDepartmentIdType(args[0])
+			cli.fillDefaultIdType()
+			if auto {
+				if _, err := cli.getDepartmentWithFallback(args[0], auto); err != nil {
+					if errors.Is(err, context.Canceled) {
+						return
+					}
+					logger.Error(logger.FormatError(err))
 					return
 				}
-				logger.Error(logger.FormatError(err))
-				return
 			}
-			if HttpCanceled {
-				return
+			var uidType = userIdTypeMap[userIdType]
+			var didType = departmentIdTypeMap[departmentIdType]
+			var deptIds = []string{args[0]}
+			if recurse {
+				logger.Info(fmt.Sprintf("正在获取部门[%s]的所有子部门...", args[0]))
+				req := fs.NewGetDepartmentChildrenReqBuilder(FeiShuClient).
+					DepartmentId(args[0]).
+					DepartmentIdType(didType).
+					UserIdType(uidType).
+					Fetch(true).
+					PageSize(50).
+					Build()
+				deptChildren, err := FeiShuClient.Department.Children(req)
+				if err != nil {
+					if errors.Is(err, context.Canceled) {
+						return
+					}
+					logger.Error(logger.FormatError(err))
+					return
+				}
+				if HttpCanceled {
+					return
+				}
+				for _, child := range deptChildren {
+					indexFeiShuDepartment(child)
+					if didType == "department_id" {
+						deptIds = append(deptIds, child.DepartmentID)
+					} else {
+						deptIds = append(deptIds, child.OpenDepartmentID)
+					}
+				}
+			}
+			var userList []*fs.UserEntry
+			var userIdSet = map[string]bool{}
+			for _, deptId := range deptIds {
+				if recurse {
+					logger.Info(fmt.Sprintf("正在获取部门[%s]直属用户列表...", deptId))
+				}
+				req := fs.NewGetUsersByDepartmentIdReqBuilder(FeiShuClient).
+					DepartmentId(deptId).
+					DepartmentIdType(didType).
+					UserIdType(uidType).
+					PageSize(50).
+					Build()
+				users, err := FeiShuClient.User.GetUsersByDepartmentId(req)
+				if err != nil {
+					if errors.Is(err, context.Canceled) {
+						return
+					}
+					logger.Error(logger.FormatError(err))
+					return
+				}
+				if HttpCanceled {
+					return
+				}
+				// 一个用户可属于多个部门,按用户ID去重
+				for _, u := range users {
+					id := u.UserId
+					if id == "" {
+						id = u.OpenId
+					}
+					if userIdSet[id] {
+						continue
+					}
+					userIdSet[id] = true
+					userList = append(userList, u)
+				}
 			}
 			length := len(userList)
 			if length == 0 {
 				logger.Info("无可用数据")
 				return
 			}
+			indexFeiShuUsers(userList)
 			for _, userInfo := range userList {
 				//if (verbose > i && verbose >= 0) || (verbose < 0) {
 				//	cli.showUserInfo(*userInfo, true)
 				//}
 				cli.showUserInfo(*userInfo, true)
 			}
+			if HttpCanceled {
+				return
+			}
+			logger.Info("正在保存至XLSX文件...")
+			msg, err := cli.saveUserToExcel(userList, "feishu_users.xlsx")
+			if err != nil {
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			logger.Success(msg)
 		},
 	}
 }
 
-func (cli *feiShuCli) newEmail() *cobra.Command {
+func (cli *feiShuCli) newUserFind() *cobra.Command {
 	return &cobra.Command{
-		Use:   "email",
-		Short: `企业邮箱操作`,
+		Use:   "find",
+		Short: `根据手机号或邮箱批量查询用户ID`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			if !cli.hasTenantAccessToken() {
 				return fmt.Errorf("请先执行run获取tenant_access_token")
 			}
-			var userIdTypeList []string
-			for idType := range userIdTypeMap {
-				userIdTypeList = append(userIdTypeList, idType)
-			}
-			if !utils.StringInList(userIdType, userIdTypeList) {
-				return errors.New("--ut :未设置或者是错误的用户ID类型,可选值：" + strings.Join(userIdTypeList, "、"))
+			if err := cli.checkIdType(); err != nil {
+				return err
 			}
 			return nil
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if inputFile != "" {
+				lines, err := utils.ReadLines(inputFile)
+				if err != nil {
+					return err
+				}
+				for _, line := range lines {
+					if strings.Contains(line, "@") {
+						emails = append(emails, line)
+					} else {
+						mobiles = append(mobiles, line)
+					}
+				}
+			}
+			if len(mobiles) == 0 && len(emails) == 0 {
+				return fmt.Errorf("请通过--mobile、--email或者-f提供要查询的手机号或邮箱")
+			}
+			return nil
 		},
-	}
-}
-
-func (cli *feiShuCli) newEmailPasswordUpdate() *cobra.Command {
-	return &cobra.Command{
-		Use:   "update",
-		Short: `更新密码`,
 		Run: func(cmd *cobra.Command, args []string) {
-			req := fs.NewUserEmailPasswordChangeReqBuilder(FeiShuClient).
-				UserIdType(userIdTypeMap[userIdType]).
-				PostData(userId, password).
-				Build()
-			err := FeiShuClient.User.EmailPasswordUpdate(req)
-			if err != nil {
-				if errors.Is(err, context.Canceled) {
+			cli.fillDefaultIdType()
+			var uidType = userIdTypeMap[userIdType]
+			var entries []*fs.UserIdEntry
+			// 接口单次最多查询50个手机号和50个邮箱
+			const batchSize = 50
+			for start := 0; start < len(mobiles) || start < len(emails); start += batchSize {
+				var mobileBatch, emailBatch []string
+				if start < len(mobiles) {
+					mobileBatch = mobiles[start:]
+					if len(mobileBatch) > batchSize {
+						mobileBatch = mobileBatch[:batchSize]
+					}
+				}
+				if start < len(emails) {
+					emailBatch = emails[start:]
+					if len(emailBatch) > batchSize {
+						emailBatch = emailBatch[:batchSize]
+					}
+				}
+				req := fs.NewBatchGetUserIdReqBuilder(FeiShuClient).
+					UserIdType(uidType).
+					Mobiles(mobileBatch).
+					Emails(emailBatch).
+					IncludeResigned(true).
+					Build()
+				users, err := FeiShuClient.User.BatchGetId(req)
+				if err != nil {
+					if errors.Is(err, context.Canceled) {
+						return
+					}
+					logger.Error(logger.FormatError(err))
+					return
+				}
+				if HttpCanceled {
 					return
 				}
+				entries = append(entries, users...)
+			}
+			if len(entries) == 0 {
+				logger.Info("无可用数据")
+				return
+			}
+			var found int
+			for _, entry := range entries {
+				key := entry.Mobile
+				if key == "" {
+					key = entry.Email
+				}
+				if entry.UserId == "" {
+					fmt.Printf("%s: 未找到用户\n", key)
+					continue
+				}
+				found++
+				fmt.Printf("%s: %s[%s]\n", key, userIdTypeMap[userIdType], entry.UserId)
+			}
+			logger.Info(fmt.Sprintf("共查询%d个,找到%d个用户", len(entries), found))
+			logger.Info("正在保存至XLSX文件...")
+			msg, err := cli.saveUserIdToExcel(entries, uidType, "feishu_user_find.xlsx")
+			if err != nil {
 				logger.Error(logger.FormatError(err))
 				return
 			}
-			logger.Success("修改成功")
+			logger.Success(msg)
 		},
 	}
 }
 
-func (cli *feiShuCli) newDump() *cobra.Command {
+func (cli *feiShuCli) newId() *cobra.Command {
 	return &cobra.Command{
-		Use:   "dump",
-		Short: `根据部门ID导出用户,不提供部门ID则导出通讯录授权范围内所有部门用户`,
-		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		Use:   "id",
+		Short: `用户ID操作`,
+	}
+}
+
+func (cli *feiShuCli) newIdConvert() *cobra.Command {
+	return &cobra.Command{
+		Use:   "convert",
+		Short: `批量转换用户ID`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
 			if !cli.hasTenantAccessToken() {
 				return fmt.Errorf("请先执行run获取tenant_access_token")
 			}
-			if err := cli.checkIdType(); err != nil {
-				return err
+			if fromIdType != "" && userIdTypeMap[fromIdType] == "" {
+				return fmt.Errorf("--from 可选值: id、openid、unionid")
+			}
+			if toIdType != "" && userIdTypeMap[toIdType] == "" {
+				return fmt.Errorf("--to 可选值: id、openid、unionid")
+			}
+			if len(args) == 0 && inputFile == "" {
+				return fmt.Errorf("请提供至少一个用户ID或者通过-f提供文件")
 			}
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			var deptNodeList []*FeiShuDepartmentNode
-			var uidType = userIdTypeMap[userIdType]
-			var didType = departmentIdTypeMap[departmentIdType]
-			conf := FeiShuClient.GetAuthScopeFromCache()
-			//根据指定部门ID先获取子部门列表
-			var isErrorOcurred bool
-			var deptIds []string
-			if len(args) == 0 {
-				for deptId := range conf.DepartmentScope {
-					deptIds = append(deptIds, deptId)
+			if inputFile != "" {
+				lines, err := utils.ReadLines(inputFile)
+				if err != nil {
+					logger.Error(logger.FormatError(err))
+					return
 				}
-			} else {
-				deptIds = append(deptIds, args[0])
+				args = append(args, lines...)
 			}
-			for _, deptId := range deptIds {
-				deptNode := &FeiShuDepartmentNode{
-					UnitIds:         []*string{},
-					DepartmentHrbps: []*string{},
-					User:            []*fs.UserEntry{},
-					Children:        []*FeiShuDepartmentNode{},
-				}
-				var deptInfo fs.DepartmentEntry
-				var err error
-				if HttpCanceled {
-					return
+			if toIdType == "" {
+				toIdType = userIdTypeCache
+			}
+			var users []*fs.UserEntry
+			for _, uid := range args {
+				if fromIdType != "" {
+					userIdType = fromIdType
+				} else {
+					userIdType = detectUserIdType(uid)
 				}
-				for i := 0; i < retry; i++ {
-					if i == 0 {
-						logger.Info(fmt.Sprintf("正在获取部门[%s]的信息....", deptId))
-					}
-					//获取部门信息
-					req := fs.NewGetDepartmentReqBuilder(FeiShuClient).
-						DepartmentId(deptId).
-						DepartmentIdType(didType).
-						UserIdType(uidType).
-						Build()
-					deptInfo, err = FeiShuClient.Department.Get(req)
-					if err != nil {
-						if errors.Is(err, context.Canceled) {
-							return
-						}
-						if i == retry-1 {
-							logger.Error(logger.FormatError(err))
-							logger.Info(fmt.Sprintf("部门[%s]信息获取失败,终止获取", deptId))
-							return
-						}
-						logger.Error(logger.FormatError(err))
-						logger.Info(fmt.Sprintf("部门[%s]信息获取失败,正在重试...", deptId))
-						time.Sleep(FeiShuDefaultInterval)
-						continue
+				cli.fillDefaultIdType()
+				userInfo, err := cli.getUserWithFallback(uid, fromIdType == "")
+				if err != nil {
+					if errors.Is(err, context.Canceled) {
+						return
 					}
-					break
+					logger.Error(fmt.Errorf("%s: %w", uid, logger.FormatError(err)))
+					users = append(users, nil)
+					continue
 				}
 				if HttpCanceled {
 					return
 				}
-				deptNode.Name = deptInfo.Name
-				deptNode.ZhCnName = deptInfo.I18NName.ZhCn
-				deptNode.JaJpName = deptInfo.I18NName.JaJp
-				deptNode.EnUsName = deptInfo.I18NName.EnUs
-				deptNode.DepartmentID = deptInfo.DepartmentID
-				deptNode.OpenDepartmentID = deptInfo.OpenDepartmentID
-				deptNode.ParentDepartmentID = deptInfo.ParentDepartmentID
-				//deptNode.ParentDepartmentName = deptInfo
-				//deptNode.Status = deptInfo
-				deptNode.LeaderUserID = deptInfo.LeaderUserID
-				//deptNode.LeaderUserName = deptInfo
-				//deptNode.ChatID = deptInfo
-
-				if deptInfo.Status.IsDeleted {
-					deptNode.Status = "已删除"
-				} else {
-					deptNode.Status = "正常"
-				}
-				logger.Info(fmt.Sprintf("正在获取部门[%s]主管用户信息...", deptId))
-				//获取部门主管领导信息
-				if deptInfo.LeaderUserID != "" {
-					for i := 0; i < retry; i++ {
-						req := fs.NewGetUserReqBuilder(FeiShuClient).
-							UserId(deptInfo.LeaderUserID).
-							UserIdType(uidType).
-							DepartmentIdType(didType).
-							Build()
-						userInfo, err := FeiShuClient.User.Get(req)
-						if err != nil {
-							if errors.Is(err, context.Canceled) {
-								return
-							}
-							if i == retry-1 {
-								logger.Error(logger.FormatError(err))
-								logger.Info(fmt.Sprintf("部门[%s]主管用户信息获取失败,将会继续执行...", deptId))
-								break
-							}
-							logger.Error(logger.FormatError(err))
-							logger.Info(fmt.Sprintf("部门[%s]主管用户信息获取失败,正在重试...", deptId))
-							time.Sleep(FeiShuDefaultInterval)
-							continue
-						}
-						deptNode.LeaderUserName = userInfo.Name
-						break
-					}
-				}
-				if HttpCanceled {
+				users = append(users, userInfo)
+				fmt.Printf("%s => %s\n", uid, userIdOf(userInfo, toIdType))
+			}
+			if HttpCanceled {
+				return
+			}
+			logger.Info("正在保存至XLSX文件...")
+			msg, err := cli.saveIdMappingToExcel(args, users, "feishu_id_mapping.xlsx")
+			if err != nil {
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			logger.Success(msg)
+		},
+	}
+}
+
+func (cli *feiShuCli) newGroup() *cobra.Command {
+	return &cobra.Command{
+		Use:   "group",
+		Short: `用户组操作`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if !cli.hasTenantAccessToken() {
+				return fmt.Errorf("请先执行run获取tenant_access_token")
+			}
+			if err := cli.checkIdType(); err != nil {
+				return err
+			}
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("请提供一个参数作为用户组ID或者提供一个子命令")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			req := fs.NewGetGroupReqBuilder(FeiShuClient).GroupId(args[0]).Build()
+			groupInfo, err := FeiShuClient.Group.Get(req)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
 					return
 				}
-				deptNode.ChatID = deptInfo.ChatID
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			if HttpCanceled || groupInfo == nil {
+				return
+			}
+			cli.showGroupInfo(groupInfo, false)
+		},
+	}
+}
 
-				//获取部门用户
-				logger.Info(fmt.Sprintf("正在获取部门[%s]直属用户列表...", deptId))
-				for i := 0; i < retry; i++ {
-					req1 := fs.NewGetUsersByDepartmentIdReqBuilder(FeiShuClient).
-						DepartmentId(deptId).
-						DepartmentIdType(didType).UserIdType(uidType).Build()
-					users, err := FeiShuClient.User.GetUsersByDepartmentId(req1)
-					if err != nil {
-						if errors.Is(err, context.Canceled) {
-							return
-						}
-						if i == retry-1 {
-							logger.Error(logger.FormatError(err))
-							logger.Info(fmt.Sprintf("部门[%s]直属用户列表获取失败,终止获取", deptId))
-							return
-						}
-						logger.Error(logger.FormatError(err))
-						logger.Info(fmt.Sprintf("部门[%s]直属用户列表获取失败,正在重试...", deptId))
-						time.Sleep(FeiShuDefaultInterval)
-						continue
-					}
-					deptNodeList = append(deptNodeList, deptNode)
-					deptNode.User = append(deptNode.User, users...)
-					err = cli.fetchDepartment(deptNode, deptId, departmentIdTypeMap[departmentIdType], userIdTypeMap[userIdType])
-					if err != nil {
-						if errors.Is(err, context.Canceled) {
-							return
-						}
-						isErrorOcurred = true
-						logger.Error(logger.FormatError(err))
+func (cli *feiShuCli) newGroupLs() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Short: `查看授权范围内的用户组列表`,
+		Run: func(cmd *cobra.Command, args []string) {
+			var groups []*fs.GroupEntry
+			// 1:普通用户组,2:动态用户组
+			for _, t := range []int{1, 2} {
+				req := fs.NewListGroupReqBuilder(FeiShuClient).Type(t).PageSize(100).Build()
+				items, err := FeiShuClient.Group.List(req)
+				if err != nil {
+					if errors.Is(err, context.Canceled) {
+						return
 					}
-					break
-				}
-				if isErrorOcurred {
-					break
+					logger.Error(logger.FormatError(err))
+					return
 				}
 				if HttpCanceled {
 					return
 				}
+				groups = append(groups, items...)
 			}
-			if HttpCanceled {
+			if len(groups) == 0 {
+				logger.Info("无可用数据")
 				return
 			}
-			if isErrorOcurred {
-				logger.Warning("终止获取,会保存已获取数据")
+			for _, groupInfo := range groups {
+				cli.showGroupInfo(groupInfo, true)
 			}
-			logger.Info("正在保存至html文件...")
-			msg, err := cli.saveDepartmentTreeWithUsersToHTML(deptNodeList, "feishu_dump.html")
+		},
+	}
+}
+
+func (cli *feiShuCli) newGroupMembers() *cobra.Command {
+	return &cobra.Command{
+		Use:   "members",
+		Short: `根据用户组ID查看用户组成员`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("请提供一个参数作为用户组ID")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.fillDefaultIdType()
+			var uidType = userIdTypeMap[userIdType]
+			var didType = departmentIdTypeMap[departmentIdType]
+			users, err := cli.getGroupUsers(args[0], uidType, didType)
 			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
 				logger.Error(logger.FormatError(err))
-				logger.Info("保存文件失败")
-			} else {
-				logger.Success(msg)
+				return
 			}
-			logger.Info("正在保存至xlsx文件...")
-			msg, err = cli.saveDepartmentWithUserToExcel(deptNodeList, "feishu_dump.xlsx")
+			if HttpCanceled {
+				return
+			}
+			// 用户组成员中的部门
+			req := fs.NewGetGroupMembersReqBuilder(FeiShuClient).
+				GroupId(args[0]).
+				MemberType("department").
+				MemberIdType("department_id").
+				PageSize(100).
+				Build()
+			depts, err := FeiShuClient.Group.Members(req)
 			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
 				logger.Error(logger.FormatError(err))
-				logger.Info("保存文件失败")
-			} else {
-				logger.Success(msg)
+			}
+			if HttpCanceled {
+				return
+			}
+			if len(users) == 0 && len(depts) == 0 {
+				logger.Info("无可用数据")
+				return
+			}
+			for _, dept := range depts {
+				fmt.Printf("  -部门ID[%s]\n", dept.MemberId)
+			}
+			for _, userInfo := range users {
+				cli.showUserInfo(*userInfo, true)
 			}
 		},
 	}
 }
 
-func (cli *feiShuCli) checkIdType() error {
-	var deptIdTypeList []string
-	var userIdTypeList []string
-	for idType := range departmentIdTypeMap {
-		deptIdTypeList = append(deptIdTypeList, idType)
-	}
-	for idType := range userIdTypeMap {
-		userIdTypeList = append(userIdTypeList, idType)
-	}
-	if !utils.StringInList(departmentIdType, deptIdTypeList) {
-		return errors.New("--dt :未设置或者是错误的部门ID类型,可选值：" + strings.Join(deptIdTypeList, "、"))
-	}
-	if !utils.StringInList(userIdType, userIdTypeList) {
-		return errors.New("--ut :未设置或者是错误的用户ID类型,可选值：" + strings.Join(userIdTypeList, "、"))
+func (cli *feiShuCli) newChat() *cobra.Command {
+	return &cobra.Command{
+		Use:   "chat",
+		Short: `群组操作`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if !cli.hasTenantAccessToken() {
+				return fmt.Errorf("请先执行run获取tenant_access_token")
+			}
+			if err := cli.checkIdType(); err != nil {
+				return err
+			}
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("请提供一个参数作为群ID或者提供一个子命令")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.fillDefaultIdType()
+			req := fs.NewGetChatReqBuilder(FeiShuClient).
+				ChatId(args[0]).
+				UserIdType(userIdTypeMap[userIdType]).
+				Build()
+			chatInfo, err := FeiShuClient.Chat.Get(req)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			if HttpCanceled || chatInfo == nil {
+				return
+			}
+			cli.showChatInfo(chatInfo)
+		},
 	}
-	return nil
 }
 
-func (cli *feiShuCli) hasTenantAccessToken() bool {
-	return FeiShuClient.GetTenantAccessTokenFromCache() != ""
-}
-
-func (cli *feiShuCli) showUserInfo(userInfo fs.UserEntry, inLine bool) {
-	var (
-		name            string
-		uid             string
-		oid             string
-		gender          string
-		employeeNo      string
-		status          []string
-		phone           string
-		email           string
-		enterpriseEmail string
-		isTenantManager string
-		workLocation    string
-		depts           []string
-	)
-	name = userInfo.Name
-	uid = userInfo.UserId
-	oid = userInfo.OpenId
-	if userInfo.Gender == 0 {
-		gender = "保密"
-	} else if userInfo.Gender == 1 {
-		gender = "男"
-	} else if userInfo.Gender == 2 {
-		gender = "女"
-	}
-	employeeNo = userInfo.EmployeeNo
-	email = userInfo.Email
-	enterpriseEmail = userInfo.EnterpriseEmail
-	if userInfo.Status.IsUnjoin {
-		status = append(status, "未加入")
-	}
-	if userInfo.Status.IsActivated {
-		status = append(status, "已激活")
-	}
-	if userInfo.Status.IsResigned {
-		status = append(status, "已离职")
-	}
-	if userInfo.Status.IsFrozen {
-		status = append(status, "已冻结")
-	}
-	if userInfo.Status.IsExited {
-		status = append(status, "已退出")
-	}
-	phone = userInfo.Mobile
-	if userInfo.IsTenantManager {
-		isTenantManager = "是"
-	} else if userInfo.IsTenantManager {
-		isTenantManager = "否"
-	}
-	for _, deptId := range userInfo.DepartmentIds {
-		req := fs.NewGetDepartmentReqBuilder(FeiShuClient).DepartmentId(deptId).DepartmentIdType(departmentIdTypeMap[departmentIdType]).Build()
-		deptInfo, err := FeiShuClient.Department.Get(req)
-		if err != nil {
-			if errors.Is(err, context.Canceled) {
+func (cli *feiShuCli) newChatLs() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Short: `查看机器人所在的群列表`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.fillDefaultIdType()
+			req := fs.NewListChatReqBuilder(FeiShuClient).
+				UserIdType(userIdTypeMap[userIdType]).
+				PageSize(100).
+				Build()
+			chats, err := FeiShuClient.Chat.List(req)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				logger.Error(logger.FormatError(err))
 				return
 			}
-			time.Sleep(FeiShuDefaultInterval)
-			continue
-		}
-		var info string
-		if deptInfo.Name != "" {
-			info = fmt.Sprintf("%s(%s: %s)", deptInfo.Name,
-				strings.ToUpper(departmentIdTypeMap["id"]), deptInfo.DepartmentID)
-		} else if deptInfo.Name == "" {
-			info = fmt.Sprintf("%s: %s",
-				strings.ToUpper(departmentIdTypeMap["id"]), deptInfo.DepartmentID)
-		}
-		depts = append(depts, info)
-		time.Sleep(FeiShuDefaultInterval)
-	}
-	if userInfo.Country != "" {
-		workLocation += userInfo.Country + " "
-	}
-	if userInfo.City != "" {
-		workLocation += userInfo.City
-	}
-	if inLine {
-		s := fmt.Sprintf("  -ID[%s] OPEN_ID[%s] 姓名[%s] 性别[%s] 工号[%s] 手机号码[%s] 邮箱[%s] 企业邮箱[%s] 状态[%s] 是否企业管理员[%s] 所属部门[%s] 工作地点[%s]", uid, oid, name, gender, employeeNo, phone, email, enterpriseEmail, strings.Join(status, "、"), isTenantManager, strings.Join(depts, "、"), workLocation)
-		fmt.Println(s)
-		return
+			if HttpCanceled {
+				return
+			}
+			if len(chats) == 0 {
+				logger.Info("无可用数据")
+				return
+			}
+			for _, c := range chats {
+				fmt.Printf("  -ID[%s] 名称[%s] 群主[%s] 外部群[%s] 状态[%s] 描述[%s]\n", c.ChatId, c.Name, c.OwnerId,
+					boolToChinese(c.External), c.ChatStatus, c.Description)
+			}
+			logger.Info(fmt.Sprintf("共%d个群", len(chats)))
+		},
 	}
-	fmt.Printf("%s\n", strings.Repeat("=", 20))
-	fmt.Printf("%-14s: %s\n", "USER_ID", uid)
-	fmt.Printf("%-14s: %s\n", "OPEN_ID", oid)
-	fmt.Printf("%-12s: %s\n", "姓名", name)
-	fmt.Printf("%-12s: %s\n", "性别", gender)
-	fmt.Printf("%-12s: %s\n", "工号", employeeNo)
-	fmt.Printf("%-10s: %s\n", "手机号码", phone)
-	fmt.Printf("%-12s: %s\n", "邮箱", email)
-	fmt.Printf("%-10s: %s\n", "企业邮箱", enterpriseEmail)
-	fmt.Printf("%-12s: %s\n", "状态", strings.Join(status, "、"))
-	fmt.Printf("%-9s: %s\n", "是否管理员", isTenantManager)
-	fmt.Printf("%-10s: %s\n", "所属部门", strings.Join(depts, "、"))
-	fmt.Printf("%-10s: %s\n", "工作地点", workLocation)
-	fmt.Printf("%s\n", strings.Repeat("=", 20))
-}
-
-func (cli *feiShuCli) showClientConfig() {
-	fsClientConfig := FeiShuClient.GetAuthScopeFromCache()
-	fmt.Printf("%s\n", strings.Repeat("=", 40))
-	if Proxy == nil {
-		fmt.Println(fmt.Sprintf("%-17s: %s", "proxy", ""))
-	} else {
-		fmt.Println(fmt.Sprintf("%-17s: %s", "proxy", *Proxy))
-	}
-	if fsClientConfig.AppId == nil {
-		fmt.Println(fmt.Sprintf("%-17s: %s", "app_id", ""))
-	} else {
-		fmt.Println(fmt.Sprintf("%-17s: %s", "app_id", *fsClientConfig.AppId))
-	}
-	if fsClientConfig.AppSecret == nil {
-		fmt.Println(fmt.Sprintf("%-17s: %s", "app_secret", ""))
-	} else {
-		fmt.Println(fmt.Sprintf("%-17s: %s", "app_secret", *fsClientConfig.AppSecret))
-	}
-	if fsClientConfig.TenantAccessToken == nil {
-		fmt.Println(fmt.Sprintf("%-17s: %s", "tenant_access_token", ""))
-	} else {
-		fmt.Println(fmt.Sprintf("%-17s: %s", "tenant_access_token", *fsClientConfig.TenantAccessToken))
-	}
-	var deptScope []string
-	for deptId, deptName := range fsClientConfig.DepartmentScope {
-		var deptIdType = "ID"
-		if departmentIdTypeCache == "open_department_id" {
-			deptIdType = "OPEN_ID"
-		}
-		if deptName != "" {
-			deptScope = append(deptScope, fmt.Sprintf("%s(%s:%s)", deptName, deptIdType, deptId))
-		} else {
-			deptScope = append(deptScope, fmt.Sprintf("%s:%s", deptIdType, deptId))
-		}
-
-	}
-	fmt.Println("-----------获取通讯录授权范围-----------")
-	logger.Notice("该接口用于获取应用被授权可访问的通讯录范围，包括可访问的部门列表、用户列表和用户组列表。授权范围为全员时，" +
-		"返回的部门列表为该企业所有的一级部门；否则返回的部门为管理员在设置授权范围时勾选的部门（不包含勾选部门的子部门）")
-	fmt.Println(fmt.Sprintf("%-13s: %s", "部门范围", strings.Join(deptScope, "、")))
-	var groupScope []string
-	for groupId, groupName := range fsClientConfig.GroupScope {
-		if groupName != "" {
-			groupScope = append(groupScope, fmt.Sprintf("%s(ID:%s)", groupName, groupId))
-		} else {
-			groupScope = append(groupScope, fmt.Sprintf("ID:%s", groupId))
-		}
-	}
-	fmt.Println(fmt.Sprintf("%-12s: %s", "用户组范围", strings.Join(groupScope, "、")))
-	var userScope []string
-	for id, userName := range fsClientConfig.UserScope {
-		var userIdType = "ID"
-		if userIdTypeCache == "open_id" {
-			userIdType = "OPEN_ID"
-		}
-		if userName != "" {
-			userScope = append(userScope, fmt.Sprintf("%s(%s:%s)", userName, userIdType, id))
-		} else {
-			userScope = append(userScope, fmt.Sprintf("%s:%s", userIdType, id))
-		}
-
-	}
-	fmt.Println(fmt.Sprintf("%-13s: %s", "用户范围", strings.Join(userScope, "、")))
-	fmt.Printf("%s\n", strings.Repeat("=", 40))
 }
 
-func (cli *feiShuCli) fetchDepartment(node *FeiShuDepartmentNode, deptId, deptIdType, userIdType string) error {
-	if HttpCanceled {
-		return nil
-	}
-	var deptChildren []*fs.DepartmentEntry
-	var err error
-	logger.Info(fmt.Sprintf("正在获取部门[%s]的子部门...", deptId))
-	for i := 0; i < retry; i++ {
-		req := fs.NewGetDepartmentChildrenReqBuilder(FeiShuClient).
-			DepartmentId(deptId).
-			DepartmentIdType(deptIdType).
-			UserIdType(userIdType).
-			Fetch(false).
-			PageSize(50).
-			Build()
-		deptChildren, err = FeiShuClient.Department.Children(req)
-		if err != nil {
-			if errors.Is(err, context.Canceled) {
-				return nil
+func (cli *feiShuCli) newChatMembers() *cobra.Command {
+	return &cobra.Command{
+		Use:   "members",
+		Short: `根据群ID查看群成员列表`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("请提供一个参数作为群ID")
 			}
-			if i == retry-1 {
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.fillDefaultIdType()
+			req := fs.NewGetChatMembersReqBuilder(FeiShuClient).
+				ChatId(args[0]).
+				MemberIdType(userIdTypeMap[userIdType]).
+				PageSize(100).
+				Build()
+			members, err := FeiShuClient.Chat.Members(req)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
 				logger.Error(logger.FormatError(err))
-				logger.Info(fmt.Sprintf("部门[%s]的子部门获取失败,终止获取", deptId))
-				return errors.New("")
+				return
 			}
-			logger.Error(logger.FormatError(err))
-			logger.Info(fmt.Sprintf("部门[%s]的子部门获取失败,正在重试...", deptId))
-			time.Sleep(FeiShuDefaultInterval)
-			continue
-		}
-		break
-	}
-	if HttpCanceled {
-		return nil
+			if HttpCanceled {
+				return
+			}
+			if len(members) == 0 {
+				logger.Info("无可用数据")
+				return
+			}
+			for _, member := range members {
+				fmt.Printf("  -%s[%s] 姓名[%s] 租户[%s]\n", strings.ToUpper(member.MemberIdType), member.MemberId, member.Name, member.TenantKey)
+			}
+			logger.Info(fmt.Sprintf("共%d个成员", len(members)))
+		},
 	}
-	for _, child := range deptChildren {
-		var id string
-		if deptIdType == "department_id" {
-			id = child.DepartmentID
-		} else {
-			id = child.OpenDepartmentID
-		}
-		req := fs.NewGetDepartmentReqBuilder(FeiShuClient).
-			DepartmentId(id).
-			DepartmentIdType(deptIdType).
-			UserIdType(userIdType).
-			Build()
-		deptInfo, err := FeiShuClient.Department.Get(req)
-		if err != nil {
-			logger.Error(logger.FormatError(err))
-			return errors.New("")
-		}
-		if HttpCanceled {
-			return nil
-		}
-		deptNode := &FeiShuDepartmentNode{
-			Name:                 deptInfo.Name,
-			ZhCnName:             deptInfo.I18NName.ZhCn,
-			JaJpName:             deptInfo.I18NName.JaJp,
-			EnUsName:             deptInfo.I18NName.EnUs,
-			DepartmentID:         deptInfo.DepartmentID,
-			OpenDepartmentID:     deptInfo.OpenDepartmentID,
-			ParentDepartmentID:   deptInfo.ParentDepartmentID,
-			ParentDepartmentName: node.Name,
-			//Status:               "",
-			LeaderUserID: deptInfo.LeaderUserID,
-			//LeaderUserName:       "",
-			ChatID:          deptInfo.ChatID,
-			UnitIds:         []*string{},
-			DepartmentHrbps: []*string{},
-			User:            []*fs.UserEntry{},
-			Children:        []*FeiShuDepartmentNode{},
-		}
-		if deptInfo.Status.IsDeleted {
-			deptNode.Status = "已删除"
-		} else {
-			deptNode.Status = "正常"
-		}
+}
 
-		//获取部门主管领导信息
-		logger.Info(fmt.Sprintf("正在获取部门[%s]主管用户信息...", id))
-		if deptInfo.LeaderUserID != "" {
-			for i := 0; i < retry; i++ {
-				req := fs.NewGetUserReqBuilder(FeiShuClient).
-					UserId(deptInfo.LeaderUserID).
-					UserIdType(userIdType).
-					DepartmentIdType(deptIdType).
+func (cli *feiShuCli) newChatDump() *cobra.Command {
+	return &cobra.Command{
+		Use:   "dump",
+		Short: `导出群信息及群成员,不提供群ID则导出机器人所在的所有群`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.fillDefaultIdType()
+			var uidType = userIdTypeMap[userIdType]
+			var chatIds = args
+			if len(chatIds) == 0 {
+				logger.Info("正在获取机器人所在的群列表...")
+				req := fs.NewListChatReqBuilder(FeiShuClient).
+					UserIdType(uidType).
+					PageSize(100).
 					Build()
-				userInfo, err := FeiShuClient.User.Get(req)
+				chats, err := FeiShuClient.Chat.List(req)
 				if err != nil {
 					if errors.Is(err, context.Canceled) {
-						return nil
+						return
 					}
-					if i == retry-1 {
-						logger.Error(logger.FormatError(err))
-						logger.Info(fmt.Sprintf("部门[%s]主管用户信息获取失败,将会继续执行...", id))
-						break
+					logger.Error(logger.FormatError(err))
+					return
+				}
+				for _, c := range chats {
+					chatIds = append(chatIds, c.ChatId)
+				}
+			}
+			if HttpCanceled {
+				return
+			}
+			if len(chatIds) == 0 {
+				logger.Info("无可用数据")
+				return
+			}
+			var chatList []*fs.ChatInfo
+			var memberList [][]*fs.ChatMemberEntry
+			for _, chatId := range chatIds {
+				logger.Info(fmt.Sprintf("正在获取群[%s]的信息及成员...", chatId))
+				req := fs.NewGetChatReqBuilder(FeiShuClient).
+					ChatId(chatId).
+					UserIdType(uidType).
+					Build()
+				chatInfo, err := FeiShuClient.Chat.Get(req)
+				if err != nil {
+					if errors.Is(err, context.Canceled) {
+						return
 					}
 					logger.Error(logger.FormatError(err))
-					logger.Info(fmt.Sprintf("部门[%s]主管用户信息获取失败,正在重试...", id))
-					time.Sleep(FeiShuDefaultInterval)
 					continue
 				}
-				deptNode.LeaderUserName = userInfo.Name
-				break
+				if HttpCanceled {
+					return
+				}
+				req1 := fs.NewGetChatMembersReqBuilder(FeiShuClient).
+					ChatId(chatId).
+					MemberIdType(uidType).
+					PageSize(100).
+					Build()
+				members, err := FeiShuClient.Chat.Members(req1)
+				if err != nil {
+					if errors.Is(err, context.Canceled) {
+						return
+					}
+					logger.Error(logger.FormatError(err))
+				}
+				if HttpCanceled {
+					return
+				}
+				chatList = append(chatList, chatInfo)
+				memberList = append(memberList, members)
+			}
+			if len(chatList) == 0 {
+				logger.Info("无可用数据")
+				return
+			}
+			logger.Info("正在保存至XLSX文件...")
+			msg, err := cli.saveChatToExcel(chatList, memberList, "feishu_chats.xlsx")
+			if err != nil {
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			logger.Success(msg)
+		},
+	}
+}
+
+func (cli *feiShuCli) newSend() *cobra.Command {
+	return &cobra.Command{
+		Use:   "send",
+		Short: `发送、回复或者更新消息`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if !cli.hasTenantAccessToken() {
+				return fmt.Errorf("请先执行run获取tenant_access_token")
+			}
+			var count int
+			for _, v := range []string{receiveId, replyMessageId, updateMessageId} {
+				if v != "" {
+					count++
+				}
+			}
+			if count != 1 {
+				return fmt.Errorf("--to、--reply和--update需且仅需提供一个")
+			}
+			if !utils.StringInList(receiveIdType, []string{"open_id", "user_id", "union_id", "chat_id", "email"}) {
+				return fmt.Errorf("--id-type 可选值: open_id、user_id、union_id、chat_id、email")
+			}
+			if !utils.StringInList(msgType, []string{"text", "post", "image", "interactive"}) {
+				return fmt.Errorf("--type 可选值: text、post、image、interactive")
+			}
+			if msgType == "text" && msgText == "" && msgFile == "" {
+				return fmt.Errorf("text消息请通过--text或者-f提供消息内容")
+			}
+			if msgType != "text" && msgFile == "" {
+				return fmt.Errorf("%s消息请通过-f提供文件", msgType)
 			}
-		}
-		if HttpCanceled {
 			return nil
-		}
-		//获取部门用户
-		logger.Info(fmt.Sprintf("正在获取部门[%s]直属用户列表...", id))
-		for i := 0; i < retry; i++ {
-			req1 := fs.NewGetUsersByDepartmentIdReqBuilder(FeiShuClient).
-				DepartmentId(id).
-				DepartmentIdType(deptIdType).UserIdType(userIdType).Build()
-			users, err := FeiShuClient.User.GetUsersByDepartmentId(req1)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			content, err := cli.buildMessageContent()
 			if err != nil {
 				if errors.Is(err, context.Canceled) {
-					return nil
-				}
-				if i == retry-1 {
-					logger.Error(logger.FormatError(err))
-					logger.Info(fmt.Sprintf("部门[%s]直属用户列表获取失败,终止获取", id))
-					return errors.New("")
+					return
 				}
 				logger.Error(logger.FormatError(err))
-				logger.Info(fmt.Sprintf("部门[%s]直属用户列表获取失败,正在重试...", id))
-				time.Sleep(FeiShuDefaultInterval)
-				continue
+				return
 			}
-			node.Children = append(node.Children, deptNode)
-			deptNode.User = append(deptNode.User, users...)
 			if HttpCanceled {
-				return nil
+				return
+			}
+			builder := fs.NewSendMessageReqBuilder(FeiShuClient).Content(content)
+			var msg *fs.MessageEntry
+			switch {
+			case replyMessageId != "":
+				msg, err = FeiShuClient.Message.Reply(builder.MessageId(replyMessageId).MsgType(msgType).Build())
+			case updateMessageId != "" && msgType == "interactive":
+				msg, err = FeiShuClient.Message.Update(builder.MessageId(updateMessageId).Build())
+			case updateMessageId != "":
+				msg, err = FeiShuClient.Message.Edit(builder.MessageId(updateMessageId).MsgType(msgType).Build())
+			default:
+				msg, err = FeiShuClient.Message.Send(builder.ReceiveIdType(receiveIdType).ReceiveId(receiveId).MsgType(msgType).Build())
 			}
-			err = cli.fetchDepartment(deptNode, id, deptIdType, userIdType)
 			if err != nil {
 				if errors.Is(err, context.Canceled) {
-					return nil
+					return
 				}
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			if HttpCanceled {
+				return
+			}
+			if updateMessageId != "" {
+				logger.Success(fmt.Sprintf("消息[%s]已更新", updateMessageId))
+				return
+			}
+			logger.Success(fmt.Sprintf("发送成功,message_id: %s", msg.MessageId))
+		},
+	}
+}
+
+func (cli *feiShuCli) newAudit() *cobra.Command {
+	return &cobra.Command{
+		Use:   "audit",
+		Short: `审计日志`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if !cli.hasTenantAccessToken() {
+				return fmt.Errorf("请先执行run获取tenant_access_token")
+			}
+			if err := cli.checkIdType(); err != nil {
 				return err
 			}
-			break
+			return nil
+		},
+	}
+}
+
+func (cli *feiShuCli) newAuditLs() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Short: `查询审计日志`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.fillDefaultIdType()
+			latest := time.Now().Unix()
+			if toTime != "" {
+				t, err := parseTimeArg(toTime)
+				if err != nil {
+					logger.Error(logger.FormatError(err))
+					return
+				}
+				latest = t
+			}
+			oldest := latest - 7*24*3600
+			if fromTime != "" {
+				t, err := parseTimeArg(fromTime)
+				if err != nil {
+					logger.Error(logger.FormatError(err))
+					return
+				}
+				oldest = t
+			}
+			builder := fs.NewListAuditInfoReqBuilder(FeiShuClient).
+				UserIdType(userIdTypeMap[userIdType]).
+				Oldest(oldest).
+				Latest(latest).
+				PageSize(20)
+			if userId != "" {
+				builder.OperatorValue(userId)
+			}
+			if eventName != "" {
+				builder.EventName(eventName)
+			}
+			logger.Info(fmt.Sprintf("正在查询%s至%s的审计日志...", time.Unix(oldest, 0).Format("2006-01-02 15:04:05"),
+				time.Unix(latest, 0).Format("2006-01-02 15:04:05")))
+			items, err := FeiShuClient.Admin.AuditInfos(builder.Build())
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				logger.Error(logger.FormatError(err))
+				if len(items) == 0 {
+					return
+				}
+				logger.Warning("查询中断,会保存已获取数据")
+			}
+			if HttpCanceled {
+				return
+			}
+			if len(items) == 0 {
+				logger.Info("无可用数据")
+				return
+			}
+			for _, item := range items {
+				fmt.Printf("  -[%s] %s 操作人[%s] IP[%s]\n", time.Unix(item.EventTime, 0).Format("2006-01-02 15:04:05"),
+					item.EventName, auditOperatorOf(item), item.Ip)
+			}
+			logger.Info(fmt.Sprintf("共%d条审计日志", len(items)))
+			saveExport(items, "feishu_audit", func() ([]any, [][]any) {
+				headers := []any{"id", "事件时间", "事件名称", "模块", "操作人类型", "操作人ID", "操作人姓名", "操作人邮箱", "IP",
+					"操作对象", "接收者", "部门ID", "日志ID"}
+				var data [][]any
+				for i, item := range items {
+					operatorType := "用户"
+					if item.OperatorType == 1 {
+						operatorType = "机器人"
+					}
+					var objects, recipients []string
+					for _, object := range item.Objects {
+						objects = append(objects, fmt.Sprintf("%s:%s(%s)", object.ObjectType, object.ObjectValue, object.ObjectName))
+					}
+					for _, recipient := range item.Recipients {
+						recipients = append(recipients, fmt.Sprintf("%s:%s", recipient.RecipientType, recipient.RecipientValue))
+					}
+					data = append(data, []any{i + 1, time.Unix(item.EventTime, 0).Format("2006-01-02 15:04:05"), item.EventName,
+						item.EventModule, operatorType, item.OperatorValue, item.OperatorDetail.OperatorName,
+						item.OperatorDetail.OperatorEmail, item.Ip, strings.Join(objects, "、"), strings.Join(recipients, "、"),
+						strings.Join(item.DepartmentIds, "、"), item.EventId})
+				}
+				return headers, data
+			})
+		},
+	}
+}
+
+func (cli *feiShuCli) newStats() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: `用户活跃和功能使用数据`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if !cli.hasTenantAccessToken() {
+				return fmt.Errorf("请先执行run获取tenant_access_token")
+			}
+			if err := cli.checkIdType(); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+}
+
+func (cli *feiShuCli) newStatsDept() *cobra.Command {
+	return &cobra.Command{
+		Use:   "dept",
+		Short: `查询部门维度的用户活跃和功能使用数据`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.fillDefaultIdType()
+			startDate, endDate, err := parseDateRange(statsDate)
+			if err != nil {
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			deptId := "0"
+			if len(args) > 0 {
+				deptId = args[0]
+			}
+			req := fs.NewGetAdminStatsReqBuilder(FeiShuClient).
+				DepartmentIdType(departmentIdTypeMap[departmentIdType]).
+				DepartmentId(deptId).
+				StartDate(startDate).
+				EndDate(endDate).
+				ContainsChildDept(containsChild).
+				PageSize(100).
+				Build()
+			items, err := FeiShuClient.Admin.DeptStats(req)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			if HttpCanceled {
+				return
+			}
+			if len(items) == 0 {
+				logger.Info("无可用数据")
+				return
+			}
+			for _, item := range items {
+				fmt.Printf("  -[%s] %s 总人数[%d] 激活人数[%d] 活跃人数[%d] 活跃率[%s]\n", item.Date, item.DepartmentPath,
+					item.TotalUserNum, item.ActiveUserNum, item.SuiteDau, item.SuiteActiveRate)
+			}
+			saveExport(items, "feishu_dept_stats", func() ([]any, [][]any) {
+				headers := []any{"id", "日期", "部门ID", "部门名称", "部门路径", "总人数", "激活人数", "激活率", "活跃人数", "活跃率",
+					"新用户数", "新激活数", "离职人数", "消息活跃人数", "发送消息人数", "发送消息数", "云文档活跃人数", "创建文件人数",
+					"创建文件数", "日历活跃人数", "音视频活跃人数", "会议时长(分钟)"}
+				var data [][]any
+				for i, item := range items {
+					data = append(data, []any{i + 1, item.Date, item.DepartmentId, item.DepartmentName, item.DepartmentPath,
+						item.TotalUserNum, item.ActiveUserNum, item.ActiveUserRate, item.SuiteDau, item.SuiteActiveRate,
+						item.NewUserNum, item.NewActiveNum, item.ResignUserNum, item.ImDau, item.SendMessengerUserNum,
+						item.SendMessengerNum, item.DocsDau, item.CreateDocsUserNum, item.CreateDocsNum, item.CalDau,
+						item.VcDau, item.VcDuration})
+				}
+				return headers, data
+			})
+		},
+	}
+}
+
+func (cli *feiShuCli) newStatsUser() *cobra.Command {
+	return &cobra.Command{
+		Use:   "user",
+		Short: `查询用户维度的用户活跃和功能使用数据`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.fillDefaultIdType()
+			startDate, endDate, err := parseDateRange(statsDate)
+			if err != nil {
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			deptId := "0"
+			if len(args) > 0 {
+				deptId = args[0]
+			}
+			builder := fs.NewGetAdminStatsReqBuilder(FeiShuClient).
+				UserIdType(userIdTypeMap[userIdType]).
+				DepartmentIdType(departmentIdTypeMap[departmentIdType]).
+				DepartmentId(deptId).
+				StartDate(startDate).
+				EndDate(endDate).
+				PageSize(100)
+			if userId != "" {
+				builder.UserId(userId)
+			}
+			items, err := FeiShuClient.Admin.UserStats(builder.Build())
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			if HttpCanceled {
+				return
+			}
+			if len(items) == 0 {
+				logger.Info("无可用数据")
+				return
+			}
+			for _, item := range items {
+				fmt.Printf("  -[%s] %s(%s) %s 激活[%s] 活跃[%s] 最近活跃时间[%s]\n", item.Date, item.UserName, item.UserId,
+					item.DepartmentPath, boolToChinese(item.UserActiveFlag == 1), boolToChinese(item.SuiteActiveFlag == 1),
+					item.LastActiveTime)
+			}
+			saveExport(items, "feishu_user_stats", func() ([]any, [][]any) {
+				headers := []any{"id", "日期", "用户ID", "姓名", "部门名称", "部门路径", "添加时间", "是否激活", "激活时间", "是否活跃",
+					"最近活跃时间", "活跃设备", "操作系统", "版本类型", "发送消息数", "创建文件数", "创建日程数", "创建任务数", "会议数",
+					"会议时长(分钟)", "邮件发送数", "邮件接收数"}
+				var data [][]any
+				for i, item := range items {
+					data = append(data, []any{i + 1, item.Date, item.UserId, item.UserName, item.DepartmentName,
+						item.DepartmentPath, item.CreateTime, boolToChinese(item.UserActiveFlag == 1), item.RegisterTime,
+						boolToChinese(item.SuiteActiveFlag == 1), item.LastActiveTime, item.ActiveOs, item.OsName,
+						item.AppPackageType, item.SendMessengerNum, item.CreateDocsNum, item.CreateCalNum, item.CreateTaskNum,
+						item.VcNum, item.VcDuration, item.EmailSendCount, item.EmailReceiveCount})
+				}
+				return headers, data
+			})
+		},
+	}
+}
+
+// defaultCoreHRFields 搜索员工时默认返回的字段
+var defaultCoreHRFields = []string{"person_info.name", "person_info.phone_number", "person_info.email", "employee_number",
+	"employment_status", "employee_type_id", "department_id", "job_level_id", "job_id", "work_email_list",
+	"hire_date", "expiration_date", "effective_time", "direct_manager_id", "work_location_id"}
+
+func (cli *feiShuCli) newCoreHR() *cobra.Command {
+	return &cobra.Command{
+		Use:   "corehr",
+		Short: `飞书人事员工、个人信息、任职信息和部门`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if !cli.hasTenantAccessToken() {
+				return fmt.Errorf("请先执行run获取tenant_access_token")
+			}
+			if err := cli.checkIdType(); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+}
+
+func (cli *feiShuCli) newCoreHREmployees() *cobra.Command {
+	return &cobra.Command{
+		Use:   "employees",
+		Short: `查询人事员工列表`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.fillDefaultIdType()
+			items, err := cli.searchCoreHREmployees(userIdTypeMap[userIdType])
+			if !cli.checkCoreHRResult(items, err) {
+				return
+			}
+			for _, item := range items {
+				fmt.Printf("  -%s(%s) 工号[%s] 状态[%s]\n", item.String("person_info.name.display_name"),
+					item.String("employment_id"), item.String("employee_number"), item.String("employment_status.enum_name"))
+			}
+			logger.Info(fmt.Sprintf("共%d名员工", len(items)))
+			cli.saveCoreHRExport(items, "feishu_corehr_employees")
+		},
+	}
+}
+
+func (cli *feiShuCli) newCoreHRPersons() *cobra.Command {
+	return &cobra.Command{
+		Use:   "persons",
+		Short: `根据个人信息ID批量查询个人信息`,
+		Run: func(cmd *cobra.Command, args []string) {
+			ids := args
+			if inputFile != "" {
+				lines, err := utils.ReadLines(inputFile)
+				if err != nil {
+					logger.Error(logger.FormatError(err))
+					return
+				}
+				ids = append(ids, lines...)
+			}
+			if len(ids) == 0 {
+				logger.Error(errors.New("请提供个人信息ID"))
+				return
+			}
+			cli.fillDefaultIdType()
+			var items []fs.CoreHRRecord
+			var err error
+			for start := 0; start < len(ids); start += 100 {
+				end := start + 100
+				if end > len(ids) {
+					end = len(ids)
+				}
+				req := fs.NewCoreHRReqBuilder(FeiShuClient).
+					UserIdType(userIdTypeMap[userIdType]).
+					PersonIds(ids[start:end]).
+					Build()
+				var persons []fs.CoreHRRecord
+				persons, err = FeiShuClient.CoreHR.Persons(req)
+				if err != nil {
+					break
+				}
+				items = append(items, persons...)
+				if HttpCanceled {
+					return
+				}
+			}
+			if !cli.checkCoreHRResult(items, err) {
+				return
+			}
+			for _, item := range items {
+				fmt.Printf("  -%s(%s)\n", item.String("name.display_name"), item.String("person_id"))
+			}
+			cli.saveCoreHRExport(items, "feishu_corehr_persons")
+		},
+	}
+}
+
+func (cli *feiShuCli) newCoreHRJobs() *cobra.Command {
+	return &cobra.Command{
+		Use:   "jobs",
+		Short: `查询任职信息列表`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.fillDefaultIdType()
+			req := fs.NewCoreHRReqBuilder(FeiShuClient).
+				UserIdType(userIdTypeMap[userIdType]).
+				DepartmentIdType(departmentIdTypeMap[departmentIdType]).
+				PageSize(100).
+				Build()
+			items, err := FeiShuClient.CoreHR.JobDatas(req)
+			if !cli.checkCoreHRResult(items, err) {
+				return
+			}
+			for _, item := range items {
+				fmt.Printf("  -%s 员工[%s] 部门[%s] 生效时间[%s]\n", item.String("id"), item.String("employment_id"),
+					item.String("department_id"), item.String("effective_time"))
+			}
+			logger.Info(fmt.Sprintf("共%d条任职信息", len(items)))
+			cli.saveCoreHRExport(items, "feishu_corehr_jobs")
+		},
+	}
+}
+
+func (cli *feiShuCli) newCoreHRDepts() *cobra.Command {
+	return &cobra.Command{
+		Use:   "depts",
+		Short: `查询人事部门列表`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.fillDefaultIdType()
+			req := fs.NewCoreHRReqBuilder(FeiShuClient).
+				UserIdType(userIdTypeMap[userIdType]).
+				DepartmentIdType(departmentIdTypeMap[departmentIdType]).
+				PageSize(100).
+				Build()
+			items, err := FeiShuClient.CoreHR.Departments(req)
+			if !cli.checkCoreHRResult(items, err) {
+				return
+			}
+			for _, item := range items {
+				fmt.Printf("  -%s(%s) 上级部门[%s]\n", coreHRI18nName(item), item.String("id"),
+					item.String("hiberarchy_common.parent_id"))
+			}
+			logger.Info(fmt.Sprintf("共%d个部门", len(items)))
+			cli.saveCoreHRExport(items, "feishu_corehr_depts")
+		},
+	}
+}
+
+func (cli *feiShuCli) newCoreHRJoin() *cobra.Command {
+	return &cobra.Command{
+		Use:   "join",
+		Short: `根据部门ID导出通讯录用户并按user_id关联人事员工信息`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.fillDefaultIdType()
+			deptId := "0"
+			if len(args) > 0 {
+				deptId = args[0]
+			}
+			// 人事员工的employment_id在user_id_type为user_id时与通讯录user_id一致
+			didType := departmentIdTypeMap[departmentIdType]
+			logger.Info(fmt.Sprintf("正在获取部门[%s]的用户...", deptId))
+			req := fs.NewGetDepartmentReqBuilder(FeiShuClient).
+				DepartmentId(deptId).
+				DepartmentIdType(didType).
+				UserIdType("user_id").
+				Build()
+			deptInfo, err := FeiShuClient.Department.Get(req)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			deptNode := &FeiShuDepartmentNode{
+				Name:               deptInfo.Name,
+				DepartmentID:       deptInfo.DepartmentID,
+				OpenDepartmentID:   deptInfo.OpenDepartmentID,
+				ParentDepartmentID: deptInfo.ParentDepartmentID,
+				LeaderUserID:       deptInfo.LeaderUserID,
+				UnitIds:            []*string{},
+				DepartmentHrbps:    []*string{},
+				User:               []*fs.UserEntry{},
+				Children:           []*FeiShuDepartmentNode{},
+			}
+			req1 := fs.NewGetUsersByDepartmentIdReqBuilder(FeiShuClient).
+				DepartmentId(deptId).
+				DepartmentIdType(didType).
+				UserIdType("user_id").
+				Build()
+			users, err := FeiShuClient.User.GetUsersByDepartmentId(req1)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			deptNode.User = append(deptNode.User, users...)
+			if err = cli.fetchDepartment(deptNode, deptId, didType, "user_id", defaultWorkers, nil); err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				logger.Error(logger.FormatError(err))
+				logger.Warning("通讯录用户获取中断,会使用已获取数据")
+			}
+			if HttpCanceled {
+				return
+			}
+			rows := cli.fetchColItem([]*FeiShuDepartmentNode{deptNode})
+			logger.Info(fmt.Sprintf("共获取%d名通讯录用户,正在获取人事员工信息...", len(rows)))
+			employees, err := cli.searchCoreHREmployees("user_id")
+			if !cli.checkCoreHRResult(employees, err) {
+				return
+			}
+			employeeMap := map[string]fs.CoreHRRecord{}
+			for _, employee := range employees {
+				employeeMap[employee.String("employment_id")] = employee
+			}
+			columns := fs.CoreHRColumns(employees)
+			headers := []any{"id", "关联状态", "部门名称", "部门ID", "部门open_department_id", "user_id", "open_id", "姓名",
+				"手机号", "邮箱", "工号", "用户状态"}
+			for _, column := range columns {
+				headers = append(headers, "corehr."+column)
+			}
+			var data [][]any
+			var joined []map[string]any
+			matched := map[string]bool{}
+			var onlyContact int
+			for _, row := range rows {
+				user := row.User
+				employee, ok := employeeMap[user.UserId]
+				status := "仅通讯录"
+				if ok {
+					status = "已关联"
+					matched[user.UserId] = true
+				} else {
+					onlyContact++
+				}
+				line := []any{len(data) + 1, status, row.Name, row.DepartmentID, row.OpenDepartmentID, user.UserId,
+					user.OpenId, user.Name, user.Mobile, user.Email, user.EmployeeNo, strings.Join(userStatusOf(&user), "、")}
+				line = append(line, coreHRRowValues(employee, columns)...)
+				data = append(data, line)
+				joined = append(joined, map[string]any{"status": status, "department": row.Name, "user": user, "corehr": employee})
+			}
+			var onlyCoreHR int
+			for _, employee := range employees {
+				id := employee.String("employment_id")
+				if matched[id] {
+					continue
+				}
+				onlyCoreHR++
+				line := []any{len(data) + 1, "仅人事", "", "", "", id, "", employee.String("person_info.name.display_name"),
+					"", "", employee.String("employee_number"), ""}
+				line = append(line, coreHRRowValues(employee, columns)...)
+				data = append(data, line)
+				joined = append(joined, map[string]any{"status": "仅人事", "corehr": employee})
+			}
+			logger.Info(fmt.Sprintf("已关联%d名,仅通讯录%d名,仅人事%d名", len(matched), onlyContact, onlyCoreHR))
+			saveExport(joined, "feishu_corehr_join", func() ([]any, [][]any) {
+				return headers, data
+			})
+		},
+	}
+}
+
+// searchCoreHREmployees 搜索所有人事员工,未指定--fields时使用默认字段
+func (cli *feiShuCli) searchCoreHREmployees(uidType string) ([]fs.CoreHRRecord, error) {
+	fields := corehrFields
+	if len(fields) == 0 {
+		fields = defaultCoreHRFields
+	}
+	req := fs.NewCoreHRReqBuilder(FeiShuClient).
+		UserIdType(uidType).
+		DepartmentIdType(departmentIdTypeMap[departmentIdType]).
+		Fields(fields).
+		PageSize(100).
+		Build()
+	return FeiShuClient.CoreHR.SearchEmployees(req)
+}
+
+// checkCoreHRResult 处理人事接口的错误,出错时已获取数据不为空则继续保存
+func (cli *feiShuCli) checkCoreHRResult(items []fs.CoreHRRecord, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return false
+		}
+		logger.Error(logger.FormatError(err))
+		if len(items) == 0 {
+			return false
+		}
+		logger.Warning("查询中断,会保存已获取数据")
+	}
+	if HttpCanceled {
+		return false
+	}
+	if len(items) == 0 {
+		logger.Info("无可用数据")
+		return false
+	}
+	return true
+}
+
+// saveCoreHRExport 将人事记录展开后导出XLSX和JSON
+func (cli *feiShuCli) saveCoreHRExport(items []fs.CoreHRRecord, basename string) {
+	saveExport(items, basename, func() ([]any, [][]any) {
+		columns := fs.CoreHRColumns(items)
+		headers := []any{"id"}
+		for _, column := range columns {
+			headers = append(headers, column)
+		}
+		var data [][]any
+		for i, item := range items {
+			data = append(data, append([]any{i + 1}, coreHRRowValues(item, columns)...))
+		}
+		return headers, data
+	})
+}
+
+// coreHRRowValues 按列顺序返回人事记录展开后的值,记录为空时返回空值
+func coreHRRowValues(record fs.CoreHRRecord, columns []string) []any {
+	flat := record.Flatten()
+	values := make([]any, 0, len(columns))
+	for _, column := range columns {
+		values = append(values, flat[column])
+	}
+	return values
+}
+
+// coreHRI18nName 返回人事部门名称,优先取中文
+func coreHRI18nName(record fs.CoreHRRecord) string {
+	names, ok := record.Get("hiberarchy_common.name").([]any)
+	if !ok || len(names) == 0 {
+		return ""
+	}
+	for _, name := range names {
+		if m, ok := name.(map[string]any); ok && m["lang"] == "zh-CN" {
+			return corehrString(m["value"])
+		}
+	}
+	if m, ok := names[0].(map[string]any); ok {
+		return corehrString(m["value"])
+	}
+	return ""
+}
+
+func corehrString(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+func (cli *feiShuCli) newEmail() *cobra.Command {
+	return &cobra.Command{
+		Use:   "email",
+		Short: `企业邮箱操作`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if !cli.hasTenantAccessToken() {
+				return fmt.Errorf("请先执行run获取tenant_access_token")
+			}
+			if userIdType == "" {
+				return nil
+			}
+			if _, ok := userIdTypeMap[userIdType]; !ok {
+				return errors.New("--ut :错误的用户ID类型,可选值：" + strings.Join(sortedKeys(userIdTypeMap), "、"))
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+
+		},
+	}
+}
+
+func (cli *feiShuCli) newEmailPasswordUpdate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "update",
+		Short: `更新密码`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.resolveUserIdType(userId)
+			req := fs.NewUserEmailPasswordChangeReqBuilder(FeiShuClient).
+				UserIdType(userIdTypeMap[userIdType]).
+				PostData(userId, password).
+				Build()
+			err := FeiShuClient.User.EmailPasswordUpdate(req)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			logger.Success("修改成功")
+		},
+	}
+}
+
+func (cli *feiShuCli) newDump() *cobra.Command {
+	return &cobra.Command{
+		Use:   "dump",
+		Short: `根据部门ID导出用户,不提供部门ID则导出通讯录授权范围内所有部门用户`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if !cli.hasTenantAccessToken() {
+				return fmt.Errorf("请先执行run获取tenant_access_token")
+			}
+			if err := cli.checkIdType(); err != nil {
+				return err
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if dumpResume && len(args) > 0 {
+				logger.Warning("--resume会使用上次的部门ID,忽略提供的部门ID")
+				args = nil
+			}
+			if len(args) > 0 {
+				auto := cli.resolveDepartmentIdType(args[0])
+				if auto {
+					if _, err := cli.getDepartmentWithFallback(args[0], auto); err != nil {
+						if errors.Is(err, context.Canceled) {
+							return
+						}
+						logger.Error(logger.FormatError(err))
+						return
+					}
+				}
+			}
+			cli.fillDefaultIdType()
+			var deptNodeList []*FeiShuDepartmentNode
+			var uidType = userIdTypeMap[userIdType]
+			var didType = departmentIdTypeMap[departmentIdType]
+			conf := FeiShuClient.GetAuthScopeFromCache()
+			//根据指定部门ID先获取子部门列表
+			var isErrorOcurred bool
+			var deptIds []string
+			if len(args) == 0 {
+				deptIds = sortedKeys(conf.DepartmentScope)
+			} else {
+				deptIds = append(deptIds, args[0])
+			}
+			var checkpoint *dumpCheckpoint
+			if dumpResume {
+				var err error
+				checkpoint, err = loadDumpCheckpoint(plugin.PlatformFeishu, valueOf(conf.AppId))
+				if err != nil {
+					logger.Error(logger.FormatError(err))
+					return
+				}
+				// 继续时使用断点中的部门ID和ID类型,保证与已获取的数据一致
+				deptIds, didType, uidType = checkpoint.DeptIds, checkpoint.DeptIdType, checkpoint.UserIdType
+				cli.indexCheckpoint(checkpoint)
+				logger.Info(fmt.Sprintf("从断点继续导出,已获取%d个部门", checkpoint.Count()))
+			} else {
+				checkpoint = newDumpCheckpoint(plugin.PlatformFeishu, valueOf(conf.AppId), deptIds, didType, uidType, len(args) == 0)
+			}
+			// canceled根据返回的错误和上下文判断,不依赖Ctrl+C后才设置的HttpCanceled
+			var canceled bool
+			isCanceled := func(err error) bool {
+				return errors.Is(err, context.Canceled) || interrupted()
+			}
+			for _, deptId := range deptIds {
+				if interrupted() {
+					canceled = true
+					break
+				}
+				deptNode, err := cli.fetchDumpRoot(deptId, didType, uidType, checkpoint)
+				if err != nil {
+					if isCanceled(err) {
+						canceled = true
+					} else {
+						isErrorOcurred = true
+						logger.Error(logger.FormatError(err))
+					}
+					break
+				}
+				deptNodeList = append(deptNodeList, deptNode)
+				err = cli.fetchDepartment(deptNode, deptId, didType, uidType, dumpWorkers, checkpoint)
+				if err != nil {
+					if isCanceled(err) {
+						canceled = true
+					} else {
+						isErrorOcurred = true
+						logger.Error(logger.FormatError(err))
+					}
+					break
+				}
+			}
+			if !isErrorOcurred && !canceled && includeGroup && len(conf.GroupScope) > 0 {
+				groupNodes, err := cli.fetchGroupOnlyUsers(deptNodeList, conf.GroupScope, uidType, didType)
+				if err != nil {
+					if isCanceled(err) {
+						canceled = true
+					} else {
+						logger.Error(logger.FormatError(err))
+						logger.Warning("用户组用户获取失败,会保存已获取数据")
+					}
+				}
+				deptNodeList = append(deptNodeList, groupNodes...)
+			}
+			// 不指定部门导出时,补充不在部门树内的单独授权用户
+			if !isErrorOcurred && !canceled && checkpoint.AllScope && len(conf.UserScope) > 0 {
+				scopeNode, err := cli.fetchScopeOnlyUsers(deptNodeList, conf.UserScope, userIdTypeMap[userIdTypeCache], didType)
+				if err != nil {
+					if isCanceled(err) {
+						canceled = true
+					} else {
+						logger.Error(logger.FormatError(err))
+						logger.Warning("单独授权用户获取失败,会保存已获取数据")
+					}
+				}
+				if len(scopeNode.User) > 0 {
+					deptNodeList = append(deptNodeList, scopeNode)
+				}
+			}
+			// 中断或者出错时保存断点,已获取的数据导出至带_partial后缀的文件
+			partial := isErrorOcurred || canceled || interrupted()
+			filename := "feishu_dump"
+			if partial {
+				filename += "_partial"
+				logger.Warning("导出未完成,会保存已获取数据")
+				if err := checkpoint.Save(); err != nil {
+					logger.Error(logger.FormatError(err))
+				} else {
+					logger.Info(fmt.Sprintf("已保存断点(已获取%d个部门),执行dump --resume继续导出", checkpoint.Count()))
+				}
+				if len(deptNodeList) == 0 {
+					logger.Warning("无已获取数据")
+					return
+				}
+			} else if err := checkpoint.Remove(); err != nil {
+				logger.Error(logger.FormatError(err))
+			}
+			logger.Info("正在保存至html文件...")
+			msg, err := cli.saveDepartmentTreeWithUsersToHTML(deptNodeList, filename+".html")
+			if err != nil {
+				logger.Error(logger.FormatError(err))
+				logger.Info("保存文件失败")
+			} else {
+				logger.Success(msg)
+			}
+			logger.Info("正在保存至xlsx文件...")
+			msg, err = cli.saveDepartmentWithUserToExcel(deptNodeList, filename+".xlsx")
+			if err != nil {
+				logger.Error(logger.FormatError(err))
+				logger.Info("保存文件失败")
+			} else {
+				logger.Success(msg)
+			}
+			if partial {
+				if bitableAppToken != "" {
+					logger.Warning("导出未完成,不同步至多维表格")
+				}
+				return
+			}
+			if bitableAppToken != "" {
+				logger.Info("正在同步至多维表格...")
+				result, err := cli.syncToBitable(deptNodeList, bitableAppToken, bitableTable)
+				if err != nil {
+					if errors.Is(err, context.Canceled) {
+						return
+					}
+					logger.Error(logger.FormatError(err))
+					if len(result) == 0 {
+						return
+					}
+					logger.Warning("同步中断,同步报告可能不完整")
+				}
+				cli.saveBitableSyncReport(result)
+			}
+		},
+	}
+}
+
+// callApi 使用tenant_access_token调用任意接口
+func (cli *feiShuCli) callApi(method, path string, query [][2]string, body []byte) (*plugin.RawResponse, error) {
+	builder := fs.NewCallReqBuilder(FeiShuClient).Method(method).Path(path).Body(body)
+	for _, kv := range query {
+		builder.Query(kv[0], kv[1])
+	}
+	return FeiShuClient.Call(builder.Build())
+}
+
+// checkIdType 校验--dt和--ut,未设置时会自动识别或者使用会话默认值
+func (cli *feiShuCli) checkIdType() error {
+	if _, ok := departmentIdTypeMap[departmentIdType]; departmentIdType != "" && !ok {
+		return errors.New("--dt :错误的部门ID类型,可选值：" + strings.Join(sortedKeys(departmentIdTypeMap), "、"))
+	}
+	if _, ok := userIdTypeMap[userIdType]; userIdType != "" && !ok {
+		return errors.New("--ut :错误的用户ID类型,可选值：" + strings.Join(sortedKeys(userIdTypeMap), "、"))
+	}
+	return nil
+}
+
+// detectDepartmentIdType 根据部门ID前缀识别部门ID类型,根部门0两种类型均可
+func detectDepartmentIdType(did string) string {
+	if strings.HasPrefix(did, "od-") {
+		return "openid"
+	}
+	if did == "0" {
+		return departmentIdTypeCache
+	}
+	return "id"
+}
+
+// detectUserIdType 根据用户ID前缀识别用户ID类型
+func detectUserIdType(uid string) string {
+	switch {
+	case strings.HasPrefix(uid, "ou_"):
+		return "openid"
+	case strings.HasPrefix(uid, "on_"):
+		return "unionid"
+	default:
+		return "id"
+	}
+}
+
+// resolveDepartmentIdType 未通过--dt指定时根据<did>识别部门ID类型,返回是否为自动识别
+func (cli *feiShuCli) resolveDepartmentIdType(did string) bool {
+	if departmentIdType != "" {
+		return false
+	}
+	departmentIdType = detectDepartmentIdType(did)
+	return true
+}
+
+// resolveUserIdType 未通过--ut指定时根据<uid>识别用户ID类型,返回是否为自动识别
+func (cli *feiShuCli) resolveUserIdType(uid string) bool {
+	if userIdType != "" {
+		return false
+	}
+	userIdType = detectUserIdType(uid)
+	return true
+}
+
+// fillDefaultIdType 未指定的ID类型使用会话默认值
+func (cli *feiShuCli) fillDefaultIdType() {
+	if departmentIdType == "" {
+		departmentIdType = departmentIdTypeCache
+	}
+	if userIdType == "" {
+		userIdType = userIdTypeCache
+	}
+}
+
+// tryIdTypes 执行fn,自动识别的ID类型返回ID不匹配的错误时依次尝试其它类型,成功后idType保持为可用的类型
+func (cli *feiShuCli) tryIdTypes(idType *string, typeMap map[string]string, auto bool, fn func() error) error {
+	err := fn()
+	if err == nil || !auto || !isIdMismatchError(err) {
+		return err
+	}
+	detected := *idType
+	for _, t := range sortedKeys(typeMap) {
+		if t == detected {
+			continue
+		}
+		logger.Info(fmt.Sprintf("ID类型[%s]获取失败,正在尝试[%s]...", detected, t))
+		*idType = t
+		e := fn()
+		if e == nil {
+			return nil
+		}
+		if !isIdMismatchError(e) {
+			return e
+		}
+	}
+	*idType = detected
+	return err
+}
+
+// getDepartmentWithFallback 获取部门详情,自动识别的部门ID类型不匹配时尝试其它类型
+func (cli *feiShuCli) getDepartmentWithFallback(did string, auto bool) (fs.DepartmentEntry, error) {
+	var deptInfo fs.DepartmentEntry
+	err := cli.tryIdTypes(&departmentIdType, departmentIdTypeMap, auto, func() error {
+		var err error
+		req := fs.NewGetDepartmentReqBuilder(FeiShuClient).
+			DepartmentId(did).
+			DepartmentIdType(departmentIdTypeMap[departmentIdType]).
+			UserIdType(userIdTypeMap[userIdType]).
+			Build()
+		deptInfo, err = FeiShuClient.Department.Get(req)
+		return err
+	})
+	return deptInfo, err
+}
+
+// getUserWithFallback 获取用户详情,自动识别的用户ID类型不匹配时尝试其它类型
+func (cli *feiShuCli) getUserWithFallback(uid string, auto bool) (*fs.UserEntry, error) {
+	var userInfo *fs.UserEntry
+	err := cli.tryIdTypes(&userIdType, userIdTypeMap, auto, func() error {
+		var err error
+		req := fs.NewGetUserReqBuilder(FeiShuClient).
+			UserId(uid).
+			UserIdType(userIdTypeMap[userIdType]).
+			DepartmentIdType(departmentIdTypeMap[departmentIdType]).
+			Build()
+		userInfo, err = FeiShuClient.User.Get(req)
+		return err
+	})
+	return userInfo, err
+}
+
+// idMismatchCodes ID类型不匹配导致参数校验失败时返回的错误码,
+// 无部门权限(40004)、无用户权限(41050)等权限错误换ID类型也无法解决,直接返回原始错误
+var idMismatchCodes = []int{99992402}
+
+// isIdMismatchError 只有ID类型可能不匹配时才尝试其它类型,权限、频率限制和凭证错误换类型无意义
+func isIdMismatchError(err error) bool {
+	var apiErr *plugin.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, code := range idMismatchCodes {
+		if apiErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (cli *feiShuCli) showGroupInfo(groupInfo *fs.GroupEntry, inLine bool) {
+	var groupType = "普通用户组"
+	if groupInfo.Type == 2 {
+		groupType = "动态用户组"
+	}
+	if inLine {
+		fmt.Printf("  -ID[%s] 名称[%s] 类型[%s] 用户数[%d] 部门数[%d] 描述[%s]\n", groupInfo.Id, groupInfo.Name, groupType,
+			groupInfo.MemberUserCount, groupInfo.MemberDepartmentCount, groupInfo.Description)
+		return
+	}
+	fmt.Printf("%s\n", strings.Repeat("=", 20))
+	fmt.Printf("%-14s: %s\n", "ID", groupInfo.Id)
+	fmt.Printf("%-12s: %s\n", "名称", groupInfo.Name)
+	fmt.Printf("%-12s: %s\n", "类型", groupType)
+	fmt.Printf("%-11s: %d\n", "用户数", groupInfo.MemberUserCount)
+	fmt.Printf("%-11s: %d\n", "部门数", groupInfo.MemberDepartmentCount)
+	fmt.Printf("%-12s: %s\n", "描述", groupInfo.Description)
+	fmt.Printf("%s\n", strings.Repeat("=", 20))
+}
+
+// getGroupUsers 获取用户组中用户成员的详情,获取详情失败的用户仅保留ID
+func (cli *feiShuCli) getGroupUsers(groupId, uidType, didType string) ([]*fs.UserEntry, error) {
+	req := fs.NewGetGroupMembersReqBuilder(FeiShuClient).
+		GroupId(groupId).
+		MemberType("user").
+		MemberIdType(uidType).
+		PageSize(100).
+		Build()
+	members, err := FeiShuClient.Group.Members(req)
+	if err != nil {
+		return nil, err
+	}
+	var users []*fs.UserEntry
+	for _, member := range members {
+		if HttpCanceled {
+			return users, nil
+		}
+		req := fs.NewGetUserReqBuilder(FeiShuClient).
+			UserId(member.MemberId).
+			UserIdType(uidType).
+			DepartmentIdType(didType).
+			Build()
+		userInfo, err := FeiShuClient.User.Get(req)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return users, err
+			}
+			logger.Error(logger.FormatError(err))
+			userInfo = &fs.UserEntry{}
+			switch uidType {
+			case "user_id":
+				userInfo.UserId = member.MemberId
+			case "union_id":
+				userInfo.UnionId = member.MemberId
+			default:
+				userInfo.OpenId = member.MemberId
+			}
+		}
+		users = append(users, userInfo)
+	}
+	return users, nil
+}
+
+// fetchGroupOnlyUsers 获取授权范围内用户组中不在已导出部门树内的用户,每个用户组生成一个虚拟部门节点
+func (cli *feiShuCli) fetchGroupOnlyUsers(tree []*FeiShuDepartmentNode, groupScope map[string]string, uidType, didType string) ([]*FeiShuDepartmentNode, error) {
+	exists := collectUserIds(tree)
+	var nodes []*FeiShuDepartmentNode
+	for _, groupId := range sortedKeys(groupScope) {
+		logger.Info(fmt.Sprintf("正在获取用户组[%s]的用户...", groupId))
+		users, err := cli.getGroupUsers(groupId, uidType, didType)
+		if err != nil {
+			return nodes, err
+		}
+		if HttpCanceled {
+			return nodes, nil
+		}
+		node := &FeiShuDepartmentNode{
+			Name:            "用户组: " + groupScope[groupId],
+			DepartmentID:    groupId,
+			Status:          "用户组",
+			UnitIds:         []*string{},
+			DepartmentHrbps: []*string{},
+			User:            []*fs.UserEntry{},
+			Children:        []*FeiShuDepartmentNode{},
+		}
+		for _, user := range users {
+			if hasUserId(exists, user) {
+				continue
+			}
+			addUserIds(exists, user)
+			node.User = append(node.User, user)
+		}
+		if len(node.User) > 0 {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+// fetchScopeOnlyUsers 获取单独授权且不在已导出部门树内的用户,userScope的键为执行run时的用户ID类型
+func (cli *feiShuCli) fetchScopeOnlyUsers(tree []*FeiShuDepartmentNode, userScope map[string]string, scopeUidType, didType string) (*FeiShuDepartmentNode, error) {
+	exists := collectUserIds(tree)
+	node := &FeiShuDepartmentNode{
+		Name:            "单独授权用户",
+		Status:          "单独授权",
+		UnitIds:         []*string{},
+		DepartmentHrbps: []*string{},
+		User:            []*fs.UserEntry{},
+		Children:        []*FeiShuDepartmentNode{},
+	}
+	for _, uid := range sortedKeys(userScope) {
+		if exists[uid] {
+			continue
+		}
+		if HttpCanceled {
+			return node, nil
+		}
+		logger.Info(fmt.Sprintf("正在获取单独授权用户[%s]的信息...", uid))
+		req := fs.NewGetUserReqBuilder(FeiShuClient).
+			UserId(uid).
+			UserIdType(scopeUidType).
+			DepartmentIdType(didType).
+			Build()
+		userInfo, err := FeiShuClient.User.Get(req)
+		if err != nil {
+			return node, err
+		}
+		if hasUserId(exists, userInfo) {
+			continue
+		}
+		addUserIds(exists, userInfo)
+		node.User = append(node.User, userInfo)
+	}
+	return node, nil
+}
+
+// collectUserIds 收集部门树内所有用户的user_id、open_id和union_id
+func collectUserIds(tree []*FeiShuDepartmentNode) map[string]bool {
+	exists := map[string]bool{}
+	var walk func(nodes []*FeiShuDepartmentNode)
+	walk = func(nodes []*FeiShuDepartmentNode) {
+		for _, node := range nodes {
+			for _, user := range node.User {
+				addUserIds(exists, user)
+			}
+			walk(node.Children)
+		}
+	}
+	walk(tree)
+	return exists
+}
+
+func addUserIds(exists map[string]bool, user *fs.UserEntry) {
+	for _, id := range []string{user.UserId, user.OpenId, user.UnionId} {
+		if id != "" {
+			exists[id] = true
+		}
+	}
+}
+
+func hasUserId(exists map[string]bool, user *fs.UserEntry) bool {
+	for _, id := range []string{user.UserId, user.OpenId, user.UnionId} {
+		if id != "" && exists[id] {
+			return true
+		}
+	}
+	return false
+}
+
+func (cli *feiShuCli) showChatInfo(chatInfo *fs.ChatInfo) {
+	fmt.Printf("%s\n", strings.Repeat("=", 20))
+	fmt.Printf("%-14s: %s\n", "CHAT_ID", chatInfo.ChatId)
+	fmt.Printf("%-12s: %s\n", "名称", chatInfo.Name)
+	fmt.Printf("%-12s: %s\n", "描述", chatInfo.Description)
+	fmt.Printf("%-12s: %s\n", "群主", chatInfo.OwnerId)
+	fmt.Printf("%-11s: %s\n", "外部群", boolToChinese(chatInfo.External))
+	fmt.Printf("%-10s: %s\n", "群组模式", chatInfo.ChatMode)
+	fmt.Printf("%-10s: %s\n", "群组类型", chatInfo.ChatType)
+	fmt.Printf("%-10s: %s\n", "群组标签", chatInfo.ChatTag)
+	fmt.Printf("%-11s: %s\n", "用户数", chatInfo.UserCount)
+	fmt.Printf("%-11s: %s\n", "机器人数", chatInfo.BotCount)
+	fmt.Printf("%-10s: %s\n", "加群审批", chatInfo.MembershipApproval)
+	fmt.Printf("%-10s: %s\n", "入群权限", chatInfo.AddMemberPermission)
+	fmt.Printf("%s\n", strings.Repeat("=", 20))
+}
+
+func boolToChinese(b bool) string {
+	if b {
+		return "是"
+	}
+	return "否"
+}
+
+// saveChatToExcel 生成包含群信息和群成员的XLSX文档,每个成员一行,members与chats一一对应
+func (cli *feiShuCli) saveChatToExcel(chats []*fs.ChatInfo, members [][]*fs.ChatMemberEntry, filename string) (string, error) {
+	if !strings.HasSuffix(filename, ".xlsx") {
+		filename = filename + ".xlsx"
+	}
+	tmp := filename
+	// 判断文件是否存在
+	if utils.IsFileExists(filename) {
+		newFilename := generateNewFilename(filename)
+		filename = newFilename
+	}
+
+	// 设置表头
+	headers := []any{"id", "群ID", "群名称", "群描述", "群主ID", "是否外部群", "群组模式", "群组类型", "用户数", "机器人数",
+		"成员ID", "成员ID类型", "成员姓名", "成员租户"}
+
+	var data [][]any
+	for i, c := range chats {
+		meta := []any{c.ChatId, c.Name, c.Description, c.OwnerId, boolToChinese(c.External), c.ChatMode, c.ChatType,
+			c.UserCount, c.BotCount}
+		if len(members[i]) == 0 {
+			row := append([]any{len(data) + 1}, meta...)
+			data = append(data, append(row, "", "", "", ""))
+			continue
+		}
+		for _, member := range members[i] {
+			row := append([]any{len(data) + 1}, meta...)
+			data = append(data, append(row, member.MemberId, member.MemberIdType, member.Name, member.TenantKey))
+		}
+	}
+
+	// 保存文件
+	err := saveToExcel(headers, data, filename)
+	if err != nil {
+		return "", errors.New("保存 Excel 文件失败: " + err.Error())
+	}
+	if tmp != filename {
+		return fmt.Sprintf("%s 已存在,已另存为 %s", tmp, filename), nil
+	}
+	return fmt.Sprintf("文件已保存至 %s", filename), nil
+}
+
+// buildMessageContent 根据消息类型生成消息内容,image类型会先上传图片
+func (cli *feiShuCli) buildMessageContent() (string, error) {
+	switch msgType {
+	case "text":
+		text := msgText
+		if text == "" {
+			data, err := os.ReadFile(msgFile)
+			if err != nil {
+				return "", err
+			}
+			text = string(data)
+		}
+		content, err := json.Marshal(map[string]string{"text": text})
+		return string(content), err
+	case "image":
+		logger.Info("正在上传图片...")
+		req := fs.NewUploadImageReqBuilder(FeiShuClient).Filename(msgFile).Build()
+		imageKey, err := FeiShuClient.Message.UploadImage(req)
+		if err != nil {
+			return "", err
+		}
+		content, err := json.Marshal(map[string]string{"image_key": imageKey})
+		return string(content), err
+	default:
+		data, err := os.ReadFile(msgFile)
+		if err != nil {
+			return "", err
+		}
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal(data, &body); err != nil {
+			return "", errors.New("消息内容不是有效的JSON: " + err.Error())
+		}
+		// 兼容自定义机器人格式{"msg_type":"...","content":{"post":{...}}}或者{"msg_type":"interactive","card":{...}}
+		for _, key := range []string{"card", "content"} {
+			if v, ok := body[key]; ok {
+				data = v
+				body = nil
+				if err := json.Unmarshal(data, &body); err != nil {
+					return "", errors.New("消息内容不是有效的JSON: " + err.Error())
+				}
+				break
+			}
+		}
+		if v, ok := body["post"]; ok && msgType == "post" {
+			data = v
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+}
+
+// botActivateStatus 返回机器人激活状态描述
+func botActivateStatus(status int) string {
+	switch status {
+	case 0:
+		return "初始化,租户待安装"
+	case 1:
+		return "租户停用"
+	case 2:
+		return "租户启用"
+	case 3:
+		return "安装后待启用"
+	case 4:
+		return "升级待启用"
+	case 5:
+		return "license过期停用"
+	case 6:
+		return "套餐到期或降级停用"
+	}
+	return fmt.Sprintf("未知(%d)", status)
+}
+
+// parseTimeArg 解析yyyy-mm-dd、yyyy-mm-dd hh:mm:ss格式的本地时间或者秒级时间戳
+func parseTimeArg(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if ts, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return ts, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t.Unix(), nil
+		}
+	}
+	return 0, fmt.Errorf("无法解析时间: %s,格式为yyyy-mm-dd[ hh:mm:ss]或者秒级时间戳", s)
+}
+
+// parseDateRange 解析日期或者逗号分隔的日期范围,为空时返回昨天
+func parseDateRange(s string) (string, string, error) {
+	if strings.TrimSpace(s) == "" {
+		yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+		return yesterday, yesterday, nil
+	}
+	dates := strings.SplitN(s, ",", 2)
+	for i := range dates {
+		dates[i] = strings.TrimSpace(dates[i])
+		if _, err := time.Parse("2006-01-02", dates[i]); err != nil {
+			return "", "", fmt.Errorf("无法解析日期: %s,格式为yyyy-mm-dd", dates[i])
+		}
+	}
+	if len(dates) == 1 {
+		return dates[0], dates[0], nil
+	}
+	return dates[0], dates[1], nil
+}
+
+// auditOperatorOf 返回审计日志的操作人描述
+func auditOperatorOf(item *fs.AuditInfoEntry) string {
+	if item.OperatorDetail.OperatorName != "" {
+		return fmt.Sprintf("%s(%s)", item.OperatorDetail.OperatorName, item.OperatorValue)
+	}
+	return item.OperatorValue
+}
+
+func (cli *feiShuCli) hasTenantAccessToken() bool {
+	return FeiShuClient.GetTenantAccessTokenFromCache() != ""
+}
+
+func (cli *feiShuCli) showUserInfo(userInfo fs.UserEntry, inLine bool) {
+	var (
+		name            string
+		uid             string
+		oid             string
+		gender          string
+		employeeNo      string
+		employeeType    string
+		status          []string
+		phone           string
+		email           string
+		enterpriseEmail string
+		isTenantManager string
+		workLocation    string
+		depts           []string
+	)
+	name = userInfo.Name
+	uid = userInfo.UserId
+	oid = userInfo.OpenId
+	gender = fs.GenderName(userInfo.Gender)
+	employeeNo = userInfo.EmployeeNo
+	employeeType = fs.EmployeeTypeName(userInfo.EmployeeType, cli.getEmployeeTypeNames())
+	email = userInfo.Email
+	enterpriseEmail = userInfo.EnterpriseEmail
+	if userInfo.Status.IsUnjoin {
+		status = append(status, "未加入")
+	}
+	if userInfo.Status.IsActivated {
+		status = append(status, "已激活")
+	}
+	if userInfo.Status.IsResigned {
+		status = append(status, "已离职")
+	}
+	if userInfo.Status.IsFrozen {
+		status = append(status, "已冻结")
+	}
+	if userInfo.Status.IsExited {
+		status = append(status, "已退出")
+	}
+	phone = userInfo.Mobile
+	if userInfo.IsTenantManager {
+		isTenantManager = "是"
+	} else {
+		isTenantManager = "否"
+	}
+	for _, deptId := range userInfo.DepartmentIds {
+		deptName, err := feiShuDepartmentName(deptId, departmentIdTypeMap[departmentIdType], userIdTypeMap[userIdType])
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			continue
+		}
+		var info string
+		if deptName != "" {
+			info = fmt.Sprintf("%s(%s: %s)", deptName,
+				strings.ToUpper(departmentIdTypeMap[departmentIdType]), deptId)
+		} else {
+			info = fmt.Sprintf("%s: %s",
+				strings.ToUpper(departmentIdTypeMap[departmentIdType]), deptId)
+		}
+		depts = append(depts, info)
+	}
+	workLocation = workLocationOf(&userInfo)
+	if inLine {
+		s := fmt.Sprintf("  -ID[%s] OPEN_ID[%s] UNION_ID[%s] 姓名[%s] 性别[%s] 工号[%s] 人员类型[%s] 手机号码[%s] 邮箱[%s] 企业邮箱[%s] 状态[%s] 是否企业管理员[%s] 所属部门[%s] 工作地点[%s]", uid, oid, userInfo.UnionId, name, gender, employeeNo, employeeType, phone, email, enterpriseEmail, strings.Join(status, "、"), isTenantManager, strings.Join(depts, "、"), workLocation)
+		fmt.Println(s)
+		return
+	}
+	fmt.Printf("%s\n", strings.Repeat("=", 20))
+	fmt.Printf("%-14s: %s\n", "USER_ID", uid)
+	fmt.Printf("%-14s: %s\n", "OPEN_ID", oid)
+	fmt.Printf("%-14s: %s\n", "UNION_ID", userInfo.UnionId)
+	fmt.Printf("%-12s: %s\n", "姓名", name)
+	fmt.Printf("%-12s: %s\n", "性别", gender)
+	fmt.Printf("%-12s: %s\n", "工号", employeeNo)
+	fmt.Printf("%-10s: %s\n", "人员类型", employeeType)
+	fmt.Printf("%-10s: %s\n", "手机号码", phone)
+	fmt.Printf("%-12s: %s\n", "邮箱", email)
+	fmt.Printf("%-10s: %s\n", "企业邮箱", enterpriseEmail)
+	fmt.Printf("%-12s: %s\n", "状态", strings.Join(status, "、"))
+	fmt.Printf("%-9s: %s\n", "是否管理员", isTenantManager)
+	fmt.Printf("%-10s: %s\n", "所属部门", strings.Join(depts, "、"))
+	fmt.Printf("%-10s: %s\n", "工作地点", workLocation)
+	fmt.Printf("%-12s: %s\n", "主部门", primaryDepartmentOf(&userInfo))
+	fmt.Printf("%-12s: %s\n", "职称", userInfo.JobTitle)
+	fmt.Printf("%-12s: %s\n", "岗位", strings.Join(positionsOf(&userInfo), "、"))
+	fmt.Printf("%-10s: %s\n", "直属上级", userInfo.LeaderUserId)
+	fmt.Printf("%-10s: %s\n", "虚线上级", strings.Join(userInfo.DottedLineLeaderUserIds, "、"))
+	fmt.Printf("%-10s: %s\n", "个性签名", userInfo.Description)
+	fmt.Printf("%-12s: %s\n", "头像", userInfo.Avatar.AvatarOrigin)
+	for _, attr := range cli.getCustomAttrs() {
+		if value := customAttrValueOf(&userInfo, attr); value != "" {
+			fmt.Printf("%s: %s\n", attr.Name(), value)
+		}
+	}
+	fmt.Printf("%s\n", strings.Repeat("=", 20))
+}
+
+func (cli *feiShuCli) showClientConfig() {
+	fsClientConfig := FeiShuClient.GetAuthScopeFromCache()
+	fmt.Printf("%s\n", strings.Repeat("=", 40))
+	if Proxy == nil {
+		fmt.Println(fmt.Sprintf("%-17s: %s", "proxy", ""))
+	} else {
+		fmt.Println(fmt.Sprintf("%-17s: %s", "proxy", *Proxy))
+	}
+	fmt.Println(fmt.Sprintf("%-17s: %s", "emit", emitSetting()))
+	fmt.Println(fmt.Sprintf("%-17s: %s", "trace", traceSetting()))
+	fmt.Println(fmt.Sprintf("%-17s: %s", "qps", qpsSetting(FeiShuClient.Limiter())))
+	fmt.Println(fmt.Sprintf("%-17s: %s", "retry", retrySetting(FeiShuClient.RetryPolicy())))
+	fmt.Println(fmt.Sprintf("%-17s: %s", "cache", directorySetting(feiShuDirectory())))
+	if fsClientConfig.AppId == nil {
+		fmt.Println(fmt.Sprintf("%-17s: %s", "app_id", ""))
+	} else {
+		fmt.Println(fmt.Sprintf("%-17s: %s", "app_id", *fsClientConfig.AppId))
+	}
+	if fsClientConfig.AppSecret == nil {
+		fmt.Println(fmt.Sprintf("%-17s: %s", "app_secret", ""))
+	} else {
+		fmt.Println(fmt.Sprintf("%-17s: %s", "app_secret", *fsClientConfig.AppSecret))
+	}
+	if fsClientConfig.TenantAccessToken == nil {
+		fmt.Println(fmt.Sprintf("%-17s: %s", "tenant_access_token", ""))
+	} else {
+		fmt.Println(fmt.Sprintf("%-17s: %s", "tenant_access_token", *fsClientConfig.TenantAccessToken))
+	}
+	if fsClientConfig.Tenant != nil {
+		fmt.Println("-----------企业信息-----------")
+		tenantType := "团队版"
+		if fsClientConfig.Tenant.TenantTag == 2 {
+			tenantType = "个人版"
+		}
+		fmt.Println(fmt.Sprintf("%-13s: %s", "企业名称", fsClientConfig.Tenant.Name))
+		fmt.Println(fmt.Sprintf("%-13s: %s", "企业编号", fsClientConfig.Tenant.DisplayId))
+		fmt.Println(fmt.Sprintf("%-17s: %s", "tenant_key", fsClientConfig.Tenant.TenantKey))
+		fmt.Println(fmt.Sprintf("%-13s: %s", "企业版本", tenantType))
+		fmt.Println(fmt.Sprintf("%-13s: %s", "企业头像", fsClientConfig.Tenant.Avatar.AvatarOrigin))
+	}
+	if fsClientConfig.Bot != nil {
+		fmt.Println("-----------机器人信息-----------")
+		fmt.Println(fmt.Sprintf("%-13s: %s", "应用名称", fsClientConfig.Bot.AppName))
+		fmt.Println(fmt.Sprintf("%-17s: %s", "open_id", fsClientConfig.Bot.OpenId))
+		fmt.Println(fmt.Sprintf("%-13s: %s", "激活状态", botActivateStatus(fsClientConfig.Bot.ActivateStatus)))
+		fmt.Println(fmt.Sprintf("%-13s: %s", "应用头像", fsClientConfig.Bot.AvatarUrl))
+		fmt.Println(fmt.Sprintf("%-11s: %s", "IP白名单", strings.Join(fsClientConfig.Bot.IpWhiteList, "、")))
+	}
+	if len(fsClientConfig.AppScopes) > 0 {
+		var granted, notGranted []string
+		for _, scope := range fsClientConfig.AppScopes {
+			name := scope.ScopeName
+			if scope.ScopeType == "user" {
+				name += "(用户身份)"
+			}
+			if scope.GrantStatus == 1 {
+				granted = append(granted, name)
+			} else {
+				notGranted = append(notGranted, name)
+			}
+		}
+		fmt.Println("-----------应用权限-----------")
+		fmt.Println(fmt.Sprintf("%-12s: %s", "已授权权限", strings.Join(granted, "、")))
+		fmt.Println(fmt.Sprintf("%-12s: %s", "未授权权限", strings.Join(notGranted, "、")))
+	}
+	var deptScope []string
+	for deptId, deptName := range fsClientConfig.DepartmentScope {
+		var deptIdType = "ID"
+		if departmentIdTypeMap[departmentIdTypeCache] == "open_department_id" {
+			deptIdType = "OPEN_ID"
+		}
+		if deptName != "" {
+			deptScope = append(deptScope, fmt.Sprintf("%s(%s:%s)", deptName, deptIdType, deptId))
+		} else {
+			deptScope = append(deptScope, fmt.Sprintf("%s:%s", deptIdType, deptId))
+		}
+
+	}
+	fmt.Println("-----------获取通讯录授权范围-----------")
+	logger.Notice("该接口用于获取应用被授权可访问的通讯录范围，包括可访问的部门列表、用户列表和用户组列表。授权范围为全员时，" +
+		"返回的部门列表为该企业所有的一级部门；否则返回的部门为管理员在设置授权范围时勾选的部门（不包含勾选部门的子部门）")
+	fmt.Println(fmt.Sprintf("%-13s: %s", "部门范围", strings.Join(deptScope, "、")))
+	var groupScope []string
+	for groupId, groupName := range fsClientConfig.GroupScope {
+		if groupName != "" {
+			groupScope = append(groupScope, fmt.Sprintf("%s(ID:%s)", groupName, groupId))
+		} else {
+			groupScope = append(groupScope, fmt.Sprintf("ID:%s", groupId))
+		}
+	}
+	fmt.Println(fmt.Sprintf("%-12s: %s", "用户组范围", strings.Join(groupScope, "、")))
+	var userScope []string
+	for id, userName := range fsClientConfig.UserScope {
+		var userIdType = strings.ToUpper(userIdTypeMap[userIdTypeCache])
+		if userName != "" {
+			userScope = append(userScope, fmt.Sprintf("%s(%s:%s)", userName, userIdType, id))
+		} else {
+			userScope = append(userScope, fmt.Sprintf("%s:%s", userIdType, id))
+		}
+
+	}
+	fmt.Println(fmt.Sprintf("%-13s: %s", "用户范围", strings.Join(userScope, "、")))
+	fmt.Printf("%s\n", strings.Repeat("=", 40))
+}
+
+// fetchDepartment 并发获取部门的所有下级部门及其主管和直属用户,挂载至node,checkpoint为nil时不使用断点
+func (cli *feiShuCli) fetchDepartment(node *FeiShuDepartmentNode, deptId, deptIdType, userIdType string, workers int, checkpoint *dumpCheckpoint) error {
+	return newDepartmentCrawler(cli, deptIdType, userIdType, workers, checkpoint).run(node, deptId)
+}
+
+// fetchDumpRoot 获取导出的起始部门及其主管和直属用户,断点中已获取时直接使用
+func (cli *feiShuCli) fetchDumpRoot(deptId, deptIdType, userIdType string, checkpoint *dumpCheckpoint) (*FeiShuDepartmentNode, error) {
+	if rec, ok := checkpoint.root(deptId); ok {
+		deptNode := cli.newDepartmentNode(rec.Department, deptIdType, userIdType)
+		deptNode.LeaderUserName = rec.LeaderUserName
+		deptNode.User = append(deptNode.User, rec.Users...)
+		return deptNode, nil
+	}
+	logger.Info(fmt.Sprintf("正在获取部门[%s]的信息....", deptId))
+	//获取部门信息
+	req := fs.NewGetDepartmentReqBuilder(FeiShuClient).
+		DepartmentId(deptId).
+		DepartmentIdType(deptIdType).
+		UserIdType(userIdType).
+		Build()
+	deptInfo, err := FeiShuClient.Department.Get(req)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) && !interrupted() {
+			logger.Info(fmt.Sprintf("部门[%s]信息获取失败,终止获取", deptId))
+		}
+		return nil, err
+	}
+	indexFeiShuDepartment(&deptInfo)
+	deptNode := cli.newDepartmentNode(&deptInfo, deptIdType, userIdType)
+	//获取部门用户
+	logger.Info(fmt.Sprintf("正在获取部门[%s]直属用户列表...", deptId))
+	req1 := fs.NewGetUsersByDepartmentIdReqBuilder(FeiShuClient).
+		DepartmentId(deptId).
+		DepartmentIdType(deptIdType).UserIdType(userIdType).Build()
+	users, err := FeiShuClient.User.GetUsersByDepartmentId(req1)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) && !interrupted() {
+			logger.Info(fmt.Sprintf("部门[%s]直属用户列表获取失败,终止获取", deptId))
+		}
+		return nil, err
+	}
+	indexFeiShuUsers(users)
+	deptNode.User = append(deptNode.User, users...)
+	checkpoint.putRoot(deptId, &crawlRecord{Department: &deptInfo, LeaderUserName: deptNode.LeaderUserName, Users: users})
+	return deptNode, nil
+}
+
+// indexCheckpoint 将断点中已获取的部门、用户和主管姓名写入索引,继续导出时创建部门节点无需重新获取主管姓名
+func (cli *feiShuCli) indexCheckpoint(checkpoint *dumpCheckpoint) {
+	index := func(dept *fs.DepartmentEntry, rec *crawlRecord) {
+		indexFeiShuDepartment(dept)
+		if rec != nil && rec.LeaderUserName != "" {
+			feiShuDirectory().AddUser(rec.LeaderUserName, dept.LeaderUserID)
+		}
+	}
+	for _, rec := range checkpoint.Roots {
+		indexFeiShuUsers(rec.Users)
+		if rec.Department != nil {
+			index(rec.Department, rec)
+		}
+	}
+	for _, rec := range checkpoint.Visited {
+		indexFeiShuUsers(rec.Users)
+		for _, child := range rec.Children {
+			index(child, checkpoint.Visited[cli.departmentIdOf(child, checkpoint.DeptIdType)])
+		}
+	}
+}
+
+// feiShuDirectory 返回当前应用的部门和用户名称索引
+func feiShuDirectory() *directoryIndex {
+	return directoryFor(plugin.PlatformFeishu, valueOf(FeiShuClient.GetAuthScopeFromCache().AppId))
+}
+
+func indexFeiShuDepartment(dept *fs.DepartmentEntry) {
+	feiShuDirectory().AddDepartment(dept.Name, dept.DepartmentID, dept.OpenDepartmentID)
+}
+
+func indexFeiShuUsers(users []*fs.UserEntry) {
+	dir := feiShuDirectory()
+	for _, user := range users {
+		dir.AddUser(user.Name, user.UserId, user.OpenId, user.UnionId)
+	}
+}
+
+// feiShuUserName 优先从索引获取用户姓名,不存在时获取用户详情并保存至索引
+func feiShuUserName(userId, userIdType, deptIdType string) (string, error) {
+	if name, ok := feiShuDirectory().User(userId); ok {
+		return name, nil
+	}
+	req := fs.NewGetUserReqBuilder(FeiShuClient).
+		UserId(userId).
+		UserIdType(userIdType).
+		DepartmentIdType(deptIdType).
+		Build()
+	userInfo, err := FeiShuClient.User.Get(req)
+	if err != nil {
+		return "", err
+	}
+	indexFeiShuUsers([]*fs.UserEntry{userInfo})
+	return userInfo.Name, nil
+}
+
+// feiShuDepartmentName 优先从索引获取部门名称,不存在时获取部门详情并保存至索引
+func feiShuDepartmentName(deptId, deptIdType, userIdType string) (string, error) {
+	if name, ok := feiShuDirectory().Department(deptId); ok {
+		return name, nil
+	}
+	req := fs.NewGetDepartmentReqBuilder(FeiShuClient).
+		DepartmentId(deptId).
+		DepartmentIdType(deptIdType).
+		UserIdType(userIdType).
+		Build()
+	deptInfo, err := FeiShuClient.Department.Get(req)
+	if err != nil {
+		return "", err
+	}
+	feiShuDirectory().AddDepartment(deptInfo.Name, deptId, deptInfo.DepartmentID, deptInfo.OpenDepartmentID)
+	return deptInfo.Name, nil
+}
+
+// refreshDirectory 并发获取通讯录授权范围内的部门和用户填充索引,部门ID类型与授权范围一致
+func (cli *feiShuCli) refreshDirectory(dir *directoryIndex) error {
+	if !cli.hasTenantAccessToken() {
+		return fmt.Errorf("请先执行run获取tenant_access_token")
+	}
+	deptIds := sortedKeys(FeiShuClient.GetAuthScopeFromCache().DepartmentScope)
+	if len(deptIds) == 0 {
+		return fmt.Errorf("无可用部门信息")
+	}
+	didType := departmentIdTypeMap[departmentIdTypeCache]
+	uidType := userIdTypeMap[userIdTypeCache]
+	for _, deptId := range deptIds {
+		if HttpCanceled {
+			return nil
+		}
+		logger.Info(fmt.Sprintf("正在获取部门[%s]的部门和用户...", deptId))
+		if _, err := feiShuDepartmentName(deptId, didType, uidType); err != nil {
+			return err
+		}
+		req := fs.NewGetUsersByDepartmentIdReqBuilder(FeiShuClient).
+			DepartmentId(deptId).
+			DepartmentIdType(didType).UserIdType(uidType).Build()
+		users, err := FeiShuClient.User.GetUsersByDepartmentId(req)
+		if err != nil {
+			return err
+		}
+		indexFeiShuUsers(users)
+		if err = cli.fetchDepartment(&FeiShuDepartmentNode{}, deptId, didType, uidType, defaultWorkers, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchDepartmentTree 获取部门及其所有子部门并构建部门树,不获取部门用户
+func (cli *feiShuCli) fetchDepartmentTree(deptId, deptIdType, userIdType string) (*FeiShuDepartmentNode, error) {
+	req := fs.NewGetDepartmentReqBuilder(FeiShuClient).
+		DepartmentId(deptId).
+		DepartmentIdType(deptIdType).
+		UserIdType(userIdType).
+		Build()
+	deptInfo, err := FeiShuClient.Department.Get(req)
+	if err != nil {
+		return nil, err
+	}
+	req1 := fs.NewGetDepartmentChildrenReqBuilder(FeiShuClient).
+		DepartmentId(deptId).
+		DepartmentIdType(deptIdType).
+		UserIdType(userIdType).
+		Fetch(true).
+		PageSize(50).
+		Build()
+	deptChildren, err := FeiShuClient.Department.Children(req1)
+	if err != nil {
+		return nil, err
+	}
+	// 根部门"0"获取详情时返回的ID为空
+	if deptInfo.DepartmentID == "" && deptInfo.OpenDepartmentID == "" {
+		deptInfo.DepartmentID = deptId
+		deptInfo.OpenDepartmentID = deptId
+	}
+	indexFeiShuDepartment(&deptInfo)
+	root := cli.newDepartmentNode(&deptInfo, deptIdType, userIdType)
+	nodeMap := map[string]*FeiShuDepartmentNode{deptId: root}
+	var nodes []*FeiShuDepartmentNode
+	for _, child := range deptChildren {
+		if HttpCanceled {
+			return root, nil
+		}
+		indexFeiShuDepartment(child)
+		node := cli.newDepartmentNode(child, deptIdType, userIdType)
+		nodeMap[cli.departmentIdOf(child, deptIdType)] = node
+		nodes = append(nodes, node)
+	}
+	// 按接口返回顺序挂载到上级部门
+	for i, child := range deptChildren {
+		node := nodes[i]
+		if parent, ok := nodeMap[child.ParentDepartmentID]; ok {
+			node.ParentDepartmentName = parent.Name
+			parent.Children = append(parent.Children, node)
+		} else {
+			root.Children = append(root.Children, node)
+		}
+	}
+	return root, nil
+}
+
+// newDepartmentNode 根据部门详情生成不含用户的部门节点,主管用户姓名优先从索引获取
+func (cli *feiShuCli) newDepartmentNode(deptInfo *fs.DepartmentEntry, deptIdType, userIdType string) *FeiShuDepartmentNode {
+	deptNode := &FeiShuDepartmentNode{
+		Name:               deptInfo.Name,
+		ZhCnName:           deptInfo.I18NName.ZhCn,
+		JaJpName:           deptInfo.I18NName.JaJp,
+		EnUsName:           deptInfo.I18NName.EnUs,
+		DepartmentID:       deptInfo.DepartmentID,
+		OpenDepartmentID:   deptInfo.OpenDepartmentID,
+		ParentDepartmentID: deptInfo.ParentDepartmentID,
+		LeaderUserID:       deptInfo.LeaderUserID,
+		ChatID:             deptInfo.ChatID,
+		MemberCount:        deptInfo.MemberCount,
+		PrimaryMemberCount: deptInfo.PrimaryMemberCount,
+		UnitIds:            deptInfo.UnitIds,
+		DepartmentHrbps:    deptInfo.DepartmentHrbps,
+		User:               []*fs.UserEntry{},
+		Children:           []*FeiShuDepartmentNode{},
+	}
+	if deptInfo.Status.IsDeleted {
+		deptNode.Status = "已删除"
+	} else {
+		deptNode.Status = "正常"
+	}
+	if deptInfo.LeaderUserID != "" {
+		deptNode.LeaderUserName, _ = feiShuUserName(deptInfo.LeaderUserID, userIdType, deptIdType)
+	}
+	return deptNode
+}
+
+// departmentIdOf 根据部门ID类型返回对应的部门ID
+func (cli *feiShuCli) departmentIdOf(dept *fs.DepartmentEntry, deptIdType string) string {
+	if deptIdType == "department_id" {
+		return dept.DepartmentID
+	}
+	return dept.OpenDepartmentID
+}
+
+// printDepartmentTree 打印部门树
+func (cli *feiShuCli) printDepartmentTree(nodes []*FeiShuDepartmentNode, level int) {
+	for _, dept := range nodes {
+		var leader string
+		if dept.LeaderUserName != "" {
+			leader = fmt.Sprintf(" 主管用户[%s(%s)]", dept.LeaderUserName, dept.LeaderUserID)
+		} else if dept.LeaderUserID != "" {
+			leader = fmt.Sprintf(" 主管用户[%s]", dept.LeaderUserID)
+		}
+		fmt.Printf("%s  -名称[%s] 状态[%s] 部门ID[%s] OPEN_ID[%s]%s 用户个数[%d] 主属用户个数[%d]\n",
+			strings.Repeat(" ", 3*level), dept.Name, dept.Status, dept.DepartmentID, dept.OpenDepartmentID, leader,
+			dept.MemberCount, dept.PrimaryMemberCount)
+		cli.printDepartmentTree(dept.Children, level+1)
+	}
+}
+
+func (cli *feiShuCli) setHelpV1(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		// 不自己打印会多一个空白行
+		cmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+			logger.Error(err)
+			return nil //返回error会自动打印用法
+		})
+
+		cmd.SilenceErrors = true      // 禁止打印错误信息
+		cmd.DisableSuggestions = true // 禁用帮助信息
+		cmd.SilenceUsage = true       // 禁用用法信息
+		cmd.Flags().SortFlags = false
+
+		// 设置自定义的使用帮助函数
+		cmd.SetHelpFunc(func(cmd *cobra.Command, s []string) {
+			fmt.Print(feishuUsage)
+		})
+		cmd.SetUsageFunc(func(cmd *cobra.Command) error {
+			fmt.Print(feishuUsage)
+			return nil
+		})
+	}
+}
+
+type FeiShuDepartmentNode struct {
+	Name                 string // 部门名称
+	ZhCnName             string // 部门的中文名
+	JaJpName             string // 部门的日文名
+	EnUsName             string // 部门的英文名
+	DepartmentID         string // 部门ID
+	OpenDepartmentID     string // 部门open_department_id
+	ParentDepartmentID   string // 上级部门ID
+	ParentDepartmentName string // 上级部门名称
+	Status               string // 部门状态
+	LeaderUserID         string // 主管领导ID
+	LeaderUserName       string // 主管领导姓名
+	ChatID               string // 部门群ID
+	MemberCount          int    // 部门下用户的个数
+	PrimaryMemberCount   int    // 部门下主属用户的个数
+	UnitIds              []*string
+	DepartmentHrbps      []*string
+	User                 []*fs.UserEntry
+	Children             []*FeiShuDepartmentNode
+}
+
+type colItem struct {
+	Name                 string // 部门名称
+	ZhCnName             string // 部门的中文名
+	JaJpName             string // 部门的日文名
+	EnUsName             string // 部门的英文名
+	DepartmentID         string // 部门ID
+	OpenDepartmentID     string // 部门open_department_id
+	ParentDepartmentID   string // 上级部门ID
+	ParentDepartmentName string // 上级部门名称
+	Status               string // 部门状态
+	LeaderUserID         string // 主管领导ID
+	LeaderUserName       string // 主管领导姓名
+	DepartmentHrbps      []*string
+	User                 fs.UserEntry
+}
+
+func (cli *feiShuCli) fetchColItem(tree []*FeiShuDepartmentNode) []*colItem {
+	var items []*colItem
+	for _, node := range tree {
+		for _, user := range node.User {
+			var item = &colItem{
+				Name:                 node.Name,
+				ZhCnName:             node.ZhCnName,
+				JaJpName:             node.JaJpName,
+				EnUsName:             node.EnUsName,
+				DepartmentID:         node.DepartmentID,
+				OpenDepartmentID:     node.OpenDepartmentID,
+				ParentDepartmentID:   node.ParentDepartmentID,
+				ParentDepartmentName: node.ParentDepartmentName,
+				Status:               node.Status,
+				LeaderUserID:         node.LeaderUserID,
+				LeaderUserName:       node.LeaderUserName,
+				DepartmentHrbps:      node.DepartmentHrbps,
+				User:                 *user,
+			}
+			items = append(items, item)
+		}
+		items = append(items, cli.fetchColItem(node.Children)...)
+	}
+	return items
+}
+
+// saveDepartmentWithUserToExcel 生成包含所属部门ID的用户信息的XLSX文档,保存文件为空则自动生成
+func (cli *feiShuCli) saveDepartmentWithUserToExcel(tree []*FeiShuDepartmentNode, filename string) (string, error) {
+	index := strings.LastIndex(filename, ".xlsx")
+	if index == -1 {
+		filename = filename + ".xlsx"
+	}
+	tmp := filename
+	// 判断文件是否存在
+	if utils.IsFileExists(filename) {
+		newFilename := generateNewFilename(filename)
+		filename = newFilename
+	}
+	var items []*colItem
+	items = append(items, cli.fetchColItem(tree)...)
+	// 设置表头
+	attrs := cli.getCustomAttrs()
+	headers := append([]any{"id"}, cli.colItemHeaders(attrs)...)
+
+	var data [][]any
+	// 写入内容
+	for i, item := range items {
+		data = append(data, append([]any{i + 1}, cli.colItemRow(item, attrs)...))
+	}
+
+	// 保存文件
+	err := saveToExcel(headers, data, filename)
+	if err != nil {
+		return "", errors.New("保存 Excel 文件失败: " + err.Error())
+	}
+	if tmp != filename {
+		return fmt.Sprintf("%s 已存在,已另存为 %s", tmp, filename), nil
+	}
+	return fmt.Sprintf("文件已保存至 %s", filename), nil
+}
+
+// bitableKeyField 多维表格中用于关联记录的索引列
+const bitableKeyField = "同步键"
+
+// bitableSyncEntry 多维表格同步结果
+type bitableSyncEntry struct {
+	Action         string   `json:"action"`                   // 新增、更新、未变化、已移除
+	Key            string   `json:"key"`                      // 同步键
+	Name           string   `json:"name"`                     // 用户姓名
+	DepartmentName string   `json:"department_name"`          // 部门名称
+	ChangedFields  []string `json:"changed_fields,omitempty"` // 更新的字段
+}
+
+// syncToBitable 将部门用户按同步键新增或者更新至多维表格,数据表不存在时自动创建,缺少的字段自动补充,返回与上次同步相比的变化
+func (cli *feiShuCli) syncToBitable(tree []*FeiShuDepartmentNode, appToken, tableName string) ([]*bitableSyncEntry, error) {
+	attrs := cli.getCustomAttrs()
+	fieldNames := []string{bitableKeyField}
+	for _, header := range cli.colItemHeaders(attrs) {
+		fieldNames = append(fieldNames, fmt.Sprint(header))
+	}
+	// 获取或者创建数据表
+	tables, err := FeiShuClient.Bitable.ListTables(fs.NewBitableReqBuilder(FeiShuClient).AppToken(appToken).PageSize(100).Build())
+	if err != nil {
+		return nil, err
+	}
+	var tableId string
+	for _, table := range tables {
+		if table.Name == tableName {
+			tableId = table.TableId
+			break
+		}
+	}
+	existing := map[string]*fs.BitableRecordEntry{}
+	if tableId == "" {
+		logger.Info(fmt.Sprintf("数据表[%s]不存在,正在创建...", tableName))
+		req := fs.NewBitableReqBuilder(FeiShuClient).AppToken(appToken).TableName(tableName).Fields(fieldNames).Build()
+		tableId, err = FeiShuClient.Bitable.CreateTable(req)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		fields, err := FeiShuClient.Bitable.ListFields(fs.NewBitableReqBuilder(FeiShuClient).AppToken(appToken).TableId(tableId).PageSize(100).Build())
+		if err != nil {
+			return nil, err
+		}
+		fieldSet := map[string]bool{}
+		for _, field := range fields {
+			fieldSet[field.FieldName] = true
+		}
+		for _, name := range fieldNames {
+			if fieldSet[name] {
+				continue
+			}
+			logger.Info(fmt.Sprintf("正在新增字段[%s]...", name))
+			req := fs.NewBitableReqBuilder(FeiShuClient).AppToken(appToken).TableId(tableId).FieldName(name).Build()
+			if _, err = FeiShuClient.Bitable.CreateField(req); err != nil {
+				return nil, err
+			}
+		}
+		logger.Info(fmt.Sprintf("正在获取数据表[%s]已有记录...", tableName))
+		records, err := FeiShuClient.Bitable.ListRecords(fs.NewBitableReqBuilder(FeiShuClient).AppToken(appToken).TableId(tableId).PageSize(fs.BitableMaxBatchSize).Build())
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if key := record.Text(bitableKeyField); key != "" {
+				existing[key] = record
+			}
+		}
+	}
+	// 与已有记录比较
+	var creates, updates []*fs.BitableRecordEntry
+	var result []*bitableSyncEntry
+	seen := map[string]bool{}
+	for _, item := range cli.fetchColItem(tree) {
+		key := item.OpenDepartmentID + "|" + item.User.OpenId
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		fields := map[string]any{bitableKeyField: key}
+		for i, value := range cli.colItemRow(item, attrs) {
+			fields[fieldNames[i+1]] = fmt.Sprint(value)
+		}
+		entry := &bitableSyncEntry{Key: key, Name: item.User.Name, DepartmentName: item.Name}
+		result = append(result, entry)
+		record, ok := existing[key]
+		if !ok {
+			entry.Action = "新增"
+			creates = append(creates, &fs.BitableRecordEntry{Fields: fields})
+			continue
+		}
+		changed := map[string]any{}
+		for _, name := range fieldNames[1:] {
+			if record.Text(name) != fields[name] {
+				changed[name] = fields[name]
+				entry.ChangedFields = append(entry.ChangedFields, name)
+			}
+		}
+		if len(changed) == 0 {
+			entry.Action = "未变化"
+			continue
+		}
+		entry.Action = "更新"
+		updates = append(updates, &fs.BitableRecordEntry{RecordId: record.RecordId, Fields: changed})
+	}
+	// 已有记录不在本次导出中的仅报告,不删除
+	var removed []string
+	for key := range existing {
+		if !seen[key] {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+	for _, key := range removed {
+		record := existing[key]
+		result = append(result, &bitableSyncEntry{Action: "已移除", Key: key, Name: record.Text("姓名"), DepartmentName: record.Text("部门名称")})
+	}
+	// 分批写入
+	for start := 0; start < len(creates); start += fs.BitableMaxBatchSize {
+		end := start + fs.BitableMaxBatchSize
+		if end > len(creates) {
+			end = len(creates)
+		}
+		logger.Info(fmt.Sprintf("正在新增第%d-%d条记录...", start+1, end))
+		req := fs.NewBitableReqBuilder(FeiShuClient).AppToken(appToken).TableId(tableId).Records(creates[start:end]).Build()
+		if _, err = FeiShuClient.Bitable.BatchCreateRecords(req); err != nil {
+			return result, err
+		}
+	}
+	for start := 0; start < len(updates); start += fs.BitableMaxBatchSize {
+		end := start + fs.BitableMaxBatchSize
+		if end > len(updates) {
+			end = len(updates)
+		}
+		logger.Info(fmt.Sprintf("正在更新第%d-%d条记录...", start+1, end))
+		req := fs.NewBitableReqBuilder(FeiShuClient).AppToken(appToken).TableId(tableId).Records(updates[start:end]).Build()
+		if _, err = FeiShuClient.Bitable.BatchUpdateRecords(req); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// saveBitableSyncReport 打印同步结果统计并导出同步报告
+func (cli *feiShuCli) saveBitableSyncReport(result []*bitableSyncEntry) {
+	counts := map[string]int{}
+	for _, entry := range result {
+		counts[entry.Action]++
+	}
+	logger.Info(fmt.Sprintf("新增%d条,更新%d条,未变化%d条,已不在本次导出%d条(未删除)", counts["新增"], counts["更新"],
+		counts["未变化"], counts["已移除"]))
+	saveExport(result, "feishu_bitable_sync", func() ([]any, [][]any) {
+		headers := []any{"id", "操作", "同步键", "姓名", "部门名称", "变更字段"}
+		var data [][]any
+		for i, entry := range result {
+			data = append(data, []any{i + 1, entry.Action, entry.Key, entry.Name, entry.DepartmentName,
+				strings.Join(entry.ChangedFields, "、")})
+		}
+		return headers, data
+	})
+}
+
+// colItemHeaders 部门用户的表头,与colItemRow一一对应
+func (cli *feiShuCli) colItemHeaders(attrs []*fs.CustomAttrEntry) []any {
+	headers := []any{"部门名称", "部门中文名称", "部门日文名称", "部门英文名称", "部门ID", "部门OPEN_ID", "上级部门ID", "上级部门名称", "部门状态", "部门主管ID", "部门主管姓名", "Hrbps"}
+	return append(headers, cli.userExcelHeaders(attrs)...)
+}
+
+// colItemRow 部门用户的行数据
+func (cli *feiShuCli) colItemRow(item *colItem, attrs []*fs.CustomAttrEntry) []any {
+	var hrbps []string
+	for _, hrbp := range item.DepartmentHrbps {
+		hrbps = append(hrbps, *hrbp)
+	}
+	row := []any{
+		item.Name,                 // 部门名称
+		item.ZhCnName,             // 部门的中文名
+		item.JaJpName,             // 部门的日文名
+		item.EnUsName,             // 部门的英文名
+		item.DepartmentID,         // 部门ID
+		item.OpenDepartmentID,     // 部门open_department_id
+		item.ParentDepartmentID,   // 上级部门ID
+		item.ParentDepartmentName, // 上级部门名称
+		item.Status,               // 部门状态
+		item.LeaderUserID,         // 主管领导ID
+		item.LeaderUserName,       // 主管领导姓名
+		strings.Join(hrbps, "、"),
+	}
+	return append(row, cli.userExcelRow(&item.User, attrs)...)
+}
+
+// saveUserToExcel 生成包含所属部门ID的用户信息的XLSX文档
+func (cli *feiShuCli) saveUserToExcel(users []*fs.UserEntry, filename string) (string, error) {
+	index := strings.LastIndex(filename, ".xlsx")
+	if index == -1 {
+		filename = filename + ".xlsx"
+	}
+	tmp := filename
+	// 判断文件是否存在
+	if utils.IsFileExists(filename) {
+		newFilename := generateNewFilename(filename)
+		filename = newFilename
+	}
+
+	// 设置表头
+	attrs := cli.getCustomAttrs()
+	headers := append([]any{"id"}, cli.userExcelHeaders(attrs)...)
+
+	var data [][]any
+	// 写入内容
+	for i, user := range users {
+		data = append(data, append([]any{i + 1}, cli.userExcelRow(user, attrs)...))
+	}
+	// 保存文件
+	err := saveToExcel(headers, data, filename)
+	if err != nil {
+		return "", errors.New("保存 Excel 文件失败: " + err.Error())
+	}
+	if tmp != filename {
+		return fmt.Sprintf("%s 已存在,已另存为 %s", tmp, filename), nil
+	}
+	return fmt.Sprintf("文件已保存至 %s", filename), nil
+}
+
+// userExcelHeaders 用户信息的XLSX表头,自定义字段使用字段名称作为表头
+func (cli *feiShuCli) userExcelHeaders(attrs []*fs.CustomAttrEntry) []any {
+	headers := []any{"用户ID", "用户OPEN_ID", "用户UNION_ID", "姓名", "英文姓名", "昵称", "性别", "电话号码", "邮箱", "企业邮箱",
+		"用户状态", "用户所属部门", "主部门", "工作地点", "加入时间", "工号", "人员类型", "是否企业管理员", "职称", "岗位", "直属上级", "虚线上级",
+		"个性签名", "头像"}
+	for _, attr := range attrs {
+		headers = append(headers, attr.Name())
+	}
+	return headers
+}
+
+// userExcelRow 用户信息的XLSX行数据,与userExcelHeaders一一对应
+func (cli *feiShuCli) userExcelRow(user *fs.UserEntry, attrs []*fs.CustomAttrEntry) []any {
+	var isAdmin = "否"
+	if user.IsTenantManager {
+		isAdmin = "是"
+	}
+	joinTime := time.Unix(int64(user.JoinTime), 0)
+	row := []any{user.UserId, user.OpenId, user.UnionId, user.Name, user.EnName, user.Nickname, fs.GenderName(user.Gender), user.Mobile,
+		user.Email, user.EnterpriseEmail, strings.Join(userStatusOf(user), "、"), strings.Join(user.DepartmentIds, "、"),
+		primaryDepartmentOf(user), workLocationOf(user), joinTime.String(), user.EmployeeNo,
+		fs.EmployeeTypeName(user.EmployeeType, cli.getEmployeeTypeNames()), isAdmin, user.JobTitle, strings.Join(positionsOf(user), "、"),
+		user.LeaderUserId, strings.Join(user.DottedLineLeaderUserIds, "、"), user.Description, user.Avatar.AvatarOrigin}
+	for _, attr := range attrs {
+		row = append(row, customAttrValueOf(user, attr))
+	}
+	return row
+}
+
+// getCustomAttrs 获取企业自定义用户字段,同一命令内只获取一次,获取失败时不导出自定义字段
+func (cli *feiShuCli) getCustomAttrs() []*fs.CustomAttrEntry {
+	if cli.customAttrsFetched {
+		return cli.customAttrs
+	}
+	cli.customAttrsFetched = true
+	req := fs.NewListCustomAttrReqBuilder(FeiShuClient).PageSize(100).Build()
+	attrs, err := FeiShuClient.CustomAttr.List(req)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			logger.Warning("获取企业自定义用户字段失败: " + err.Error())
 		}
+		return nil
 	}
-	return nil
+	cli.customAttrs = attrs
+	return attrs
 }
 
-func (cli *feiShuCli) setHelpV1(cmds ...*cobra.Command) {
-	for _, cmd := range cmds {
-		// 不自己打印会多一个空白行
-		cmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
-			logger.Error(err)
-			return nil //返回error会自动打印用法
-		})
+// getEmployeeTypeNames 获取租户的人员类型,同一命令内只获取一次,获取失败时仅解析内置人员类型
+func (cli *feiShuCli) getEmployeeTypeNames() map[int]string {
+	if cli.employeeTypeNamesFetched {
+		return cli.employeeTypeNames
+	}
+	cli.employeeTypeNamesFetched = true
+	names, err := FeiShuClient.GetEmployeeTypeNames()
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			logger.Warning("获取人员类型失败,仅解析内置人员类型: " + err.Error())
+		}
+		return nil
+	}
+	cli.employeeTypeNames = names
+	return names
+}
 
-		cmd.SilenceErrors = true      // 禁止打印错误信息
-		cmd.DisableSuggestions = true // 禁用帮助信息
-		cmd.SilenceUsage = true       // 禁用用法信息
-		cmd.Flags().SortFlags = false
+// workLocationOf 返回用户的工作地点,国家或地区和城市解析为中文名称
+func workLocationOf(user *fs.UserEntry) string {
+	var location []string
+	if user.Country != "" {
+		location = append(location, fs.CountryName(user.Country))
+	}
+	if user.City != "" {
+		location = append(location, fs.CityName(user.City))
+	}
+	return strings.Join(location, " ")
+}
 
-		// 设置自定义的使用帮助函数
-		cmd.SetHelpFunc(func(cmd *cobra.Command, s []string) {
-			fmt.Print(feishuUsage)
-		})
-		cmd.SetUsageFunc(func(cmd *cobra.Command) error {
-			fmt.Print(feishuUsage)
-			return nil
-		})
+// userStatusOf 返回用户状态描述
+func userStatusOf(user *fs.UserEntry) []string {
+	var userStat []string
+	if user.Status.IsUnjoin {
+		userStat = append(userStat, "未加入")
+	}
+	if user.Status.IsResigned {
+		userStat = append(userStat, "已离职")
+	}
+	if user.Status.IsActivated {
+		userStat = append(userStat, "已激活")
+	}
+	if user.Status.IsExited {
+		userStat = append(userStat, "已退出")
 	}
+	if user.Status.IsFrozen {
+		userStat = append(userStat, "已冻结")
+	}
+	return userStat
 }
 
-type FeiShuDepartmentNode struct {
-	Name                 string // 部门名称
-	ZhCnName             string // 部门的中文名
-	JaJpName             string // 部门的日文名
-	EnUsName             string // 部门的英文名
-	DepartmentID         string // 部门ID
-	OpenDepartmentID     string // 部门open_department_id
-	ParentDepartmentID   string // 上级部门ID
-	ParentDepartmentName string // 上级部门名称
-	Status               string // 部门状态
-	LeaderUserID         string // 主管领导ID
-	LeaderUserName       string // 主管领导姓名
-	ChatID               string // 部门群ID
-	UnitIds              []*string
-	DepartmentHrbps      []*string
-	User                 []*fs.UserEntry
-	Children             []*FeiShuDepartmentNode
+// primaryDepartmentOf 根据排序信息返回用户主部门ID
+func primaryDepartmentOf(user *fs.UserEntry) string {
+	for _, order := range user.Orders {
+		if order.IsPrimaryDept {
+			return order.DepartmentId
+		}
+	}
+	return ""
 }
 
-type colItem struct {
-	Name                 string // 部门名称
-	ZhCnName             string // 部门的中文名
-	JaJpName             string // 部门的日文名
-	EnUsName             string // 部门的英文名
-	DepartmentID         string // 部门ID
-	OpenDepartmentID     string // 部门open_department_id
-	ParentDepartmentID   string // 上级部门ID
-	ParentDepartmentName string // 上级部门名称
-	Status               string // 部门状态
-	LeaderUserID         string // 主管领导ID
-	LeaderUserName       string // 主管领导姓名
-	DepartmentHrbps      []*string
-	User                 fs.UserEntry
+// positionsOf 返回用户岗位描述,主岗标记为(主)
+func positionsOf(user *fs.UserEntry) []string {
+	var positions []string
+	for _, position := range user.Positions {
+		name := position.PositionName
+		if name == "" {
+			name = position.PositionCode
+		}
+		if position.IsMajor {
+			name += "(主)"
+		}
+		positions = append(positions, name)
+	}
+	return positions
 }
 
-func (cli *feiShuCli) fetchColItem(tree []*FeiShuDepartmentNode) []*colItem {
-	var items []*colItem
-	for _, node := range tree {
-		for _, user := range node.User {
-			var item = &colItem{
-				Name:                 node.Name,
-				ZhCnName:             node.ZhCnName,
-				JaJpName:             node.JaJpName,
-				EnUsName:             node.EnUsName,
-				DepartmentID:         node.DepartmentID,
-				OpenDepartmentID:     node.OpenDepartmentID,
-				ParentDepartmentID:   node.ParentDepartmentID,
-				ParentDepartmentName: node.ParentDepartmentName,
-				Status:               node.Status,
-				LeaderUserID:         node.LeaderUserID,
-				LeaderUserName:       node.LeaderUserName,
-				DepartmentHrbps:      node.DepartmentHrbps,
-				User:                 *user,
+// customAttrValueOf 返回用户自定义字段的可读值,枚举类型解析为选项名称
+func customAttrValueOf(user *fs.UserEntry, attr *fs.CustomAttrEntry) string {
+	for _, value := range user.CustomAttrs {
+		if value.Id != attr.Id {
+			continue
+		}
+		switch value.Type {
+		case "HREF":
+			if value.Value.Text != "" {
+				return fmt.Sprintf("%s(%s)", value.Value.Text, value.Value.Url)
 			}
-			items = append(items, item)
+			return value.Value.Url
+		case "ENUMERATION", "PICTURE_ENUM":
+			if value.Value.Name != "" {
+				return value.Value.Name
+			}
+			return attr.OptionName(value.Value.OptionId)
+		case "GENERIC_USER":
+			return value.Value.GenericUser.Id
+		default:
+			return value.Value.Text
 		}
-		items = append(items, cli.fetchColItem(node.Children)...)
 	}
-	return items
+	return ""
 }
 
-// saveDepartmentWithUserToExcel 生成包含所属部门ID的用户信息的XLSX文档,保存文件为空则自动生成
-func (cli *feiShuCli) saveDepartmentWithUserToExcel(tree []*FeiShuDepartmentNode, filename string) (string, error) {
-	index := strings.LastIndex(filename, ".xlsx")
-	if index == -1 {
+// userIdOf 根据ID类型返回用户的对应ID
+func userIdOf(user *fs.UserEntry, idType string) string {
+	switch idType {
+	case "id":
+		return user.UserId
+	case "unionid":
+		return user.UnionId
+	default:
+		return user.OpenId
+	}
+}
+
+func (cli *feiShuCli) saveUserIdToExcel(entries []*fs.UserIdEntry, uidType, filename string) (string, error) {
+	if !strings.HasSuffix(filename, ".xlsx") {
 		filename = filename + ".xlsx"
 	}
 	tmp := filename
@@ -1142,122 +3731,67 @@ func (cli *feiShuCli) saveDepartmentWithUserToExcel(tree []*FeiShuDepartmentNode
 		newFilename := generateNewFilename(filename)
 		filename = newFilename
 	}
-	var items []*colItem
-	items = append(items, cli.fetchColItem(tree)...)
+
 	// 设置表头
-	headers := []any{"id", "部门名称", "部门中文名称", "部门日文名称", "部门英文名称", "部门ID", "部门OPEN_ID", "上级部门ID", "上级部门名称", "部门状态", "部门主管ID", "部门主管姓名", "Hrbps", "用户ID", "用户OPEN_ID",
-		"姓名", "英文姓名", "昵称", "性别", "电话号码", "邮箱", "企业邮箱", "用户状态", "用户所属部门", "工作地点", "加入时间", "工号", "是否企业管理员", "职称"}
+	headers := []any{"id", "手机号", "邮箱", uidType, "用户状态"}
 
 	var data [][]any
-	// 写入内容
-	for i, item := range items {
-		var gender string
-		user := item.User
-		if user.Gender == 0 {
-			gender = "保密"
-		} else if user.Gender == 1 {
-			gender = "男"
-		} else if user.Gender == 2 {
-			gender = "女"
-		}
-		var hrbps []string
-		for _, hrbp := range item.DepartmentHrbps {
-			hrbps = append(hrbps, *hrbp)
-		}
+	for i, entry := range entries {
 		var userStat []string
-		if user.Status.IsUnjoin {
+		if entry.UserId == "" {
+			userStat = append(userStat, "未找到")
+		}
+		if entry.Status.IsUnjoin {
 			userStat = append(userStat, "未加入")
 		}
-		if user.Status.IsResigned {
+		if entry.Status.IsResigned {
 			userStat = append(userStat, "已离职")
 		}
-		if user.Status.IsActivated {
+		if entry.Status.IsActivated {
 			userStat = append(userStat, "已激活")
 		}
-		if user.Status.IsExited {
+		if entry.Status.IsExited {
 			userStat = append(userStat, "已退出")
 		}
-		if user.Status.IsFrozen {
+		if entry.Status.IsFrozen {
 			userStat = append(userStat, "已冻结")
 		}
-		var workLocation string
-		if user.Country != "" {
-			workLocation += user.Country
-		}
-		if user.City != "" {
-			workLocation += user.City
-		}
-		var isAdmin string
-		if user.IsTenantManager {
-			isAdmin = "是"
-		}
-		if !user.IsTenantManager {
-			isAdmin = "否"
+		data = append(data, []any{i + 1, entry.Mobile, entry.Email, entry.UserId, strings.Join(userStat, "、")})
+	}
+
+	// 保存文件
+	err := saveToExcel(headers, data, filename)
+	if err != nil {
+		return "", errors.New("保存 Excel 文件失败: " + err.Error())
+	}
+	if tmp != filename {
+		return fmt.Sprintf("%s 已存在,已另存为 %s", tmp, filename), nil
+	}
+	return fmt.Sprintf("文件已保存至 %s", filename), nil
+}
+
+// saveIdMappingToExcel 保存用户ID对照表,users与ids一一对应,获取失败的用户为nil
+func (cli *feiShuCli) saveIdMappingToExcel(ids []string, users []*fs.UserEntry, filename string) (string, error) {
+	if !strings.HasSuffix(filename, ".xlsx") {
+		filename = filename + ".xlsx"
+	}
+	tmp := filename
+	// 判断文件是否存在
+	if utils.IsFileExists(filename) {
+		newFilename := generateNewFilename(filename)
+		filename = newFilename
+	}
+
+	// 设置表头
+	headers := []any{"id", "输入ID", "姓名", "工号", "user_id", "open_id", "union_id", "备注"}
+
+	var data [][]any
+	for i, user := range users {
+		if user == nil {
+			data = append(data, []any{i + 1, ids[i], "", "", "", "", "", "获取失败"})
+			continue
 		}
-		timestamp := int64(user.JoinTime)   // 已知的时间戳（以秒为单位）
-		joinTime := time.Unix(timestamp, 0) // 使用time.Unix()函数将时间戳转换为本地时间
-		var (
-			deptName             = item.Name                 // 部门名称
-			zhCnName             = item.ZhCnName             // 部门的中文名
-			jaJpName             = item.JaJpName             // 部门的日文名
-			enUsName             = item.EnUsName             // 部门的英文名
-			departmentID         = item.DepartmentID         // 部门ID
-			openDepartmentID     = item.OpenDepartmentID     // 部门open_department_id
-			parentDepartmentID   = item.ParentDepartmentID   // 上级部门ID
-			parentDepartmentName = item.ParentDepartmentName // 上级部门名称
-			status               = item.Status               // 部门状态
-			leaderUserID         = item.LeaderUserID         // 主管领导ID
-			leaderUserName       = item.LeaderUserName       // 主管领导姓名
-			departmentHrbps      = strings.Join(hrbps, "、")
-			uid                  = user.UserId
-			userOpenId           = user.OpenId
-			username             = user.Name
-			usernameEn           = user.EnName
-			userNikname          = user.Nickname
-			userGender           = gender
-			userPhone            = user.Mobile
-			userEmail            = user.Email
-			userEnterpriseEmail  = user.EnterpriseEmail
-			userStatus           = strings.Join(userStat, "、")
-			userDepts            = strings.Join(user.DepartmentIds, "、")
-			userWorkLocation     = workLocation
-			userJoinTime         = joinTime.String()
-			userEmployeeNo       = user.EmployeeNo
-			userIsAdmin          = isAdmin
-			userJobTitle         = user.JobTitle
-		)
-		d := []any{
-			i + 1,
-			deptName,
-			zhCnName,
-			jaJpName,
-			enUsName,
-			departmentID,
-			openDepartmentID,
-			parentDepartmentID,
-			parentDepartmentName,
-			status,
-			leaderUserID,
-			leaderUserName,
-			departmentHrbps,
-			uid,
-			userOpenId,
-			username,
-			usernameEn,
-			userNikname,
-			userGender,
-			userPhone,
-			userEmail,
-			userEnterpriseEmail,
-			userStatus,
-			userDepts,
-			userWorkLocation,
-			userJoinTime,
-			userEmployeeNo,
-			userIsAdmin,
-			userJobTitle,
-		}
-		data = append(data, d)
+		data = append(data, []any{i + 1, ids[i], user.Name, user.EmployeeNo, user.UserId, user.OpenId, user.UnionId, ""})
 	}
 
 	// 保存文件
@@ -1272,27 +3806,18 @@ func (cli *feiShuCli) saveDepartmentWithUserToExcel(tree []*FeiShuDepartmentNode
 }
 
 func (cli *feiShuCli) recursePrintDept(depts []*fs.DepartmentEntry, did, didType, uidType string, level int, index *int) error {
-	var deptChildren []*fs.DepartmentEntry
-	var err error
-	for i := 0; i < retry; i++ {
-		req := fs.NewGetDepartmentChildrenReqBuilder(FeiShuClient).
-			DepartmentId(did).
-			DepartmentIdType(didType).
-			UserIdType(uidType).
-			PageSize(50).
-			Build()
-		deptChildren, err = FeiShuClient.Department.Children(req)
-		if err != nil {
-			if errors.Is(err, context.Canceled) {
-				return nil
-			}
-			if i == retry-1 {
-				return err
-			}
-			time.Sleep(FeiShuDefaultInterval)
-			continue
+	req := fs.NewGetDepartmentChildrenReqBuilder(FeiShuClient).
+		DepartmentId(did).
+		DepartmentIdType(didType).
+		UserIdType(uidType).
+		PageSize(50).
+		Build()
+	deptChildren, err := FeiShuClient.Department.Children(req)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil
 		}
-		break
+		return err
 	}
 	if HttpCanceled {
 		return nil
@@ -1343,6 +3868,129 @@ func (cli *feiShuCli) recursePrintDept(depts []*fs.DepartmentEntry, did, didType
 	return nil
 }
 
+// generateDepartmentTreeHTML 生成包含部门信息的部门树HTML代码
+func (cli *feiShuCli) generateDepartmentTreeHTML(nodes []*FeiShuDepartmentNode, level int) string {
+	html := ""
+	for _, dept := range nodes {
+		html += fmt.Sprintf("<div style=\"margin-left:%dem;\">", level)
+
+		// 添加折叠/展开按钮
+		if len(dept.Children) > 0 {
+			html += fmt.Sprintf("<span class=\"toggle\" onclick=\"toggleDepartment(this)\">-</span>")
+		} else {
+			html += "<span class=\"empty-toggle\"></span>"
+		}
+
+		s := fmt.Sprintf("ID:%s&nbsp;&nbsp;OPEN_ID:%s&nbsp;&nbsp;名称:%s", dept.DepartmentID, dept.OpenDepartmentID, dept.Name)
+		if dept.EnUsName != "" {
+			s += fmt.Sprintf("&nbsp;&nbsp;英文名称:%s", dept.EnUsName)
+		}
+		if dept.JaJpName != "" {
+			s += fmt.Sprintf("&nbsp;&nbsp;日文名称:%s", dept.JaJpName)
+		}
+		if dept.Status != "" {
+			s += fmt.Sprintf("&nbsp;&nbsp;状态:%s", dept.Status)
+		}
+		if dept.LeaderUserName != "" {
+			s += fmt.Sprintf("&nbsp;&nbsp;领导:%s(ID:%s)", dept.LeaderUserName, dept.LeaderUserID)
+		} else if dept.LeaderUserID != "" {
+			s += fmt.Sprintf("&nbsp;&nbsp;领导:ID:%s", dept.LeaderUserID)
+		}
+		if len(dept.DepartmentHrbps) > 0 {
+			var tmp []string
+			for _, hrbp := range dept.DepartmentHrbps {
+				tmp = append(tmp, *hrbp)
+			}
+			s += fmt.Sprintf("&nbsp;&nbsp;Hrbp:%s", strings.Join(tmp, "、"))
+		}
+		s += fmt.Sprintf("&nbsp;&nbsp;用户个数:%d&nbsp;&nbsp;主属用户个数:%d", dept.MemberCount, dept.PrimaryMemberCount)
+
+		// 添加部门名称
+		html += fmt.Sprintf("<span class=\"department\">%s</span>", s)
+
+		// 递归生成子部门树
+		if len(dept.Children) > 0 {
+			html += cli.generateDepartmentTreeHTML(dept.Children, level+1)
+		}
+
+		html += "</div>"
+	}
+	return html
+}
+
+// saveDepartmentTreeToHTML 生成含部门信息的部门树并将其输出为HTML文档
+func (cli *feiShuCli) saveDepartmentTreeToHTML(nodes []*FeiShuDepartmentNode, filename string) (string, error) {
+	index := strings.LastIndex(filename, ".html")
+	if index == -1 {
+		filename = filename + ".html"
+	}
+	tmp := filename
+	// 判断文件是否存在
+	if utils.IsFileExists(filename) {
+		newFilename := generateNewFilename(filename)
+		filename = newFilename
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return "", errors.New("创建HTML文件失败: " + err.Error())
+	}
+	defer file.Close()
+	departmentTreeHTML := cli.generateDepartmentTreeHTML(nodes, 0)
+	htmlDocument := cli.generateTreeHTMLDocument(departmentTreeHTML)
+	_, err = file.WriteString(htmlDocument)
+	if err != nil {
+		return "", errors.New("无法写入HTML内容到文件: " + err.Error())
+	}
+	if filename == tmp {
+		return fmt.Sprintf("文件已保存至 %s", filename), nil
+	}
+	return fmt.Sprintf("%s 已存在,已另存为 %s", tmp, filename), nil
+}
+
+// saveDepartmentTreeToExcel 将部门树按层级展开保存为XLSX文档,不包含用户
+func (cli *feiShuCli) saveDepartmentTreeToExcel(tree []*FeiShuDepartmentNode, filename string) (string, error) {
+	if !strings.HasSuffix(filename, ".xlsx") {
+		filename = filename + ".xlsx"
+	}
+	tmp := filename
+	// 判断文件是否存在
+	if utils.IsFileExists(filename) {
+		newFilename := generateNewFilename(filename)
+		filename = newFilename
+	}
+
+	// 设置表头
+	headers := []any{"id", "层级", "部门名称", "部门中文名称", "部门日文名称", "部门英文名称", "部门ID", "部门OPEN_ID", "上级部门ID",
+		"上级部门名称", "部门状态", "部门主管ID", "部门主管姓名", "Hrbps", "用户个数", "主属用户个数", "部门群ID"}
+
+	var data [][]any
+	var walk func(nodes []*FeiShuDepartmentNode, level int)
+	walk = func(nodes []*FeiShuDepartmentNode, level int) {
+		for _, d := range nodes {
+			var hrbps []string
+			for _, hrbp := range d.DepartmentHrbps {
+				hrbps = append(hrbps, *hrbp)
+			}
+			row := []any{len(data) + 1, level, d.Name, d.ZhCnName, d.JaJpName, d.EnUsName, d.DepartmentID, d.OpenDepartmentID,
+				d.ParentDepartmentID, d.ParentDepartmentName, d.Status, d.LeaderUserID, d.LeaderUserName,
+				strings.Join(hrbps, "、"), d.MemberCount, d.PrimaryMemberCount, d.ChatID}
+			data = append(data, row)
+			walk(d.Children, level+1)
+		}
+	}
+	walk(tree, 0)
+
+	// 保存文件
+	err := saveToExcel(headers, data, filename)
+	if err != nil {
+		return "", errors.New("保存 Excel 文件失败: " + err.Error())
+	}
+	if tmp != filename {
+		return fmt.Sprintf("%s 已存在,已另存为 %s", tmp, filename), nil
+	}
+	return fmt.Sprintf("文件已保存至 %s", filename), nil
+}
+
 // generateDepartmentTreeWithUsersHTML 生成包含部门信息和用户信息的部门树HTML代码
 func (cli *feiShuCli) generateDepartmentTreeWithUsersHTML(nodes []*FeiShuDepartmentNode, level int) string {
 	html := ""
@@ -1382,14 +4030,7 @@ func (cli *feiShuCli) generateDepartmentTreeWithUsersHTML(nodes []*FeiShuDepartm
 		a := ""
 		for i := 0; i < len(dept.User); i++ {
 			user := dept.User[i]
-			var gender string
-			if user.Gender == 0 {
-				gender = "保密"
-			} else if user.Gender == 1 {
-				gender = "男"
-			} else if user.Gender == 2 {
-				gender = "女"
-			}
+			gender := fs.GenderName(user.Gender)
 			var hrbps []string
 			for _, hrbp := range dept.DepartmentHrbps {
 				hrbps = append(hrbps, *hrbp)
@@ -1410,13 +4051,6 @@ func (cli *feiShuCli) generateDepartmentTreeWithUsersHTML(nodes []*FeiShuDepartm
 			if user.Status.IsFrozen {
 				userStat = append(userStat, "已冻结")
 			}
-			var workLocation string
-			if user.Country != "" {
-				workLocation += user.Country
-			}
-			if user.City != "" {
-				workLocation += user.City
-			}
 			var isAdmin string
 			if user.IsTenantManager {
 				isAdmin = "是"
@@ -1424,7 +4058,8 @@ func (cli *feiShuCli) generateDepartmentTreeWithUsersHTML(nodes []*FeiShuDepartm
 			if !user.IsTenantManager {
 				isAdmin = "否"
 			}
-			m := fmt.Sprintf("ID:%s&nbsp;&nbsp;OPEN_ID:%s&nbsp;&nbsp;姓名:%s&nbsp;&nbsp;性别:%s&nbsp;&nbsp;电话号码:%s&nbsp;&nbsp;邮箱:%s&nbsp;&nbsp;企业邮箱:%s&nbsp;&nbsp;状态:%s&nbsp;&nbsp;工号:%s&nbsp;&nbsp;是否企业管理员:%s", user.UserId, user.OpenId, user.Name, gender, user.Mobile, user.Email, user.EnterpriseEmail, userStat, user.EmployeeNo, isAdmin)
+			employeeType := fs.EmployeeTypeName(user.EmployeeType, cli.getEmployeeTypeNames())
+			m := fmt.Sprintf("ID:%s&nbsp;&nbsp;OPEN_ID:%s&nbsp;&nbsp;姓名:%s&nbsp;&nbsp;性别:%s&nbsp;&nbsp;电话号码:%s&nbsp;&nbsp;邮箱:%s&nbsp;&nbsp;企业邮箱:%s&nbsp;&nbsp;状态:%s&nbsp;&nbsp;工号:%s&nbsp;&nbsp;人员类型:%s&nbsp;&nbsp;工作地点:%s&nbsp;&nbsp;是否企业管理员:%s", user.UserId, user.OpenId, user.Name, gender, user.Mobile, user.Email, user.EnterpriseEmail, userStat, user.EmployeeNo, employeeType, workLocationOf(user), isAdmin)
 			a += fmt.Sprintf("<li>%s</li>", m)
 		}
 		html += fmt.Sprintf("<ul class=\"user-list\">%s</ul>", a)
@@ -2,18 +2,21 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/fasnow/ghttp"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"idebug/logger"
+	"idebug/plugin"
 	fs "idebug/plugin/feishu"
 	"idebug/plugin/wechat"
 	"os"
 	"os/exec"
 	"runtime"
+	"sort"
 	"strings"
-	"time"
 )
 
 type Module string
@@ -25,29 +28,56 @@ const (
 )
 
 var (
-	Context               context.Context
-	Cancel                context.CancelFunc
-	HttpCanceled          bool
-	CurrentModule         *Module
-	WxClient              *wechat.Client
-	FeiShuClient          *fs.Client
-	FeiShuDefaultInterval = 100 * time.Millisecond
-	departmentIdTypeMap   = map[string]string{"id": "department_id", "openid": "open_department_id"} //飞书部门ID类型
-	userIdTypeMap         = map[string]string{"id": "user_id", "openid": "open_id"}                  //飞书用户ID类型
-	retry                 = 3
+	Context             context.Context
+	Cancel              context.CancelFunc
+	HttpCanceled        bool
+	CurrentModule       *Module
+	WxClient            *wechat.Client
+	FeiShuClient        *fs.Client
+	departmentIdTypeMap = map[string]string{"id": "department_id", "openid": "open_department_id"}       //飞书部门ID类型
+	userIdTypeMap       = map[string]string{"id": "user_id", "openid": "open_id", "unionid": "union_id"} //飞书用户ID类型
 )
 
 var (
 	Proxy                 *string
 	userId                string
 	departmentId          string
-	userIdType            string //飞书用户ID类型
-	departmentIdType      string //飞书部门ID类型缓存
-	userIdTypeCache       string //飞书用户ID类型
-	departmentIdTypeCache string //飞书部门ID类型缓存
-	password              string //飞书企业邮箱密码更改
-	recurse               bool   //递归获取
-	verbose               int    //打印过程的数量
+	userIdType            string     //飞书用户ID类型
+	departmentIdType      string     //飞书部门ID类型缓存
+	userIdTypeCache       = "openid" //飞书用户ID类型会话默认值,未指定--ut时作为返回的用户ID类型
+	departmentIdTypeCache = "openid" //飞书部门ID类型会话默认值,未指定--dt时作为返回的部门ID类型
+	password              string     //飞书企业邮箱密码更改
+	recurse               bool       //递归获取
+	mobiles               []string   //飞书批量查询用户的手机号
+	emails                []string   //飞书批量查询用户的邮箱
+	inputFile             string     //按行读取参数的文件
+	fromIdType            string     //飞书ID转换的源ID类型
+	toIdType              string     //飞书ID转换的目标ID类型
+	includeGroup          bool       //飞书导出时包含仅通过用户组授权的用户
+	receiveId             string     //飞书消息接收者ID
+	receiveIdType         string     //飞书消息接收者ID类型
+	msgType               string     //飞书消息类型
+	msgText               string     //飞书文本消息内容
+	msgFile               string     //飞书消息内容文件或者图片文件
+	replyMessageId        string     //飞书回复的消息ID
+	updateMessageId       string     //飞书更新的消息ID
+	fromTime              string     //查询的起始时间
+	toTime                string     //查询的结束时间
+	eventName             string     //飞书审计日志事件名称
+	statsDate             string     //飞书统计数据日期
+	containsChild         bool       //飞书部门统计数据是否包含子部门
+	corehrFields          []string   //飞书人事搜索员工返回的字段
+	bitableAppToken       string     //飞书导出同步的多维表格app_token
+	bitableTable          string     //飞书导出同步的多维表格数据表名称
+	dumpWorkers           int        //飞书导出时并发获取的部门数量
+	dumpResume            bool       //导出时从断点继续
+	callQuery             []string   //call命令的查询参数,格式为k=v
+	callBody              string     //call命令的请求体,@开头为文件
+	apiMaxPages           int        //api命令最多获取的页数
+	emitOnce              string     //当前命令输出的请求格式
+	unmask                bool       //输出请求时不隐藏凭证
+	harExport             bool       //请求记录导出为HAR
+	verbose               int        //打印过程的数量
 )
 
 const mainUsage = `Global Commands:
@@ -58,12 +88,26 @@ const mainUsage = `Global Commands:
     update            检测更新
     -h,--help,help    查看帮助
     set proxy <proxy> 设置代理,支持socks5,http
+    set emit <format> [--unmask]
+                      每个请求都输出可复现的命令或者代码,可选值:curl、httpie、go、off,默认隐藏凭证,--unmask:不隐藏凭证
+    --emit <format> [--unmask]
+                      仅对当前命令输出可复现的命令或者代码
+    calls ls [--unmask]
+                      查看本次会话的请求记录
+    set trace <on|off|file [path]>
+                      记录每个请求和响应(隐藏凭证),on:输出至控制台,file:追加至文件(默认idebug_trace.log)
+    calls export [--har] [--unmask]
+                      导出本次会话的请求记录为Postman Collection,--har:导出为包含响应的HAR文件
+    calls clear       清空本次会话的请求记录
 `
 
 type mainCli struct {
 	Root   *cobra.Command
 	set    *cobra.Command
 	proxy  *cobra.Command
+	emit   *cobra.Command
+	trace  *cobra.Command
+	calls  *cobra.Command
 	clear  *cobra.Command
 	update *cobra.Command
 	info   *cobra.Command
@@ -76,6 +120,9 @@ func NewMainCli() *mainCli {
 	cli.Root = cli.newRoot()
 	cli.set = cli.newSet()
 	cli.proxy = newProxy()
+	cli.emit = newEmit()
+	cli.trace = newTrace()
+	cli.calls = newCalls()
 	cli.clear = cli.newClear()
 	cli.update = cli.newUpdate()
 	cli.info = cli.newInfo()
@@ -86,8 +133,10 @@ func NewMainCli() *mainCli {
 }
 
 func (cli *mainCli) init() {
-	cli.set.AddCommand(cli.proxy)
-	cli.Root.AddCommand(cli.set, cli.clear, cli.update, cli.info, cli.use, cli.exit)
+	cli.Root.PersistentFlags().StringVar(&emitOnce, "emit", "", "当前命令输出的请求格式,可选值: curl、httpie、go")
+	cli.Root.PersistentFlags().BoolVar(&unmask, "unmask", false, "不隐藏凭证")
+	cli.set.AddCommand(cli.proxy, cli.emit, cli.trace)
+	cli.Root.AddCommand(cli.set, cli.clear, cli.update, cli.info, cli.use, cli.exit, cli.calls)
 	//cli.setHelpV1(cli.Root, "")
 	//cli.setHelpV1(cli.proxy, "")
 	//cli.setHelpV1(cli.set, "")
@@ -98,7 +147,8 @@ func (cli *mainCli) init() {
 	//cli.setHelpV1(cli.use, "")
 	//cli.setHelpV1(cli.exit, "")
 
-	cli.setHelpV2(cli.Root, cli.proxy, cli.set, cli.clear, cli.update, cli.info, cli.use, cli.exit)
+	cli.setHelpV2(cli.Root, cli.proxy, cli.emit, cli.trace, cli.set, cli.clear, cli.update, cli.info, cli.use, cli.exit, cli.calls)
+	cli.setHelpV2(cli.calls.Commands()...)
 }
 
 func (cli *mainCli) newExit() *cobra.Command {
@@ -198,6 +248,8 @@ func (cli *mainCli) newInfo() *cobra.Command {
 			} else {
 				fmt.Println(fmt.Sprintf("%-17s: %s", "proxy", *Proxy))
 			}
+			fmt.Println(fmt.Sprintf("%-17s: %s", "emit", emitSetting()))
+			fmt.Println(fmt.Sprintf("%-17s: %s", "trace", traceSetting()))
 		},
 	}
 }
@@ -216,12 +268,16 @@ func (cli *mainCli) newUse() *cobra.Command {
 				*CurrentModule = FeiShuModule
 				if FeiShuClient == nil {
 					FeiShuClient = fs.NewClient()
+					FeiShuClient.Limiter().OnBackoff = onBackoff
+					FeiShuClient.RetryPolicy().OnRetry = onRetry
 				}
 				break
 			case WxModule:
 				*CurrentModule = WxModule
 				if WxClient == nil {
 					WxClient = wechat.NewWxClient()
+					WxClient.Limiter().OnBackoff = onBackoff
+					WxClient.RetryPolicy().OnRetry = onRetry
 				}
 				break
 			default:
@@ -364,6 +420,52 @@ func (cli *mainCli) setHelpV2(cmds ...*cobra.Command) {
 	}
 }
 
+// newCall 生成call命令,do负责注入凭证并发送请求
+func newCall(do plugin.CallFunc) *cobra.Command {
+	return &cobra.Command{
+		Use:   `call`,
+		Short: `调用任意接口,自动注入凭证`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) < 2 {
+				logger.Error(errors.New("用法: call <METHOD> <path> [--query k=v] [--body @file.json]"))
+				return
+			}
+			var query [][2]string
+			for _, kv := range callQuery {
+				k, v, ok := strings.Cut(kv, "=")
+				if !ok {
+					logger.Error(fmt.Errorf("--query :错误的参数 %s,格式为k=v", kv))
+					return
+				}
+				query = append(query, [2]string{k, v})
+			}
+			var body []byte
+			if strings.HasPrefix(callBody, "@") {
+				data, err := os.ReadFile(callBody[1:])
+				if err != nil {
+					logger.Error(logger.FormatError(err))
+					return
+				}
+				body = data
+			} else if callBody != "" {
+				body = []byte(callBody)
+			}
+			if len(body) > 0 && !json.Valid(body) {
+				logger.Warning("请求体不是合法的JSON,将按原样发送")
+			}
+			response, err := do(args[0], args[1], query, body)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				logger.Error(logger.FormatError(err))
+				return
+			}
+			printRawResponse(response)
+		},
+	}
+}
+
 func setProxy(args []string) {
 	if len(args) == 0 {
 		*Proxy = ""
@@ -374,6 +476,24 @@ func setProxy(args []string) {
 	logger.Success("proxy => " + *Proxy)
 }
 
+// sortedKeys 返回排序后的map键
+func sortedKeys(m map[string]string) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// valueOf 返回字符串指针的值,nil返回空字符串
+func valueOf(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
 func reset() {
 	userId = ""
 	departmentId = ""
@@ -381,6 +501,35 @@ func reset() {
 	departmentIdType = ""
 	password = ""
 	recurse = false
+	mobiles = nil
+	emails = nil
+	inputFile = ""
+	fromIdType = ""
+	toIdType = ""
+	includeGroup = false
+	receiveId = ""
+	receiveIdType = "open_id"
+	msgType = "text"
+	msgText = ""
+	msgFile = ""
+	replyMessageId = ""
+	updateMessageId = ""
+	fromTime = ""
+	toTime = ""
+	eventName = ""
+	statsDate = ""
+	containsChild = false
+	corehrFields = nil
+	bitableAppToken = ""
+	bitableTable = "通讯录"
+	dumpWorkers = defaultWorkers
+	dumpResume = false
+	callQuery = nil
+	callBody = ""
+	apiMaxPages = 0
+	emitOnce = ""
+	unmask = false
+	harExport = false
 	verbose = -1
 	HttpCanceled = false
 }
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/buger/jsonparser"
 	"github.com/coreos/go-semver/semver"
@@ -8,12 +11,66 @@ import (
 	"github.com/xuri/excelize/v2"
 	"idebug/config"
 	"idebug/logger"
+	"idebug/plugin"
+	"idebug/utils"
 	"net/http"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// printRawResponse 打印接口的状态、耗时、响应头和格式化后的响应体
+func printRawResponse(response *plugin.RawResponse) {
+	logger.Info(fmt.Sprintf("%s %s", response.Method, response.Url))
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		logger.Success(fmt.Sprintf("%s 耗时%dms", response.Status, response.Duration.Milliseconds()))
+	} else {
+		logger.Warning(fmt.Sprintf("%s 耗时%dms", response.Status, response.Duration.Milliseconds()))
+	}
+	var keys []string
+	for key := range response.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("%s: %s\n", key, strings.Join(response.Header[key], ", "))
+	}
+	fmt.Println()
+	var out bytes.Buffer
+	if err := json.Indent(&out, response.Body, "", "  "); err == nil {
+		fmt.Println(out.String())
+	} else {
+		fmt.Println(string(response.Body))
+	}
+}
+
+// saveExport 将数据同时保存为XLSX和JSON文件,rows返回XLSX的表头和行数据
+func saveExport(items any, basename string, rows func() ([]any, [][]any)) {
+	logger.Info("正在保存至XLSX文件...")
+	filename := basename + ".xlsx"
+	if utils.IsFileExists(filename) {
+		filename = generateNewFilename(filename)
+	}
+	headers, data := rows()
+	if err := saveToExcel(headers, data, filename); err != nil {
+		logger.Error(errors.New("保存 Excel 文件失败: " + err.Error()))
+	} else {
+		logger.Success(fmt.Sprintf("文件已保存至 %s", filename))
+	}
+	logger.Info("正在保存至JSON文件...")
+	filename = basename + ".json"
+	if utils.IsFileExists(filename) {
+		filename = generateNewFilename(filename)
+	}
+	if err := saveToJSON(items, filename); err != nil {
+		logger.Error(errors.New("保存 JSON 文件失败: " + err.Error()))
+	} else {
+		logger.Success(fmt.Sprintf("文件已保存至 %s", filename))
+	}
+}
+
 // generateNewFilename 生成带时间戳的新文件名
 func generateNewFilename(filename string) string {
 	var ext string
@@ -30,6 +87,15 @@ func generateNewFilename(filename string) string {
 	return newFilename
 }
 
+// saveToJSON 以缩进格式保存数据至json文件
+func saveToJSON(data any, filename string) error {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, content, 0644)
+}
+
 // 保存数据至excel,header长度要和数据列数匹配
 func saveToExcel(header []any, data [][]any, filename string) error {
 	file := excelize.NewFile()
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"idebug/logger"
+	"idebug/plugin"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// onBackoff 触发频率限制时提示暂停时间
+func onBackoff(class string, wait time.Duration) {
+	logger.Warning(fmt.Sprintf("触发频率限制,[%s]类接口暂停%s", class, wait))
+}
+
+// qpsSetting 返回各接口类别QPS的描述
+func qpsSetting(limiter *plugin.RateLimiter) string {
+	var values []string
+	for _, class := range limiter.Classes() {
+		qps := "不限速"
+		if v := limiter.QPS(class); v > 0 {
+			qps = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		values = append(values, class+"="+qps)
+	}
+	return strings.Join(values, " ")
+}
+
+// newQps limiter在执行时获取,模块客户端在切换模块时才创建
+func newQps(limiter func() *plugin.RateLimiter) *cobra.Command {
+	return &cobra.Command{
+		Use:   `qps`,
+		Short: `设置各接口类别的QPS`,
+		Run: func(cmd *cobra.Command, args []string) {
+			l := limiter()
+			var class, value string
+			switch len(args) {
+			case 0:
+				logger.Info("qps => " + qpsSetting(l))
+				return
+			case 1:
+				value = args[0]
+			default:
+				class, value = args[0], args[1]
+			}
+			if class == "" && value == "reset" {
+				l.Reset()
+				logger.Success("qps => " + qpsSetting(l))
+				return
+			}
+			qps, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				logger.Error(errors.New("QPS必须为数字,0表示不限速"))
+				return
+			}
+			if err = l.SetQPS(class, qps); err != nil {
+				logger.Error(fmt.Errorf("%w,可选值: %s", err, strings.Join(l.Classes(), "、")))
+				return
+			}
+			logger.Success("qps => " + qpsSetting(l))
+		},
+	}
+}
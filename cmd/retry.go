@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"idebug/logger"
+	"idebug/plugin"
+	"strconv"
+	"time"
+)
+
+// onRetry 重试前提示失败类别和等待时间
+func onRetry(class plugin.ErrorClass, n int, delay time.Duration) {
+	logger.Info(fmt.Sprintf("请求失败(%s),%s后进行第%d次重试...", class, delay.Round(time.Millisecond), n))
+}
+
+// retrySetting 返回重试策略的描述
+func retrySetting(policy *plugin.RetryPolicy) string {
+	deadline := "不限制"
+	if policy.Deadline > 0 {
+		deadline = policy.Deadline.String()
+	}
+	return fmt.Sprintf("%d次,总时长%s", policy.Retries, deadline)
+}
+
+// newRetry policy在执行时获取,模块客户端在切换模块时才创建
+func newRetry(policy func() *plugin.RetryPolicy) *cobra.Command {
+	return &cobra.Command{
+		Use:   `retry`,
+		Short: `设置请求失败时的重试次数和总时长`,
+		Run: func(cmd *cobra.Command, args []string) {
+			p := policy()
+			if len(args) == 0 {
+				logger.Info("retry => " + retrySetting(p))
+				return
+			}
+			if args[0] == "reset" {
+				p.Retries, p.Deadline = plugin.DefaultRetries, plugin.DefaultRetryDeadline
+				logger.Success("retry => " + retrySetting(p))
+				return
+			}
+			retries, err := strconv.Atoi(args[0])
+			if err != nil || retries < 0 {
+				logger.Error(errors.New("重试次数必须为非负整数,0表示不重试"))
+				return
+			}
+			deadline := p.Deadline
+			if len(args) > 1 {
+				if deadline, err = time.ParseDuration(args[1]); err != nil || deadline < 0 {
+					logger.Error(errors.New("总时长格式错误,如30s、2m,0表示不限制"))
+					return
+				}
+			}
+			p.Retries, p.Deadline = retries, deadline
+			logger.Success("retry => " + retrySetting(p))
+		},
+	}
+}
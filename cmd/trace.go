@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"idebug/logger"
+	"idebug/plugin"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// defaultTraceFile set trace file未指定文件时的默认文件
+const defaultTraceFile = "idebug_trace.log"
+
+var (
+	traceMode string // 为空时关闭,on输出至控制台,file追加至traceFile
+	traceFile string
+	traceLock sync.Mutex
+)
+
+func init() {
+	plugin.AddHook(onTrace)
+}
+
+// onTrace 按set trace设置记录隐藏凭证后的请求和响应
+func onTrace(call *plugin.Call) {
+	if traceMode == "" {
+		return
+	}
+	text := formatTrace(plugin.MaskCall(call))
+	traceLock.Lock()
+	defer traceLock.Unlock()
+	if traceMode == "on" {
+		fmt.Print(text)
+		return
+	}
+	file, err := os.OpenFile(traceFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		logger.Error(logger.FormatError(err))
+		return
+	}
+	defer file.Close()
+	if _, err = io.WriteString(file, text); err != nil {
+		logger.Error(logger.FormatError(err))
+	}
+}
+
+// formatTrace 按>>> 请求、<<< 响应的格式输出请求记录
+func formatTrace(call *plugin.Call) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, ">>> %s %s %s\n", call.Time.Format("2006-01-02 15:04:05.000"), call.Method, call.Url)
+	writeTraceHeaders(&b, call.Header)
+	writeTraceBody(&b, call.Body, call.Header.Get("Content-Type"))
+	if call.Err != nil {
+		fmt.Fprintf(&b, "<<< 请求失败 %dms: %s\n", call.Duration.Milliseconds(), call.Err.Error())
+	}
+	if call.StatusCode != 0 {
+		fmt.Fprintf(&b, "<<< %s %dms\n", call.Status, call.Duration.Milliseconds())
+		writeTraceHeaders(&b, call.ResponseHeader)
+		writeTraceBody(&b, call.ResponseBody, call.ResponseHeader.Get("Content-Type"))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func writeTraceHeaders(b *strings.Builder, header map[string][]string) {
+	var keys []string
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(b, "%s: %s\n", key, strings.Join(header[key], ", "))
+	}
+}
+
+func writeTraceBody(b *strings.Builder, body []byte, contentType string) {
+	if len(body) == 0 {
+		return
+	}
+	b.WriteString("\n")
+	var out bytes.Buffer
+	switch {
+	case json.Indent(&out, body, "", "  ") == nil:
+		b.Write(out.Bytes())
+	case strings.HasPrefix(contentType, "multipart/") || strings.HasPrefix(contentType, "image/"):
+		fmt.Fprintf(b, "(二进制数据%d字节)", len(body))
+	default:
+		b.Write(body)
+	}
+	b.WriteString("\n")
+}
+
+// traceSetting 返回trace设置的描述
+func traceSetting() string {
+	switch traceMode {
+	case "on":
+		return "on"
+	case "file":
+		return "file " + traceFile
+	}
+	return "off"
+}
+
+func newTrace() *cobra.Command {
+	return &cobra.Command{
+		Use:   `trace`,
+		Short: `设置是否记录请求和响应`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				logger.Error(errors.New("可选值: on、off、file [path]"))
+				return
+			}
+			switch args[0] {
+			case "on":
+				traceMode = "on"
+			case "off":
+				traceMode = ""
+			case "file":
+				traceFile = defaultTraceFile
+				if len(args) > 1 {
+					traceFile = args[1]
+				}
+				traceMode = "file"
+			default:
+				logger.Error(fmt.Errorf("未知的值: %s,可选值: on、off、file [path]", args[0]))
+				return
+			}
+			logger.Success("trace => " + traceSetting())
+		},
+	}
+}
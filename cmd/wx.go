@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"idebug/logger"
+	"idebug/plugin"
 	"idebug/plugin/wechat"
 	"idebug/utils"
 	"os"
@@ -14,34 +15,74 @@ import (
 )
 
 const wechatUsage = mainUsage + `wechat Module:
-    set corpid     <corpid>      设置corpid
-    set corpsecret <corpsecret>  设置corpsecret
+    set mode       <mode>        设置认证模式,可选值:corp(默认,企业自建应用)、suite(第三方应用)、provider(服务商,
+                                 只能通过call调用服务商接口,dp、user、dump不可用)
+    set corpid     <corpid>      设置corpid,provider模式下为服务商corpid
+    set corpsecret <corpsecret>  设置corpsecret,corp模式使用
+    set suiteid    <suiteid>     设置suite_id,suite模式使用
+    set suitesecret <secret>     设置suite_secret,suite模式使用
+    set suiteticket <ticket>     设置suite_ticket,suite模式使用
+    set authcorpid <corpid>      设置授权企业corpid,suite模式使用
+    set permanentcode <code>     设置授权企业永久授权码,suite模式使用
+    set providersecret <secret>  设置provider_secret,provider模式使用,该凭证仅能调用服务商相关接口
     set token      <token>       设置access_token,与set corpid和set corpsecret互斥
-    run                          获取access_token
+    set qps  [class] <n|reset>   设置接口类别(token、contact、default)的QPS,不指定类别时设置全部,0:不限速,reset:恢复默认值
+    set retry <n|reset> [deadline]
+                                 设置网络错误、5xx和频率限制的重试次数(默认3)和包含重试的总时长(默认1m),0:不重试或不限制
+    run                          根据认证模式获取access_token
     dp             <did>         根据<did>查看部门详情  
     dp ls          <did>         根据<did>递归获取子部门id,不提供<did>则递归获取默认部门
     dp tree        <did>         根据<did>递归获取子部门信息,稍微详细一些,不提供<did>则递归获取默认部门  
     user           <uid>         根据<uid>查看用户详情
     user ls        <did> [-r]    根据<did>查看部门用户列表,-r:递归获取(默认false)
-    dump           <did>         根据<did>递归导出部门用户,不提供<did>则递归获取默认部门
+    dump           <did>         根据<did>递归导出部门用户,不提供<did>则递归获取默认部门,按部门逐个获取直属成员
+    dump --resume                从上次中断(Ctrl+C、网络错误或者凭证过期)的位置继续导出,已获取用户的部门不再重复获取,
+                                 中断时已获取的用户会导出至wechat_dump_partial.html和wechat_dump_partial.xlsx
+    call <METHOD> <path> [--query k=v] [--body @file.json]
+                                 调用任意接口,自动注入access_token(provider模式为provider_access_token),
+                                 <path>为/cgi-bin下的路径或者接口域名下的完整URL,--query可多次指定,
+                                 --body为JSON字符串或者@文件,打印状态、响应头、耗时和格式化后的响应
+    api ls                       查看接口目录,包含内置接口和自定义目录下YAML文件中的接口
+    api reload                   重新加载接口目录
+    api <name> [k=v ...] [--pages <n>]
+                                 调用接口目录中的接口,自动翻页并导出XLSX和JSON,--pages:最多获取的页数(默认所有页)
+    cache ls                     查看部门和用户名称索引,索引由部门和用户列表填充,显示名称时优先查询索引
+    cache clear                  清空索引
+    cache refresh                清空索引并获取通讯录授权范围内的部门和用户重新填充
+    cache persist  <on|off>      设置是否将索引按corpid保存至磁盘(默认off)
 `
 
 // set domain     <domain>      设置接口域名,默认值为官方接口【https://qyapi.weixin.qq.com】,自建企业微信使用该方法设置
 type wechatCli struct {
-	Root       *cobra.Command
-	info       *cobra.Command
-	run        *cobra.Command
-	set        *cobra.Command
-	corpId     *cobra.Command
-	corpSecret *cobra.Command
-	token      *cobra.Command
-	domain     *cobra.Command
-	dp         *cobra.Command
-	dpLs       *cobra.Command
-	dpTree     *cobra.Command
-	user       *cobra.Command
-	userLs     *cobra.Command
-	dump       *cobra.Command
+	Root           *cobra.Command
+	info           *cobra.Command
+	run            *cobra.Command
+	set            *cobra.Command
+	mode           *cobra.Command
+	corpId         *cobra.Command
+	corpSecret     *cobra.Command
+	suiteId        *cobra.Command
+	suiteSecret    *cobra.Command
+	suiteTicket    *cobra.Command
+	authCorpId     *cobra.Command
+	permanentCode  *cobra.Command
+	providerSecret *cobra.Command
+	token          *cobra.Command
+	domain         *cobra.Command
+	dp             *cobra.Command
+	dpLs           *cobra.Command
+	dpTree         *cobra.Command
+	user           *cobra.Command
+	userLs         *cobra.Command
+	dump           *cobra.Command
+	call           *cobra.Command
+	api            *cobra.Command
+	emit           *cobra.Command
+	trace          *cobra.Command
+	qps            *cobra.Command
+	retry          *cobra.Command
+	calls          *cobra.Command
+	cache          *cobra.Command
 }
 
 func NewWechatCli() *wechatCli {
@@ -52,6 +93,13 @@ func NewWechatCli() *wechatCli {
 	cli.set = cli.newSet()
 	cli.corpId = cli.newCorpId()
 	cli.corpSecret = cli.newCorpSecret()
+	cli.mode = cli.newMode()
+	cli.suiteId = cli.newSetValue("suiteid", "设置suite_id", (*wechat.Client).SetSuiteId)
+	cli.suiteSecret = cli.newSetValue("suitesecret", "设置suite_secret", (*wechat.Client).SetSuiteSecret)
+	cli.suiteTicket = cli.newSetValue("suiteticket", "设置suite_ticket", (*wechat.Client).SetSuiteTicket)
+	cli.authCorpId = cli.newSetValue("authcorpid", "设置授权企业corpid", (*wechat.Client).SetAuthCorpId)
+	cli.permanentCode = cli.newSetValue("permanentcode", "设置授权企业永久授权码", (*wechat.Client).SetPermanentCode)
+	cli.providerSecret = cli.newSetValue("providersecret", "设置provider_secret", (*wechat.Client).SetProviderSecret)
 	cli.domain = cli.newBaseDomain()
 	cli.dp = cli.newDp()
 	cli.dpLs = cli.newDpLs()
@@ -59,6 +107,14 @@ func NewWechatCli() *wechatCli {
 	cli.user = cli.newUser()
 	cli.userLs = cli.newUserLs()
 	cli.dump = cli.newDump()
+	cli.call = newCall(cli.callApi)
+	cli.api = newApi(WxModule, cli.callApi)
+	cli.emit = newEmit()
+	cli.trace = newTrace()
+	cli.qps = newQps(func() *plugin.RateLimiter { return WxClient.Limiter() })
+	cli.retry = newRetry(func() *plugin.RetryPolicy { return WxClient.RetryPolicy() })
+	cli.calls = newCalls()
+	cli.cache = newCache(cli.directory, cli.refreshDirectory)
 	cli.init()
 	return cli
 }
@@ -69,16 +125,30 @@ func (cli *wechatCli) init() {
 
 	//cli.userLs.Flags().IntVarP(&verbose, "verbose", "v", -1, "控制台输出的条数,默认全部输出")
 	cli.userLs.Flags().BoolVarP(&recurse, "re", "r", false, "是否递归获取,默认false")
+	cli.dump.Flags().BoolVar(&dumpResume, "resume", false, "从上次中断的位置继续导出,使用上次的部门ID")
+	cli.call.Flags().StringArrayVar(&callQuery, "query", nil, "查询参数,格式为k=v,可多次指定")
+	cli.call.Flags().StringVar(&callBody, "body", "", "请求体JSON,@开头为文件,如@body.json")
 
+	cli.set.AddCommand(cli.mode)
 	cli.set.AddCommand(cli.corpId)
 	cli.set.AddCommand(cli.corpSecret)
+	cli.set.AddCommand(cli.suiteId, cli.suiteSecret, cli.suiteTicket, cli.authCorpId, cli.permanentCode, cli.providerSecret)
 	cli.set.AddCommand(cli.domain)
-	cli.set.AddCommand(newProxy())
+	cli.set.AddCommand(newProxy(), cli.emit, cli.trace, cli.qps, cli.retry)
+	cli.Root.PersistentFlags().StringVar(&emitOnce, "emit", "", "当前命令输出的请求格式,可选值: curl、httpie、go")
+	cli.Root.PersistentFlags().BoolVar(&unmask, "unmask", false, "不隐藏凭证")
 	cli.dp.AddCommand(cli.dpLs, cli.dpTree)
 	cli.user.AddCommand(cli.userLs)
-	cli.Root.AddCommand(cli.set, cli.run, cli.info, cli.dp, cli.user, cli.dump)
-
-	cli.setHelpV1(cli.Root, cli.info, cli.run, cli.set, cli.corpId, cli.corpSecret, cli.dp, cli.dpLs, cli.dpTree, cli.user, cli.userLs, cli.dump)
+	cli.Root.AddCommand(cli.set, cli.run, cli.info, cli.dp, cli.user, cli.dump, cli.call, cli.api, cli.calls, cli.cache)
+
+	cli.setHelpV1(cli.api)
+	cli.setHelpV1(cli.api.Commands()...)
+	cli.setHelpV1(cli.emit, cli.trace, cli.qps, cli.retry, cli.calls)
+	cli.setHelpV1(cli.calls.Commands()...)
+	cli.setHelpV1(cli.cache)
+	cli.setHelpV1(cli.cache.Commands()...)
+	cli.setHelpV1(cli.Root, cli.info, cli.run, cli.set, cli.mode, cli.corpId, cli.corpSecret, cli.suiteId, cli.suiteSecret,
+		cli.suiteTicket, cli.authCorpId, cli.permanentCode, cli.providerSecret, cli.dp, cli.dpLs, cli.dpTree, cli.user, cli.userLs, cli.dump, cli.call)
 }
 
 func (cli *wechatCli) newRoot() *cobra.Command {
@@ -92,6 +162,9 @@ func (cli *wechatCli) newRoot() *cobra.Command {
 			return nil
 		},
 		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			if err := saveDirectories(); err != nil {
+				logger.Error(logger.FormatError(err))
+			}
 			reset()
 		},
 	}
@@ -105,15 +178,32 @@ func (cli *wechatCli) newRun() *cobra.Command {
 			conf := WxClient.GetConfig()
 			if conf.AccessToken != nil && *conf.AccessToken != "" {
 				return nil
-			} else {
-				if conf.CorpId == nil || *conf.CorpId == "" {
+			}
+			isEmpty := func(v *string) bool { return v == nil || *v == "" }
+			switch conf.AuthMode {
+			case wechat.AuthModeSuite:
+				if isEmpty(conf.SuiteId) || isEmpty(conf.SuiteSecret) || isEmpty(conf.SuiteTicket) {
+					return fmt.Errorf("请先设置suiteid、suitesecret和suiteticket")
+				}
+				if isEmpty(conf.AuthCorpId) || isEmpty(conf.PermanentCode) {
+					return fmt.Errorf("请先设置authcorpid和permanentcode")
+				}
+			case wechat.AuthModeProvider:
+				if isEmpty(conf.CorpId) {
 					return fmt.Errorf("请先设置corpid")
 				}
-				if conf.CorpSecret == nil || *conf.CorpSecret == "" {
+				if isEmpty(conf.ProviderSecret) {
+					return fmt.Errorf("请先设置providersecret")
+				}
+			default:
+				if isEmpty(conf.CorpId) {
+					return fmt.Errorf("请先设置corpid")
+				}
+				if isEmpty(conf.CorpSecret) {
 					return fmt.Errorf("请先设置corpsecret")
 				}
-				return nil
 			}
+			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			conf := WxClient.GetConfig()
@@ -193,6 +283,40 @@ func (cli *wechatCli) newCorpSecret() *cobra.Command {
 	}
 }
 
+func (cli *wechatCli) newMode() *cobra.Command {
+	return &cobra.Command{
+		Use:   "mode",
+		Short: `设置认证模式,可选值:corp、suite、provider`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) < 1 {
+				logger.Warning("请提供一个值")
+				return
+			}
+			if err := WxClient.SetAuthMode(wechat.AuthMode(args[0])); err != nil {
+				logger.Error(err)
+				return
+			}
+			logger.Success("mode => " + args[0])
+		},
+	}
+}
+
+// newSetValue 生成只需要一个参数的set子命令
+func (cli *wechatCli) newSetValue(use, short string, set func(*wechat.Client, string)) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) < 1 {
+				logger.Warning("请提供一个值")
+				return
+			}
+			set(WxClient, args[0])
+			logger.Success(use + " => " + args[0])
+		},
+	}
+}
+
 func (cli *wechatCli) newToken() *cobra.Command {
 	return &cobra.Command{
 		Use:   "token",
@@ -213,6 +337,9 @@ func (cli *wechatCli) newDp() *cobra.Command {
 		Use:   "dp",
 		Short: `部门操作`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := cli.checkContactMode(); err != nil {
+				return err
+			}
 			if !cli.hasAccessToken() {
 				return fmt.Errorf("请先执行run获取access_token")
 			}
@@ -237,6 +364,7 @@ func (cli *wechatCli) newDp() *cobra.Command {
 			if HttpCanceled {
 				return
 			}
+			cli.directory().AddDepartment(deptInfo.Name, strconv.Itoa(deptInfo.ID))
 			fmt.Printf("%s\n", strings.Repeat("=", 20))
 			fmt.Printf("%-10s: %d\n", "部门ID", deptInfo.ID)
 			fmt.Printf("%-8s: %d\n", "上级部门ID", deptInfo.ParentId)
@@ -256,28 +384,22 @@ func (cli *wechatCli) newDpLs() *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			var departmentList []*WxDepartmentNode
 			var depts []*wechat.DepartmentEntrySimplified
-			for i := 0; i < retry; i++ {
-				var err error
-				if len(args) == 0 {
-					req := wechat.NewGetDepartmentIdListReqBuilder(WxClient).Build()
-					depts, err = WxClient.Department.GetIdList(req)
+			var err error
+			if len(args) == 0 {
+				req := wechat.NewGetDepartmentIdListReqBuilder(WxClient).Build()
+				depts, err = WxClient.Department.GetIdList(req)
 
-				} else {
-					//递归获取指定部门所有ID
-					req := wechat.NewGetDepartmentIdListReqBuilder(WxClient).DepartmentId(args[0]).Build()
-					depts, err = WxClient.Department.GetIdList(req)
-				}
-				if err != nil {
-					if errors.Is(err, context.Canceled) {
-						return
-					}
-					if i == retry-1 {
-						logger.Error(logger.FormatError(err))
-						return
-					}
-					continue
+			} else {
+				//递归获取指定部门所有ID
+				req := wechat.NewGetDepartmentIdListReqBuilder(WxClient).DepartmentId(args[0]).Build()
+				depts, err = WxClient.Department.GetIdList(req)
+			}
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
 				}
-				break
+				logger.Error(logger.FormatError(err))
+				return
 			}
 			if HttpCanceled {
 				return
@@ -314,25 +436,19 @@ func (cli *wechatCli) newDpTree() *cobra.Command {
 			var departmentList []*WxDepartmentNode
 			var err error
 			var departments []*wechat.DepartmentEntry
-			for i := 0; i < retry; i++ {
-				if len(args) == 0 {
-					req := wechat.NewGetDepartmentListReqBuilder(WxClient).Build()
-					departments, err = WxClient.Department.GetList(req)
-				} else {
-					req := wechat.NewGetDepartmentListReqBuilder(WxClient).DepartmentId(args[0]).Build()
-					departments, err = WxClient.Department.GetList(req)
-				}
-				if err != nil {
-					if errors.Is(err, context.Canceled) {
-						return
-					}
-					if i == retry-1 {
-						logger.Error(logger.FormatError(err))
-						return
-					}
-					continue
+			if len(args) == 0 {
+				req := wechat.NewGetDepartmentListReqBuilder(WxClient).Build()
+				departments, err = WxClient.Department.GetList(req)
+			} else {
+				req := wechat.NewGetDepartmentListReqBuilder(WxClient).DepartmentId(args[0]).Build()
+				departments, err = WxClient.Department.GetList(req)
+			}
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
 				}
-				break
+				logger.Error(logger.FormatError(err))
+				return
 			}
 			if HttpCanceled {
 				return
@@ -341,6 +457,7 @@ func (cli *wechatCli) newDpTree() *cobra.Command {
 				logger.Warning("无可用部门信息")
 				return
 			}
+			cli.indexDepartments(departments)
 			for _, v := range departments {
 				d := wechat.DepartmentEntry{
 					DepartmentEntrySimplified: wechat.DepartmentEntrySimplified{
@@ -373,6 +490,9 @@ func (cli *wechatCli) newUser() *cobra.Command {
 		Use:   "user",
 		Short: `用户操作`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := cli.checkContactMode(); err != nil {
+				return err
+			}
 			if !cli.hasAccessToken() {
 				return fmt.Errorf("请先执行run获取access_token")
 			}
@@ -397,6 +517,7 @@ func (cli *wechatCli) newUser() *cobra.Command {
 			if HttpCanceled {
 				return
 			}
+			cli.directory().AddUser(userInfo.Name, userInfo.UserId)
 			cli.showUserInfo(userInfo, false)
 			return
 		},
@@ -430,6 +551,8 @@ func (cli *wechatCli) newUserLs() *cobra.Command {
 				logger.Warning("无可用用户信息")
 				return
 			}
+			cli.indexUsers(userList)
+			cli.prefetchDepartments(userList)
 			for i := 0; i < len(userList); i++ {
 				//if i >= verbose && verbose > -1 {
 				//	logger.Info("更多数据请查看文件")
@@ -453,6 +576,9 @@ func (cli *wechatCli) newDump() *cobra.Command {
 		Use:   "dump",
 		Short: `根据部门ID导出用户,不提供部门ID则导出通讯录授权范围内所有部门用户`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := cli.checkContactMode(); err != nil {
+				return err
+			}
 			if !cli.hasAccessToken() {
 				return fmt.Errorf("请先执行run获取access_token")
 
@@ -460,37 +586,50 @@ func (cli *wechatCli) newDump() *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			var departmentTreeResource []*wechat.DepartmentEntry
-			var deptList []*wechat.DepartmentEntry
-			var err error
-			logger.Info("正在获取部门树...")
-			for i := 0; i < retry; i++ {
-				if len(args) == 0 {
-					req := wechat.NewGetDepartmentListReqBuilder(WxClient).Build()
-					deptList, err = WxClient.Department.GetList(req)
-				} else {
-					req := wechat.NewGetDepartmentListReqBuilder(WxClient).DepartmentId(args[0]).Build()
-					deptList, err = WxClient.Department.GetList(req)
+			if dumpResume && len(args) > 0 {
+				logger.Warning("--resume会使用上次的部门ID,忽略提供的部门ID")
+				args = nil
+			}
+			key := cli.directory().Key
+			var checkpoint *dumpCheckpoint
+			if dumpResume {
+				var err error
+				checkpoint, err = loadDumpCheckpoint(plugin.PlatformWechat, key)
+				if err != nil {
+					logger.Error(logger.FormatError(err))
+					return
 				}
+				logger.Info(fmt.Sprintf("从断点继续导出,已获取%d个部门的用户", checkpoint.Count()))
+			} else {
+				checkpoint = newDumpCheckpoint(plugin.PlatformWechat, key, args, "", "", len(args) == 0)
+			}
+			deptList := checkpoint.Departments
+			if len(deptList) == 0 {
+				logger.Info("正在获取部门树...")
+				builder := wechat.NewGetDepartmentListReqBuilder(WxClient)
+				if len(checkpoint.DeptIds) > 0 {
+					builder = builder.DepartmentId(checkpoint.DeptIds[0])
+				}
+				var err error
+				deptList, err = WxClient.Department.GetList(builder.Build())
 				if err != nil {
 					if errors.Is(err, context.Canceled) {
 						return
 					}
-					if i == retry-1 {
-						logger.Error(logger.FormatError(err))
-						return
-					}
-					continue
+					logger.Error(logger.FormatError(err))
+					return
 				}
-				break
-			}
-			if HttpCanceled {
-				return
-			}
-			if len(deptList) == 0 {
-				logger.Warning("无可用部门信息")
-				return
+				if interrupted() {
+					return
+				}
+				if len(deptList) == 0 {
+					logger.Warning("无可用部门信息")
+					return
+				}
+				checkpoint.putDepartments(deptList)
 			}
+			cli.indexDepartments(deptList)
+			var departmentTreeResource []*wechat.DepartmentEntry
 			for _, v := range deptList {
 				d := wechat.DepartmentEntry{
 					DepartmentEntrySimplified: wechat.DepartmentEntrySimplified{
@@ -505,38 +644,56 @@ func (cli *wechatCli) newDump() *cobra.Command {
 				departmentTreeResource = append(departmentTreeResource, &d)
 			}
 			departmentTree := cli.buildDepartmentTree(departmentTreeResource)
+			// 逐个部门获取直属成员并记录断点,中断时已获取的成员仍会导出
 			logger.Info("正在获取用户...")
-			var userList []*wechat.UserEntry
-			for i := 0; i < retry; i++ {
-				req := wechat.NewGetUsersByDepartmentIdReqBuilder(WxClient).DepartmentId(strconv.Itoa(departmentTreeResource[0].ID)).Fetch(true).Build()
-				userList, err = WxClient.User.GetUsersByDepartmentId(req)
-				if err != nil {
-					if errors.Is(err, context.Canceled) {
-						return
-					}
-					if i == retry-1 {
-						logger.Error(logger.FormatError(err))
-						return
+			var isErrorOcurred, canceled bool
+			for _, dept := range deptList {
+				if interrupted() {
+					canceled = true
+					break
+				}
+				id := strconv.Itoa(dept.ID)
+				var users []*wechat.UserEntry
+				if rec, ok := checkpoint.visited(id); ok {
+					users = rec.WechatUsers
+				} else {
+					req := wechat.NewGetUsersByDepartmentIdReqBuilder(WxClient).DepartmentId(id).Build()
+					var err error
+					users, err = WxClient.User.GetUsersByDepartmentId(req)
+					if err != nil {
+						if errors.Is(err, context.Canceled) || interrupted() {
+							canceled = true
+						} else {
+							isErrorOcurred = true
+							logger.Error(logger.FormatError(err))
+							logger.Info(fmt.Sprintf("部门[%s]用户获取失败,终止获取", id))
+						}
+						break
 					}
-					continue
+					checkpoint.putVisited(id, &crawlRecord{WechatUsers: users})
+				}
+				cli.indexUsers(users)
+				for _, wxUser := range users {
+					cli.insertUserToDepartmentTree(wxUser, dept.ID, departmentTree)
 				}
-				break
-			}
-			if HttpCanceled {
-				return
 			}
-
-			// 将用户插入到部门树中
-			for _, wxUser := range userList {
-				departmentIDs := wxUser.Department
-				for _, departmentID := range departmentIDs {
-					cli.insertUserToDepartmentTree(wxUser, departmentID, departmentTree)
+			// 中断或者出错时保存断点,已获取的用户导出至带_partial后缀的文件
+			filename := "wechat_dump"
+			if isErrorOcurred || canceled || interrupted() {
+				filename += "_partial"
+				logger.Warning("导出未完成,会保存已获取数据")
+				if err := checkpoint.Save(); err != nil {
+					logger.Error(logger.FormatError(err))
+				} else {
+					logger.Info(fmt.Sprintf("已保存断点(已获取%d个部门的用户),执行dump --resume继续导出", checkpoint.Count()))
 				}
+			} else if err := checkpoint.Remove(); err != nil {
+				logger.Error(logger.FormatError(err))
 			}
 
 			//保存文件
 			logger.Info("正在保存至html文件...")
-			msg, err := cli.saveDepartmentTreeWithUsersToHTML(departmentTree, "wechat_dump.html")
+			msg, err := cli.saveDepartmentTreeWithUsersToHTML(departmentTree, filename+".html")
 			if err != nil {
 				logger.Error(logger.FormatError(err))
 				logger.Info("保存文件失败")
@@ -545,7 +702,7 @@ func (cli *wechatCli) newDump() *cobra.Command {
 			}
 
 			logger.Info("正在保存至xlsx文件...")
-			msg, err = cli.saveUserTreeToExcel(departmentTree, "wechat_dump.xlsx")
+			msg, err = cli.saveUserTreeToExcel(departmentTree, filename+".xlsx")
 			if err != nil {
 				logger.Error(logger.FormatError(err))
 				logger.Info("保存文件失败")
@@ -570,19 +727,25 @@ func (cli *wechatCli) hasAccessToken() bool {
 	return WxClient.GetAccessTokenFromCache() != ""
 }
 
+// checkContactMode provider_access_token只能调用服务商接口,通讯录接口不接受
+func (cli *wechatCli) checkContactMode() error {
+	if WxClient.GetConfig().AuthMode == wechat.AuthModeProvider {
+		return errors.New("provider模式获取的provider_access_token不能调用通讯录接口,请使用corp或suite模式,服务商接口可通过call调用")
+	}
+	return nil
+}
+
 func (cli *wechatCli) showUserInfo(userInfo *wechat.UserEntry, inLine bool) {
 	var depts []string
 	for _, deptId := range userInfo.Department {
-		for i := 0; i < retry; i++ {
-			req := wechat.NewGetDepartmentReqBuilder(WxClient).DepartmentId(strconv.Itoa(deptId)).Build()
-			deptInfo, err := WxClient.Department.Get(req)
-			if err != nil {
+		name, err := cli.departmentName(deptId)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
 				logger.Error(logger.FormatError(err))
-				continue
 			}
-			depts = append(depts, fmt.Sprintf("%s (ID:%d)", deptInfo.Name, deptId))
-			break
+			continue
 		}
+		depts = append(depts, fmt.Sprintf("%s (ID:%d)", name, deptId))
 	}
 	if inLine {
 		s := fmt.Sprintf("  -ID[%s] 姓名[%s] 所属部门ID[%s] 职位[%s] 手机[%s] 邮箱[%s] 微信二维码[%s]", userInfo.UserId, userInfo.Name, strings.Join(depts, "、"), userInfo.Position, userInfo.Mobile, userInfo.Email, userInfo.QrCode)
@@ -600,6 +763,105 @@ func (cli *wechatCli) showUserInfo(userInfo *wechat.UserEntry, inLine bool) {
 	fmt.Printf("%s\n", strings.Repeat("=", 20))
 }
 
+// directory 返回当前企业的部门和用户名称索引,suite模式下按授权企业区分
+func (cli *wechatCli) directory() *directoryIndex {
+	conf := WxClient.GetConfig()
+	key := valueOf(conf.CorpId)
+	if conf.AuthMode == wechat.AuthModeSuite {
+		key = valueOf(conf.AuthCorpId)
+	}
+	return directoryFor(plugin.PlatformWechat, key)
+}
+
+func (cli *wechatCli) indexDepartments(depts []*wechat.DepartmentEntry) {
+	dir := cli.directory()
+	for _, dept := range depts {
+		dir.AddDepartment(dept.Name, strconv.Itoa(dept.ID))
+	}
+}
+
+func (cli *wechatCli) indexUsers(users []*wechat.UserEntry) {
+	dir := cli.directory()
+	for _, user := range users {
+		dir.AddUser(user.Name, user.UserId)
+	}
+}
+
+// departmentName 优先从索引获取部门名称,不存在时获取部门详情并保存至索引
+func (cli *wechatCli) departmentName(deptId int) (string, error) {
+	dir := cli.directory()
+	id := strconv.Itoa(deptId)
+	if name, ok := dir.Department(id); ok {
+		return name, nil
+	}
+	req := wechat.NewGetDepartmentReqBuilder(WxClient).DepartmentId(id).Build()
+	deptInfo, err := WxClient.Department.Get(req)
+	if err != nil {
+		return "", err
+	}
+	dir.AddDepartment(deptInfo.Name, id)
+	return deptInfo.Name, nil
+}
+
+// prefetchDepartments 用户所属部门有多个不在索引中时,通过一次部门列表请求填充索引,
+// 获取失败时由departmentName逐个获取
+func (cli *wechatCli) prefetchDepartments(users []*wechat.UserEntry) {
+	dir := cli.directory()
+	missing := map[int]bool{}
+	for _, user := range users {
+		for _, deptId := range user.Department {
+			if _, ok := dir.Department(strconv.Itoa(deptId)); !ok {
+				missing[deptId] = true
+			}
+		}
+	}
+	if len(missing) < 2 {
+		return
+	}
+	logger.Info(fmt.Sprintf("%d个部门名称不在索引中,正在获取部门列表...", len(missing)))
+	req := wechat.NewGetDepartmentListReqBuilder(WxClient).Build()
+	depts, err := WxClient.Department.GetList(req)
+	if err != nil {
+		return
+	}
+	cli.indexDepartments(depts)
+}
+
+// refreshDirectory 获取通讯录授权范围内的部门和用户填充索引
+func (cli *wechatCli) refreshDirectory(dir *directoryIndex) error {
+	if err := cli.checkContactMode(); err != nil {
+		return err
+	}
+	if !cli.hasAccessToken() {
+		return fmt.Errorf("请先执行run获取access_token")
+	}
+	logger.Info("正在获取部门列表...")
+	req := wechat.NewGetDepartmentListReqBuilder(WxClient).Build()
+	depts, err := WxClient.Department.GetList(req)
+	if err != nil {
+		return err
+	}
+	cli.indexDepartments(depts)
+	// 根部门为上级部门不在列表中的部门
+	ids := map[int]bool{}
+	for _, dept := range depts {
+		ids[dept.ID] = true
+	}
+	for _, dept := range depts {
+		if ids[dept.ParentId] || HttpCanceled {
+			continue
+		}
+		logger.Info(fmt.Sprintf("正在获取部门[%d]的用户...", dept.ID))
+		req := wechat.NewGetUsersByDepartmentIdReqBuilder(WxClient).DepartmentId(strconv.Itoa(dept.ID)).Fetch(true).Build()
+		users, err := WxClient.User.GetUsersByDepartmentId(req)
+		if err != nil {
+			return err
+		}
+		cli.indexUsers(users)
+	}
+	return nil
+}
+
 func (cli *wechatCli) showClientConfig() {
 	wxClientConfig := WxClient.GetConfig()
 	fmt.Printf("%s\n", strings.Repeat("=", 20))
@@ -608,16 +870,37 @@ func (cli *wechatCli) showClientConfig() {
 	} else {
 		fmt.Println(fmt.Sprintf("%-17s: %s", "proxy", *Proxy))
 	}
+	fmt.Println(fmt.Sprintf("%-17s: %s", "emit", emitSetting()))
+	fmt.Println(fmt.Sprintf("%-17s: %s", "trace", traceSetting()))
+	fmt.Println(fmt.Sprintf("%-17s: %s", "qps", qpsSetting(WxClient.Limiter())))
+	fmt.Println(fmt.Sprintf("%-17s: %s", "retry", retrySetting(WxClient.RetryPolicy())))
+	fmt.Println(fmt.Sprintf("%-17s: %s", "cache", directorySetting(cli.directory())))
 	fmt.Println(fmt.Sprintf("%-17s: %s", "domain", wechat.GetBaseDomain()))
-	if wxClientConfig.CorpId == nil {
-		fmt.Println(fmt.Sprintf("%-17s: %s", "corpid", ""))
-	} else {
-		fmt.Println(fmt.Sprintf("%-17s: %s", "corpid", *wxClientConfig.CorpId))
+	fmt.Println(fmt.Sprintf("%-17s: %s", "mode", wxClientConfig.AuthMode))
+	var values [][2]string
+	switch wxClientConfig.AuthMode {
+	case wechat.AuthModeSuite:
+		values = [][2]string{
+			{"suite_id", valueOf(wxClientConfig.SuiteId)},
+			{"suite_secret", valueOf(wxClientConfig.SuiteSecret)},
+			{"suite_ticket", valueOf(wxClientConfig.SuiteTicket)},
+			{"auth_corpid", valueOf(wxClientConfig.AuthCorpId)},
+			{"permanent_code", valueOf(wxClientConfig.PermanentCode)},
+			{"suite_token", valueOf(wxClientConfig.SuiteAccessToken)},
+		}
+	case wechat.AuthModeProvider:
+		values = [][2]string{
+			{"corpid", valueOf(wxClientConfig.CorpId)},
+			{"provider_secret", valueOf(wxClientConfig.ProviderSecret)},
+		}
+	default:
+		values = [][2]string{
+			{"corpid", valueOf(wxClientConfig.CorpId)},
+			{"corpsecret", valueOf(wxClientConfig.CorpSecret)},
+		}
 	}
-	if wxClientConfig.CorpSecret == nil {
-		fmt.Println(fmt.Sprintf("%-17s: %s", "corpsecret", ""))
-	} else {
-		fmt.Println(fmt.Sprintf("%-17s: %s", "corpsecret", *wxClientConfig.CorpSecret))
+	for _, v := range values {
+		fmt.Println(fmt.Sprintf("%-17s: %s", v[0], v[1]))
 	}
 	if wxClientConfig.AccessToken == nil {
 		fmt.Println(fmt.Sprintf("%-17s: %s", "access_token", ""))
@@ -627,6 +910,15 @@ func (cli *wechatCli) showClientConfig() {
 	fmt.Printf("%s\n", strings.Repeat("=", 20))
 }
 
+// callApi 使用access_token调用任意接口
+func (cli *wechatCli) callApi(method, path string, query [][2]string, body []byte) (*plugin.RawResponse, error) {
+	builder := wechat.NewCallReqBuilder(WxClient).Method(method).Path(path).Body(body)
+	for _, kv := range query {
+		builder.Query(kv[0], kv[1])
+	}
+	return WxClient.Call(builder.Build())
+}
+
 func (cli *wechatCli) setHelpV1(cmds ...*cobra.Command) {
 	for _, cmd := range cmds {
 		// 不自己打印会多一个空白行
@@ -16,6 +16,7 @@ require (
 	github.com/spf13/cobra v1.7.0
 	github.com/spf13/pflag v1.0.5
 	github.com/xuri/excelize/v2 v2.7.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
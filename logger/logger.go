@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"errors"
 	"fmt"
 	"github.com/mattn/go-colorable"
 	"os"
@@ -17,8 +18,17 @@ const (
 	blue  = "\033[34m"
 )
 
+// hinter 可给出解决办法的错误,如plugin.APIError
+type hinter interface {
+	Hint() string
+}
+
 func Error(err error) {
 	fmt.Fprintln(Writer, red+"[!] "+reset+err.Error())
+	var h hinter
+	if errors.As(err, &h) {
+		fmt.Fprintln(Writer, blue+"[*] "+reset+h.Hint())
+	}
 }
 
 func Success(str string) {
@@ -73,5 +83,5 @@ func FormatError(err error) error {
 	}
 	dir = filepath.ToSlash(dir)
 	funcName := runtime.FuncForPC(pc).Name()
-	return fmt.Errorf("%s %d line: %s", funcName, line, err)
+	return fmt.Errorf("%s %d line: %w", funcName, line, err)
 }
@@ -0,0 +1,123 @@
+package plugin
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	PlatformWechat = "wechat"
+	PlatformFeishu = "feishu"
+)
+
+// APIError 接口返回的错误,可通过errors.As获取错误码
+type APIError struct {
+	Platform   string // wechat或feishu
+	Code       int    // 企业微信的errcode或飞书的code
+	Msg        string // 企业微信的errmsg或飞书的msg
+	RequestID  string // 企业微信errmsg中的hint或飞书的X-Tt-Logid,反馈问题时使用
+	HTTPStatus int
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("from server - %d %s", e.Code, e.Msg)
+}
+
+// Hint 错误码说明及可能的解决办法,未收录的错误码返回官方查询地址
+func (e *APIError) Hint() string {
+	var hint string
+	if entry, ok := errCodes[e.Platform][e.Code]; ok {
+		hint = entry[0] + "," + entry[1]
+	} else if e.Platform == PlatformWechat {
+		hint = fmt.Sprintf("未收录的错误码,可在 https://open.work.weixin.qq.com/devtool/query?e=%d 查询", e.Code)
+	} else {
+		hint = fmt.Sprintf("未收录的错误码,可在 https://open.feishu.cn/search?q=%d 查询", e.Code)
+	}
+	if e.RequestID != "" {
+		hint += fmt.Sprintf(" (请求ID: %s)", e.RequestID)
+	}
+	return hint
+}
+
+// NewWechatError 根据企业微信的errcode和errmsg创建错误,response可以为nil
+func NewWechatError(code int, msg string, response *http.Response) *APIError {
+	e := newAPIError(PlatformWechat, code, msg, response)
+	// errmsg格式: invalid credential, hint: [1690000000_xxx], from ip: ..., more info at ...
+	if _, after, ok := strings.Cut(msg, "hint: ["); ok {
+		if id, _, ok := strings.Cut(after, "]"); ok {
+			e.RequestID = id
+		}
+	}
+	return e
+}
+
+// NewFeishuError 根据飞书的code和msg创建错误,response可以为nil
+func NewFeishuError(code int, msg string, response *http.Response) *APIError {
+	e := newAPIError(PlatformFeishu, code, msg, response)
+	if response != nil {
+		e.RequestID = response.Header.Get("X-Tt-Logid")
+	}
+	return e
+}
+
+// NewRawAPIError 根据原始响应创建错误,platform为wechat或feishu
+func NewRawAPIError(platform string, code int, msg string, response *RawResponse) *APIError {
+	var r *http.Response
+	if response != nil {
+		r = &http.Response{StatusCode: response.StatusCode, Header: response.Header}
+	}
+	if platform == PlatformWechat {
+		return NewWechatError(code, msg, r)
+	}
+	return NewFeishuError(code, msg, r)
+}
+
+func newAPIError(platform string, code int, msg string, response *http.Response) *APIError {
+	e := &APIError{Platform: platform, Code: code, Msg: msg}
+	if response != nil {
+		e.HTTPStatus = response.StatusCode
+	}
+	return e
+}
+
+// errCodes 常见错误码,值为[说明, 解决办法]
+var errCodes = map[string]map[int][2]string{
+	PlatformWechat: {
+		-1:     {"系统繁忙", "稍后重试"},
+		40001:  {"不合法的secret参数", "检查corpsecret是否正确,注意区分应用secret与通讯录同步secret"},
+		40013:  {"不合法的CorpID", "检查corpid是否正确,可在管理后台-我的企业-企业信息中查看"},
+		40014:  {"不合法的access_token", "access_token已失效或被篡改,使用run重新获取或检查手动设置的token"},
+		40056:  {"不合法的agentid", "检查应用的AgentId是否正确"},
+		41001:  {"缺少access_token参数", "先使用run获取access_token或使用set token手动设置"},
+		41004:  {"缺少secret参数", "使用set corpsecret设置secret"},
+		42001:  {"access_token已过期", "使用run重新获取access_token"},
+		45009:  {"接口调用超过限制", "降低调用频率,可使用set qps调整"},
+		45011:  {"API调用太频繁", "降低调用频率,可使用set qps调整"},
+		45033:  {"接口并发调用超过限制", "降低并发数,稍后重试"},
+		48002:  {"API接口无权限调用", "该secret对应的应用没有此接口权限,通讯录类接口需使用通讯录同步secret或为应用开启通讯录权限"},
+		60011:  {"指定的成员/部门/标签参数无权限", "目标不在应用的可见范围内,在管理后台调整应用可见范围"},
+		60020:  {"访问ip不在白名单之中", "将出口IP加入应用的企业可信IP,或通过已加白的代理访问"},
+		60111:  {"UserID不存在", "检查UserID是否正确,注意区分大小写"},
+		60123:  {"无效的部门id", "检查部门ID是否存在且在可见范围内"},
+		301002: {"无权限操作指定的应用", "检查secret对应的应用是否有权限访问该数据"},
+	},
+	PlatformFeishu: {
+		10003:    {"应用凭证参数不正确", "检查app_id和app_secret是否正确"},
+		10014:    {"app_secret不正确", "检查app_secret是否正确或已被重置"},
+		40004:    {"无部门权限", "目标部门不在应用的通讯录权限范围内,在开发者后台调整权限范围并发布版本"},
+		41050:    {"无用户权限", "目标用户不在应用的通讯录权限范围内,在开发者后台调整权限范围并发布版本"},
+		230002:   {"机器人不在群组中", "先将机器人添加到目标群组"},
+		230006:   {"应用未开启机器人能力", "在开发者后台开启机器人能力并发布版本"},
+		1254302:  {"无多维表格访问权限", "将应用添加为多维表格的协作者并授予编辑权限"},
+		99991400: {"请求频率超限", "降低调用频率,可使用set qps调整"},
+		99991401: {"访问IP被拒绝", "将出口IP加入应用的IP白名单"},
+		99991661: {"缺少access_token", "先使用run获取tenant_access_token"},
+		99991663: {"tenant_access_token无效", "token已失效,使用run重新获取"},
+		99991664: {"app_access_token无效", "token已失效,使用run重新获取"},
+		99991668: {"user_access_token无效", "检查user_access_token是否正确或已过期"},
+		99991672: {"应用缺少接口所需的权限", "在开发者后台为应用开通对应权限并发布版本,可使用info查看已授予的权限"},
+		99991679: {"用户未授权", "需要用户授权对应的权限"},
+		99992402: {"请求参数校验失败", "检查ID类型及参数格式是否正确"},
+	},
+}
@@ -0,0 +1,317 @@
+package feishu
+
+import (
+	"encoding/json"
+	"github.com/fasnow/ghttp"
+	"idebug/plugin"
+	"net/http"
+	"strconv"
+)
+
+type AuditInfoEntry struct {
+	EventId        string   `json:"event_id"`        // 日志ID
+	UniqueId       string   `json:"unique_id"`       // 唯一ID
+	EventName      string   `json:"event_name"`      // 行为名称
+	DepartmentIds  []string `json:"department_ids"`  // 用户所属部门的ID列表
+	EventModule    int      `json:"event_module"`    // 模块
+	OperatorType   int      `json:"operator_type"`   // 操作人类型,0:user,1:bot
+	OperatorValue  string   `json:"operator_value"`  // 操作人ID
+	EventTime      int64    `json:"event_time"`      // 事件时间(秒)
+	Ip             string   `json:"ip"`              // IP信息
+	OperatorApp    string   `json:"operator_app"`    // 第三方isvID
+	ThirdPartyApp  string   `json:"third_party_app"` // 第三方应用
+	OperatorTenant string   `json:"operator_tenant"` // 操作人企业编号
+	Objects        []struct {
+		ObjectType   string `json:"object_type"`  // 操作对象类型
+		ObjectValue  string `json:"object_value"` // 操作对象值
+		ObjectName   string `json:"object_name"`  // 操作对象名称
+		ObjectOwner  string `json:"object_owner"` // 操作对象的owner
+		ObjectDetail any    `json:"object_detail"`
+	} `json:"objects"` // 操作对象列表
+	Recipients []struct {
+		RecipientType   string `json:"recipient_type"`  // 接收者对象类型
+		RecipientValue  string `json:"recipient_value"` // 接收者对象值
+		RecipientDetail any    `json:"recipient_detail"`
+	} `json:"recipients"` // 接收者对象列表
+	OperatorDetail struct {
+		OperatorName  string `json:"operator_name"`  // 操作人名字
+		OperatorEmail string `json:"operator_email"` // 操作人邮箱
+	} `json:"operator_detail"` // 日志扩展信息
+	CommonDrawers any `json:"common_drawers"` // 日志扩展信息
+}
+
+type DeptStatsEntry struct {
+	Date                 string `json:"date"`                    // 日期
+	DepartmentId         string `json:"department_id"`           // 部门ID
+	DepartmentName       string `json:"department_name"`         // 部门名
+	DepartmentPath       string `json:"department_path"`         // 部门路径
+	TotalUserNum         int    `json:"total_user_num"`          // 部门总人数
+	ActiveUserNum        int    `json:"active_user_num"`         // 激活人数
+	ActiveUserRate       string `json:"active_user_rate"`        // 激活率
+	SuiteDau             int    `json:"suite_dau"`               // 活跃人数
+	SuiteActiveRate      string `json:"suite_active_rate"`       // 活跃率
+	NewUserNum           int    `json:"new_user_num"`            // 新用户数
+	NewActiveNum         int    `json:"new_active_num"`          // 新激活数
+	ResignUserNum        int    `json:"resign_user_num"`         // 离职人数
+	ImDau                int    `json:"im_dau"`                  // 消息活跃人数
+	SendMessengerUserNum int    `json:"send_messenger_user_num"` // 发送消息人数
+	SendMessengerNum     int    `json:"send_messenger_num"`      // 发送消息数
+	DocsDau              int    `json:"docs_dau"`                // 云文档活跃人数
+	CreateDocsUserNum    int    `json:"create_docs_user_num"`    // 创建文件人数
+	CreateDocsNum        int    `json:"create_docs_num"`         // 创建文件数
+	CalDau               int    `json:"cal_dau"`                 // 日历活跃人数
+	VcDau                int    `json:"vc_dau"`                  // 音视频活跃人数
+	VcDuration           int    `json:"vc_duration"`             // 会议时长(分钟)
+}
+
+type UserStatsEntry struct {
+	Date              string `json:"date"`                // 日期
+	UserId            string `json:"user_id"`             // 用户ID
+	UserName          string `json:"user_name"`           // 用户名
+	DepartmentName    string `json:"department_name"`     // 部门名
+	DepartmentPath    string `json:"department_path"`     // 部门路径
+	CreateTime        string `json:"create_time"`         // 添加时间
+	UserActiveFlag    int    `json:"user_active_flag"`    // 用户激活状态,0:未激活,1:已激活
+	RegisterTime      string `json:"register_time"`       // 激活时间
+	SuiteActiveFlag   int    `json:"suite_active_flag"`   // 用户活跃状态,0:无活跃,1:活跃
+	LastActiveTime    string `json:"last_active_time"`    // 最近活跃时间
+	ImActiveFlag      int    `json:"im_active_flag"`      // 用户消息活跃状态
+	SendMessengerNum  int    `json:"send_messenger_num"`  // 发送消息数
+	DocsActiveFlag    int    `json:"docs_active_flag"`    // 用户云文档活跃状态
+	CreateDocsNum     int    `json:"create_docs_num"`     // 创建文件数
+	CalActiveFlag     int    `json:"cal_active_flag"`     // 用户日历活跃状态
+	CreateCalNum      int    `json:"create_cal_num"`      // 创建日程数
+	VcActiveFlag      int    `json:"vc_active_flag"`      // 用户音视频活跃状态
+	VcDuration        int    `json:"vc_duration"`         // 会议时长(分钟)
+	ActiveOs          string `json:"active_os"`           // 活跃设备
+	CreateTaskNum     int    `json:"create_task_num"`     // 创建任务数
+	VcNum             int    `json:"vc_num"`              // 会议数
+	AppPackageType    string `json:"app_package_type"`    // 飞书的版本类型
+	OsName            string `json:"os_name"`             // 操作系统
+	EmailSendCount    string `json:"email_send_count"`    // 邮件发送数量
+	EmailReceiveCount string `json:"email_receive_count"` // 邮件接收数量
+}
+
+type ListAuditInfoReqBuilder struct {
+	req Req
+}
+
+type ListAuditInfoReq struct {
+	req Req
+}
+
+func NewListAuditInfoReqBuilder(f *Client) *ListAuditInfoReqBuilder {
+	builder := &ListAuditInfoReqBuilder{}
+	builder.req = Req{
+		PathParams:  &plugin.PathParams{},
+		QueryParams: &plugin.QueryParams{},
+		Client:      f,
+	}
+	return builder
+}
+
+func (builder *ListAuditInfoReqBuilder) UserIdType(t string) *ListAuditInfoReqBuilder {
+	builder.req.QueryParams.Set("user_id_type", t)
+	return builder
+}
+
+// Oldest 日志时间范围的起始时间(秒)
+func (builder *ListAuditInfoReqBuilder) Oldest(t int64) *ListAuditInfoReqBuilder {
+	builder.req.QueryParams.Set("oldest", strconv.FormatInt(t, 10))
+	return builder
+}
+
+// Latest 日志时间范围的结束时间(秒)
+func (builder *ListAuditInfoReqBuilder) Latest(t int64) *ListAuditInfoReqBuilder {
+	builder.req.QueryParams.Set("latest", strconv.FormatInt(t, 10))
+	return builder
+}
+
+// OperatorValue 操作人ID,设置后只查询该用户的日志
+func (builder *ListAuditInfoReqBuilder) OperatorValue(id string) *ListAuditInfoReqBuilder {
+	builder.req.QueryParams.Set("operator_type", "0")
+	builder.req.QueryParams.Set("operator_value", id)
+	return builder
+}
+
+// EventName 事件名称
+func (builder *ListAuditInfoReqBuilder) EventName(name string) *ListAuditInfoReqBuilder {
+	builder.req.QueryParams.Set("event_name", name)
+	return builder
+}
+
+// PageSize 分页大小,最大20
+func (builder *ListAuditInfoReqBuilder) PageSize(size int) *ListAuditInfoReqBuilder {
+	builder.req.QueryParams.Set("page_size", strconv.Itoa(size))
+	return builder
+}
+
+func (builder *ListAuditInfoReqBuilder) Build() *ListAuditInfoReq {
+	req := &ListAuditInfoReq{}
+	req.req = builder.req
+	return req
+}
+
+// AuditInfos 获取审计日志
+func (a *admin) AuditInfos(req *ListAuditInfoReq) ([]*AuditInfoEntry, error) {
+	var items []*AuditInfoEntry
+	err := req.req.Client.listAll(getAuditInfosUrl, req.req.QueryParams, func(data json.RawMessage) error {
+		var page []*AuditInfoEntry
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+		items = append(items, page...)
+		return nil
+	})
+	return items, err
+}
+
+type GetAdminStatsReqBuilder struct {
+	req Req
+}
+
+type GetAdminStatsReq struct {
+	req Req
+}
+
+func NewGetAdminStatsReqBuilder(f *Client) *GetAdminStatsReqBuilder {
+	builder := &GetAdminStatsReqBuilder{}
+	builder.req = Req{
+		PathParams:  &plugin.PathParams{},
+		QueryParams: &plugin.QueryParams{},
+		Client:      f,
+	}
+	return builder
+}
+
+func (builder *GetAdminStatsReqBuilder) UserIdType(t string) *GetAdminStatsReqBuilder {
+	builder.req.QueryParams.Set("user_id_type", t)
+	return builder
+}
+
+func (builder *GetAdminStatsReqBuilder) DepartmentIdType(t string) *GetAdminStatsReqBuilder {
+	builder.req.QueryParams.Set("department_id_type", t)
+	return builder
+}
+
+// StartDate 起始日期(包含),格式为yyyy-mm-dd
+func (builder *GetAdminStatsReqBuilder) StartDate(date string) *GetAdminStatsReqBuilder {
+	builder.req.QueryParams.Set("start_date", date)
+	return builder
+}
+
+// EndDate 终止日期(包含),格式为yyyy-mm-dd,与起始日期间隔不超过91天
+func (builder *GetAdminStatsReqBuilder) EndDate(date string) *GetAdminStatsReqBuilder {
+	builder.req.QueryParams.Set("end_date", date)
+	return builder
+}
+
+func (builder *GetAdminStatsReqBuilder) DepartmentId(id string) *GetAdminStatsReqBuilder {
+	builder.req.QueryParams.Set("department_id", id)
+	return builder
+}
+
+// ContainsChildDept 是否包含子部门,仅部门维度有效
+func (builder *GetAdminStatsReqBuilder) ContainsChildDept(contains bool) *GetAdminStatsReqBuilder {
+	builder.req.QueryParams.Set("contains_child_dept", strconv.FormatBool(contains))
+	return builder
+}
+
+// UserId 用户ID,仅用户维度有效
+func (builder *GetAdminStatsReqBuilder) UserId(id string) *GetAdminStatsReqBuilder {
+	builder.req.QueryParams.Set("user_id", id)
+	return builder
+}
+
+// PageSize 分页大小,最大100
+func (builder *GetAdminStatsReqBuilder) PageSize(size int) *GetAdminStatsReqBuilder {
+	builder.req.QueryParams.Set("page_size", strconv.Itoa(size))
+	return builder
+}
+
+func (builder *GetAdminStatsReqBuilder) Build() *GetAdminStatsReq {
+	req := &GetAdminStatsReq{}
+	req.req = builder.req
+	return req
+}
+
+// DeptStats 获取部门维度的用户活跃和功能使用数据
+func (a *admin) DeptStats(req *GetAdminStatsReq) ([]*DeptStatsEntry, error) {
+	var items []*DeptStatsEntry
+	err := req.req.Client.listAll(getAdminDeptStatsUrl, req.req.QueryParams, func(data json.RawMessage) error {
+		var page []*DeptStatsEntry
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+		items = append(items, page...)
+		return nil
+	})
+	return items, err
+}
+
+// UserStats 获取用户维度的用户活跃和功能使用数据
+func (a *admin) UserStats(req *GetAdminStatsReq) ([]*UserStatsEntry, error) {
+	var items []*UserStatsEntry
+	err := req.req.Client.listAll(getAdminUserStatsUrl, req.req.QueryParams, func(data json.RawMessage) error {
+		var page []*UserStatsEntry
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+		items = append(items, page...)
+		return nil
+	})
+	return items, err
+}
+
+// listAll 按page_token翻页获取所有数据,每页的data.items交给handle处理
+func (client *Client) listAll(url string, params *plugin.QueryParams, handle func(items json.RawMessage) error) error {
+	var pageToken string
+	for {
+		query := params.Encode()
+		if pageToken != "" {
+			query += "&page_token=" + pageToken
+		}
+		request, err := http.NewRequest("GET", url+"?"+query, nil)
+		if err != nil {
+			return err
+		}
+		token, err := client.autoGetTenantAccessToken()
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+		response, err := client.http.Do(request)
+		if err != nil {
+			return err
+		}
+		body, err := ghttp.GetResponseBody(response.Body)
+		if err != nil {
+			return err
+		}
+		var tmp struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+			Data struct {
+				HasMore   bool            `json:"has_more"`
+				PageToken string          `json:"page_token"`
+				Items     json.RawMessage `json:"items"`
+			} `json:"data"`
+		}
+		err = json.Unmarshal(body, &tmp)
+		if err != nil {
+			return err
+		}
+		if tmp.Code != 0 {
+			return plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
+		}
+		if len(tmp.Data.Items) > 0 {
+			if err = handle(tmp.Data.Items); err != nil {
+				return err
+			}
+		}
+		if !tmp.Data.HasMore || tmp.Data.PageToken == "" {
+			return nil
+		}
+		pageToken = tmp.Data.PageToken
+	}
+}
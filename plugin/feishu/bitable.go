@@ -0,0 +1,277 @@
+package feishu
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"idebug/plugin"
+	"strconv"
+	"strings"
+)
+
+// bitableTextFieldType 多维表格多行文本字段类型
+const bitableTextFieldType = 1
+
+// BitableMaxBatchSize 多维表格批量新增和更新记录时单次最多500条
+const BitableMaxBatchSize = 500
+
+type BitableTableEntry struct {
+	TableId  string `json:"table_id"` // 数据表ID
+	Revision int    `json:"revision"` // 数据表版本号
+	Name     string `json:"name"`     // 数据表名称
+}
+
+type BitableFieldEntry struct {
+	FieldId   string `json:"field_id"`   // 字段ID
+	FieldName string `json:"field_name"` // 字段名称
+	Type      int    `json:"type"`       // 字段类型,1为多行文本
+	IsPrimary bool   `json:"is_primary"` // 是否为索引列
+}
+
+type BitableRecordEntry struct {
+	RecordId string         `json:"record_id,omitempty"` // 记录ID
+	Fields   map[string]any `json:"fields"`              // 记录字段,键为字段名称
+}
+
+// Text 返回文本字段的值,兼容字符串和富文本片段数组两种返回格式
+func (r *BitableRecordEntry) Text(field string) string {
+	switch value := r.Fields[field].(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case []any:
+		var texts []string
+		for _, segment := range value {
+			if m, ok := segment.(map[string]any); ok {
+				if text, ok := m["text"].(string); ok {
+					texts = append(texts, text)
+				}
+			}
+		}
+		return strings.Join(texts, "")
+	default:
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+}
+
+type BitableReqBuilder struct {
+	req  Req
+	body map[string]any
+}
+
+type BitableReq struct {
+	req Req
+}
+
+func NewBitableReqBuilder(f *Client) *BitableReqBuilder {
+	builder := &BitableReqBuilder{body: map[string]any{}}
+	builder.req = Req{
+		PathParams:  &plugin.PathParams{},
+		QueryParams: &plugin.QueryParams{},
+		Client:      f,
+	}
+	return builder
+}
+
+// AppToken 多维表格的app_token
+func (builder *BitableReqBuilder) AppToken(token string) *BitableReqBuilder {
+	builder.req.PathParams.Set(":app_token", token)
+	return builder
+}
+
+// TableId 数据表ID
+func (builder *BitableReqBuilder) TableId(id string) *BitableReqBuilder {
+	builder.req.PathParams.Set(":table_id", id)
+	return builder
+}
+
+// TableName 新建数据表时的名称,与Fields一起使用
+func (builder *BitableReqBuilder) TableName(name string) *BitableReqBuilder {
+	builder.body["table_name"] = name
+	return builder
+}
+
+// Fields 新建数据表时的字段名称,均为多行文本,第一个字段为索引列
+func (builder *BitableReqBuilder) Fields(names []string) *BitableReqBuilder {
+	builder.body["field_names"] = names
+	return builder
+}
+
+// FieldName 新增字段的名称,字段类型为多行文本
+func (builder *BitableReqBuilder) FieldName(name string) *BitableReqBuilder {
+	builder.body["field_name"] = name
+	builder.body["type"] = bitableTextFieldType
+	return builder
+}
+
+// Records 批量新增或者更新的记录,更新时RecordId不能为空,单次最多500条
+func (builder *BitableReqBuilder) Records(records []*BitableRecordEntry) *BitableReqBuilder {
+	builder.body["records"] = records
+	return builder
+}
+
+// PageSize 分页大小,记录最大500,数据表和字段最大100
+func (builder *BitableReqBuilder) PageSize(size int) *BitableReqBuilder {
+	builder.req.QueryParams.Set("page_size", strconv.Itoa(size))
+	return builder
+}
+
+func (builder *BitableReqBuilder) Build() *BitableReq {
+	req := &BitableReq{}
+	req.req = builder.req
+	req.req.Body = builder.body
+	return req
+}
+
+func (req *BitableReq) url(raw string) (string, error) {
+	appToken := req.req.PathParams.Get(":app_token")
+	if appToken == "" {
+		return "", errors.New("app_token不能为空")
+	}
+	raw = strings.Replace(raw, ":app_token", appToken, 1)
+	if strings.Contains(raw, ":table_id") {
+		tableId := req.req.PathParams.Get(":table_id")
+		if tableId == "" {
+			return "", errors.New("数据表ID不能为空")
+		}
+		raw = strings.Replace(raw, ":table_id", tableId, 1)
+	}
+	return raw, nil
+}
+
+// ListTables 获取多维表格的所有数据表
+func (b *bitable) ListTables(req *BitableReq) ([]*BitableTableEntry, error) {
+	url, err := req.url(bitableTablesUrl)
+	if err != nil {
+		return nil, err
+	}
+	var tables []*BitableTableEntry
+	err = req.req.Client.listAll(url, req.req.QueryParams, func(data json.RawMessage) error {
+		var page []*BitableTableEntry
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+		tables = append(tables, page...)
+		return nil
+	})
+	return tables, err
+}
+
+// CreateTable 新建数据表,返回数据表ID
+func (b *bitable) CreateTable(req *BitableReq) (string, error) {
+	url, err := req.url(bitableTablesUrl)
+	if err != nil {
+		return "", err
+	}
+	body := req.req.Body.(map[string]any)
+	name, _ := body["table_name"].(string)
+	if name == "" {
+		return "", errors.New("数据表名称不能为空")
+	}
+	var fields []map[string]any
+	names, _ := body["field_names"].([]string)
+	for _, fieldName := range names {
+		fields = append(fields, map[string]any{"field_name": fieldName, "type": bitableTextFieldType})
+	}
+	var tmp struct {
+		Data struct {
+			TableId string `json:"table_id"`
+		} `json:"data"`
+	}
+	table := map[string]any{"name": name, "default_view_name": name, "fields": fields}
+	if err = req.req.Client.postWithTenantAccessToken(url, map[string]any{"table": table}, &tmp); err != nil {
+		return "", err
+	}
+	return tmp.Data.TableId, nil
+}
+
+// ListFields 获取数据表的所有字段
+func (b *bitable) ListFields(req *BitableReq) ([]*BitableFieldEntry, error) {
+	url, err := req.url(bitableFieldsUrl)
+	if err != nil {
+		return nil, err
+	}
+	var fields []*BitableFieldEntry
+	err = req.req.Client.listAll(url, req.req.QueryParams, func(data json.RawMessage) error {
+		var page []*BitableFieldEntry
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+		fields = append(fields, page...)
+		return nil
+	})
+	return fields, err
+}
+
+// CreateField 新增多行文本字段
+func (b *bitable) CreateField(req *BitableReq) (*BitableFieldEntry, error) {
+	url, err := req.url(bitableFieldsUrl)
+	if err != nil {
+		return nil, err
+	}
+	var tmp struct {
+		Data struct {
+			Field *BitableFieldEntry `json:"field"`
+		} `json:"data"`
+	}
+	if err = req.req.Client.postWithTenantAccessToken(url, req.req.Body, &tmp); err != nil {
+		return nil, err
+	}
+	return tmp.Data.Field, nil
+}
+
+// ListRecords 获取数据表的所有记录
+func (b *bitable) ListRecords(req *BitableReq) ([]*BitableRecordEntry, error) {
+	url, err := req.url(bitableRecordsUrl)
+	if err != nil {
+		return nil, err
+	}
+	var records []*BitableRecordEntry
+	err = req.req.Client.listAll(url, req.req.QueryParams, func(data json.RawMessage) error {
+		var page []*BitableRecordEntry
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+		records = append(records, page...)
+		return nil
+	})
+	return records, err
+}
+
+// BatchCreateRecords 批量新增记录,单次最多500条
+func (b *bitable) BatchCreateRecords(req *BitableReq) ([]*BitableRecordEntry, error) {
+	return b.batchRecords(bitableBatchCreateRecordsUrl, req)
+}
+
+// BatchUpdateRecords 批量更新记录,单次最多500条
+func (b *bitable) BatchUpdateRecords(req *BitableReq) ([]*BitableRecordEntry, error) {
+	return b.batchRecords(bitableBatchUpdateRecordsUrl, req)
+}
+
+func (b *bitable) batchRecords(raw string, req *BitableReq) ([]*BitableRecordEntry, error) {
+	url, err := req.url(raw)
+	if err != nil {
+		return nil, err
+	}
+	body := req.req.Body.(map[string]any)
+	records, _ := body["records"].([]*BitableRecordEntry)
+	if len(records) == 0 {
+		return nil, nil
+	}
+	if len(records) > BitableMaxBatchSize {
+		return nil, fmt.Errorf("单次最多%d条记录", BitableMaxBatchSize)
+	}
+	var tmp struct {
+		Data struct {
+			Records []*BitableRecordEntry `json:"records"`
+		} `json:"data"`
+	}
+	if err = req.req.Client.postWithTenantAccessToken(url, map[string]any{"records": records}, &tmp); err != nil {
+		return nil, err
+	}
+	return tmp.Data.Records, nil
+}
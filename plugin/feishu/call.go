@@ -0,0 +1,137 @@
+package feishu
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/fasnow/ghttp"
+	"idebug/plugin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type CallReqBuilder struct {
+	req  Req
+	body []byte
+}
+
+type CallReq struct {
+	req  Req
+	body []byte
+}
+
+func NewCallReqBuilder(f *Client) *CallReqBuilder {
+	builder := &CallReqBuilder{}
+	builder.req = Req{
+		PathParams:  &plugin.PathParams{},
+		QueryParams: &plugin.QueryParams{},
+		Client:      f,
+	}
+	return builder
+}
+
+// Method 请求方法,默认GET
+func (builder *CallReqBuilder) Method(method string) *CallReqBuilder {
+	builder.req.HttpMethod = strings.ToUpper(method)
+	return builder
+}
+
+// Path 接口路径,如/contact/v3/scopes,完整URL必须属于接口域名,不以http开头时会拼接至https://open.feishu.cn/open-apis
+func (builder *CallReqBuilder) Path(path string) *CallReqBuilder {
+	builder.req.ApiPath = path
+	return builder
+}
+
+// Query 追加查询参数
+func (builder *CallReqBuilder) Query(key, value string) *CallReqBuilder {
+	builder.req.QueryParams.Add(key, value)
+	return builder
+}
+
+// Body 原始请求体,按JSON发送
+func (builder *CallReqBuilder) Body(body []byte) *CallReqBuilder {
+	builder.body = body
+	return builder
+}
+
+func (builder *CallReqBuilder) Build() *CallReq {
+	req := &CallReq{}
+	req.req = builder.req
+	req.body = builder.body
+	return req
+}
+
+// GetBaseDomain 返回接口域名
+func GetBaseDomain() string {
+	return baseDomain
+}
+
+// ResolveUrl 将接口路径解析为完整的接口地址,完整URL必须与接口域名一致,避免凭证发送至其它主机
+func ResolveUrl(path string) (string, error) {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		if !plugin.SameOrigin(path, baseDomain) {
+			return "", fmt.Errorf("只能调用接口域名%s下的接口: %s", baseDomain, path)
+		}
+		return path, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if !strings.HasPrefix(path, "/open-apis/") {
+		path = "/open-apis" + path
+	}
+	return baseDomain + path, nil
+}
+
+// Call 使用tenant_access_token调用任意接口,返回原始响应,不校验响应中的code
+func (client *Client) Call(req *CallReq) (*plugin.RawResponse, error) {
+	if req.req.ApiPath == "" {
+		return nil, errors.New("接口路径不能为空")
+	}
+	method := req.req.HttpMethod
+	if method == "" {
+		method = "GET"
+	}
+	url, err := ResolveUrl(req.req.ApiPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(*req.req.QueryParams) > 0 {
+		if strings.Contains(url, "?") {
+			url += "&" + req.req.QueryParams.Encode()
+		} else {
+			url += "?" + req.req.QueryParams.Encode()
+		}
+	}
+	request, err := http.NewRequest(method, url, bytes.NewReader(req.body))
+	if err != nil {
+		return nil, err
+	}
+	if len(req.body) > 0 {
+		request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	token, err := client.autoGetTenantAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	start := time.Now()
+	response, err := client.http.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ghttp.GetResponseBody(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.RawResponse{
+		Method:     method,
+		Url:        url,
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Header:     response.Header,
+		Body:       body,
+		Duration:   time.Since(start),
+	}, nil
+}
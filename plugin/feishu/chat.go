@@ -0,0 +1,302 @@
+package feishu
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/fasnow/ghttp"
+	"idebug/plugin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type ChatEntry struct {
+	ChatId      string `json:"chat_id"`       // 群ID
+	Avatar      string `json:"avatar"`        // 群头像URL
+	Name        string `json:"name"`          // 群名称
+	Description string `json:"description"`   // 群描述
+	OwnerId     string `json:"owner_id"`      // 群主ID,ID值与查询参数中的user_id_type对应,群主为机器人时为空
+	OwnerIdType string `json:"owner_id_type"` // 群主ID对应的ID类型
+	External    bool   `json:"external"`      // 是否是外部群
+	TenantKey   string `json:"tenant_key"`    // 租户Key
+	ChatStatus  string `json:"chat_status"`   // 群状态,normal:正常,dissolved:已解散,dissolved_save:已解散但保留
+}
+
+type ChatInfo struct {
+	ChatEntry
+	ChatMode               string `json:"chat_mode"`                // 群模式,group:群组,topic:话题,p2p:单聊
+	ChatType               string `json:"chat_type"`                // 群类型,private:私有群,public:公开群
+	ChatTag                string `json:"chat_tag"`                 // 群标签
+	AddMemberPermission    string `json:"add_member_permission"`    // 拉用户或机器人入群权限
+	ShareCardPermission    string `json:"share_card_permission"`    // 群分享权限
+	AtAllPermission        string `json:"at_all_permission"`        // at所有人权限
+	EditPermission         string `json:"edit_permission"`          // 群编辑权限
+	MembershipApproval     string `json:"membership_approval"`      // 加群审批
+	ModerationPermission   string `json:"moderation_permission"`    // 发言权限
+	JoinMessageVisibility  string `json:"join_message_visibility"`  // 成员入群提示
+	LeaveMessageVisibility string `json:"leave_message_visibility"` // 成员退群提示
+	UserCount              string `json:"user_count"`               // 用户数量
+	BotCount               string `json:"bot_count"`                // 机器人数量
+}
+
+type ChatMemberEntry struct {
+	MemberIdType string `json:"member_id_type"` // 成员ID类型
+	MemberId     string `json:"member_id"`      // 成员ID
+	Name         string `json:"name"`           // 成员名称
+	TenantKey    string `json:"tenant_key"`     // 租户Key,外部成员的租户Key与本企业不同
+}
+
+type ListChatReqBuilder struct {
+	req Req
+}
+
+type ListChatReq struct {
+	req Req
+}
+
+func NewListChatReqBuilder(f *Client) *ListChatReqBuilder {
+	builder := &ListChatReqBuilder{}
+	builder.req = Req{
+		PathParams:  &plugin.PathParams{},
+		QueryParams: &plugin.QueryParams{},
+		Client:      f,
+	}
+	return builder
+}
+
+func (builder *ListChatReqBuilder) UserIdType(t string) *ListChatReqBuilder {
+	builder.req.QueryParams.Set("user_id_type", t)
+	return builder
+}
+
+// PageSize 分页大小,最大100
+func (builder *ListChatReqBuilder) PageSize(size int) *ListChatReqBuilder {
+	builder.req.QueryParams.Set("page_size", strconv.Itoa(size))
+	return builder
+}
+
+func (builder *ListChatReqBuilder) Build() *ListChatReq {
+	req := &ListChatReq{}
+	req.req = builder.req
+	return req
+}
+
+// List 获取机器人所在的群列表
+func (c *chat) List(req *ListChatReq) ([]*ChatEntry, error) {
+	var items []*ChatEntry
+	var pageToken string
+	for {
+		params := req.req.QueryParams.Encode()
+		if pageToken != "" {
+			params += "&page_token=" + pageToken
+		}
+		request, err := http.NewRequest("GET", getChatListUrl+"?"+params, nil)
+		if err != nil {
+			return nil, err
+		}
+		token, err := req.req.Client.autoGetTenantAccessToken()
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+		response, err := req.req.Client.http.Do(request)
+		if err != nil {
+			return nil, err
+		}
+		body, err := ghttp.GetResponseBody(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		var tmp struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+			Data struct {
+				HasMore   bool         `json:"has_more"`
+				PageToken string       `json:"page_token"`
+				Items     []*ChatEntry `json:"items"`
+			} `json:"data"`
+		}
+		err = json.Unmarshal(body, &tmp)
+		if err != nil {
+			return nil, err
+		}
+		if tmp.Code != 0 {
+			return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
+		}
+		items = append(items, tmp.Data.Items...)
+		if !tmp.Data.HasMore {
+			return items, nil
+		}
+		pageToken = tmp.Data.PageToken
+	}
+}
+
+type GetChatReqBuilder struct {
+	req Req
+}
+
+type GetChatReq struct {
+	req Req
+}
+
+func NewGetChatReqBuilder(f *Client) *GetChatReqBuilder {
+	builder := &GetChatReqBuilder{}
+	builder.req = Req{
+		PathParams:  &plugin.PathParams{},
+		QueryParams: &plugin.QueryParams{},
+		Client:      f,
+	}
+	return builder
+}
+
+func (builder *GetChatReqBuilder) ChatId(id string) *GetChatReqBuilder {
+	builder.req.PathParams.Set(":chat_id", id)
+	return builder
+}
+
+func (builder *GetChatReqBuilder) UserIdType(t string) *GetChatReqBuilder {
+	builder.req.QueryParams.Set("user_id_type", t)
+	return builder
+}
+
+func (builder *GetChatReqBuilder) Build() *GetChatReq {
+	req := &GetChatReq{}
+	req.req = builder.req
+	return req
+}
+
+// Get 获取群信息
+func (c *chat) Get(req *GetChatReq) (*ChatInfo, error) {
+	id := strings.TrimSpace(req.req.PathParams.Get(":chat_id"))
+	if id == "" {
+		return nil, errors.New("群ID不能为空")
+	}
+	request, err := http.NewRequest("GET", strings.Replace(getChatUrl, ":chat_id", id, 1)+"?"+req.req.QueryParams.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	token, err := req.req.Client.autoGetTenantAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	response, err := req.req.Client.http.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ghttp.GetResponseBody(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	var tmp struct {
+		Code int       `json:"code"`
+		Msg  string    `json:"msg"`
+		Data *ChatInfo `json:"data"`
+	}
+	err = json.Unmarshal(body, &tmp)
+	if err != nil {
+		return nil, err
+	}
+	if tmp.Code != 0 {
+		return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
+	}
+	if tmp.Data != nil {
+		tmp.Data.ChatId = id
+	}
+	return tmp.Data, nil
+}
+
+type GetChatMembersReqBuilder struct {
+	req Req
+}
+
+type GetChatMembersReq struct {
+	req Req
+}
+
+func NewGetChatMembersReqBuilder(f *Client) *GetChatMembersReqBuilder {
+	builder := &GetChatMembersReqBuilder{}
+	builder.req = Req{
+		PathParams:  &plugin.PathParams{},
+		QueryParams: &plugin.QueryParams{},
+		Client:      f,
+	}
+	return builder
+}
+
+func (builder *GetChatMembersReqBuilder) ChatId(id string) *GetChatMembersReqBuilder {
+	builder.req.PathParams.Set(":chat_id", id)
+	return builder
+}
+
+// MemberIdType 成员ID类型,可选值user_id、union_id、open_id
+func (builder *GetChatMembersReqBuilder) MemberIdType(t string) *GetChatMembersReqBuilder {
+	builder.req.QueryParams.Set("member_id_type", t)
+	return builder
+}
+
+// PageSize 分页大小,最大100
+func (builder *GetChatMembersReqBuilder) PageSize(size int) *GetChatMembersReqBuilder {
+	builder.req.QueryParams.Set("page_size", strconv.Itoa(size))
+	return builder
+}
+
+func (builder *GetChatMembersReqBuilder) Build() *GetChatMembersReq {
+	req := &GetChatMembersReq{}
+	req.req = builder.req
+	return req
+}
+
+// Members 获取群成员列表,不包含机器人
+func (c *chat) Members(req *GetChatMembersReq) ([]*ChatMemberEntry, error) {
+	id := strings.TrimSpace(req.req.PathParams.Get(":chat_id"))
+	if id == "" {
+		return nil, errors.New("群ID不能为空")
+	}
+	var items []*ChatMemberEntry
+	var pageToken string
+	for {
+		params := req.req.QueryParams.Encode()
+		if pageToken != "" {
+			params += "&page_token=" + pageToken
+		}
+		request, err := http.NewRequest("GET", strings.Replace(getChatMembersUrl, ":chat_id", id, 1)+"?"+params, nil)
+		if err != nil {
+			return nil, err
+		}
+		token, err := req.req.Client.autoGetTenantAccessToken()
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+		response, err := req.req.Client.http.Do(request)
+		if err != nil {
+			return nil, err
+		}
+		body, err := ghttp.GetResponseBody(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		var tmp struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+			Data struct {
+				HasMore   bool               `json:"has_more"`
+				PageToken string             `json:"page_token"`
+				Items     []*ChatMemberEntry `json:"items"`
+			} `json:"data"`
+		}
+		err = json.Unmarshal(body, &tmp)
+		if err != nil {
+			return nil, err
+		}
+		if tmp.Code != 0 {
+			return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
+		}
+		items = append(items, tmp.Data.Items...)
+		if !tmp.Data.HasMore {
+			return items, nil
+		}
+		pageToken = tmp.Data.PageToken
+	}
+}
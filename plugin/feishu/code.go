@@ -0,0 +1,154 @@
+package feishu
+
+import (
+	"strconv"
+	"strings"
+)
+
+// 内置人员类型,自定义人员类型需通过 GetEmployeeTypeNames 获取
+var employeeTypeNames = map[int]string{
+	1: "正式员工",
+	2: "实习生",
+	3: "外包",
+	4: "劳务",
+	5: "顾问",
+}
+
+var genderNames = map[int]string{
+	0: "保密",
+	1: "男",
+	2: "女",
+	3: "其他",
+}
+
+// 国家或地区Code,参见 [国家/地区码表](https://open.feishu.cn/document/uAjLw4CM/ukTMukTMukTM/reference/contact-v3/user/country-code-description)
+var countryNames = map[string]string{
+	"CN": "中国大陆",
+	"HK": "中国香港",
+	"MO": "中国澳门",
+	"TW": "中国台湾",
+	"US": "美国",
+	"CA": "加拿大",
+	"MX": "墨西哥",
+	"BR": "巴西",
+	"AR": "阿根廷",
+	"CL": "智利",
+	"GB": "英国",
+	"IE": "爱尔兰",
+	"FR": "法国",
+	"DE": "德国",
+	"IT": "意大利",
+	"ES": "西班牙",
+	"PT": "葡萄牙",
+	"NL": "荷兰",
+	"BE": "比利时",
+	"CH": "瑞士",
+	"AT": "奥地利",
+	"SE": "瑞典",
+	"NO": "挪威",
+	"DK": "丹麦",
+	"FI": "芬兰",
+	"PL": "波兰",
+	"RU": "俄罗斯",
+	"UA": "乌克兰",
+	"TR": "土耳其",
+	"JP": "日本",
+	"KR": "韩国",
+	"SG": "新加坡",
+	"MY": "马来西亚",
+	"TH": "泰国",
+	"VN": "越南",
+	"PH": "菲律宾",
+	"ID": "印度尼西亚",
+	"IN": "印度",
+	"PK": "巴基斯坦",
+	"BD": "孟加拉国",
+	"AE": "阿联酋",
+	"SA": "沙特阿拉伯",
+	"IL": "以色列",
+	"EG": "埃及",
+	"ZA": "南非",
+	"NG": "尼日利亚",
+	"KE": "肯尼亚",
+	"AU": "澳大利亚",
+	"NZ": "新西兰",
+}
+
+// 常见城市的英文或拼音名称
+var cityNames = map[string]string{
+	"beijing":   "北京",
+	"shanghai":  "上海",
+	"guangzhou": "广州",
+	"shenzhen":  "深圳",
+	"hangzhou":  "杭州",
+	"nanjing":   "南京",
+	"suzhou":    "苏州",
+	"chengdu":   "成都",
+	"chongqing": "重庆",
+	"wuhan":     "武汉",
+	"xian":      "西安",
+	"xi'an":     "西安",
+	"tianjin":   "天津",
+	"changsha":  "长沙",
+	"zhengzhou": "郑州",
+	"xiamen":    "厦门",
+	"qingdao":   "青岛",
+	"jinan":     "济南",
+	"hefei":     "合肥",
+	"fuzhou":    "福州",
+	"dalian":    "大连",
+	"shenyang":  "沈阳",
+	"kunming":   "昆明",
+	"zhuhai":    "珠海",
+	"dongguan":  "东莞",
+	"hong kong": "香港",
+	"hongkong":  "香港",
+	"macau":     "澳门",
+	"taipei":    "台北",
+	"singapore": "新加坡",
+	"tokyo":     "东京",
+	"seoul":     "首尔",
+	"london":    "伦敦",
+	"paris":     "巴黎",
+	"new york":  "纽约",
+	"san jose":  "圣何塞",
+	"seattle":   "西雅图",
+}
+
+// GenderName 返回性别名称,未知值返回空
+func GenderName(gender int) string {
+	if name, ok := genderNames[gender]; ok {
+		return name
+	}
+	return ""
+}
+
+// EmployeeTypeName 返回人员类型名称,names为租户的人员类型(可为nil),未知值返回编号
+func EmployeeTypeName(employeeType int, names map[int]string) string {
+	if name, ok := names[employeeType]; ok && name != "" {
+		return name
+	}
+	if name, ok := employeeTypeNames[employeeType]; ok {
+		return name
+	}
+	if employeeType == 0 {
+		return ""
+	}
+	return strconv.Itoa(employeeType)
+}
+
+// CountryName 返回国家或地区名称,未知Code原样返回
+func CountryName(code string) string {
+	if name, ok := countryNames[strings.ToUpper(strings.TrimSpace(code))]; ok {
+		return name
+	}
+	return code
+}
+
+// CityName 返回城市中文名称,未知城市原样返回
+func CityName(city string) string {
+	if name, ok := cityNames[strings.ToLower(strings.TrimSpace(city))]; ok {
+		return name
+	}
+	return city
+}
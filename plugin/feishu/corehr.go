@@ -0,0 +1,252 @@
+package feishu
+
+import (
+	"encoding/json"
+	"github.com/fasnow/ghttp"
+	"idebug/plugin"
+	"idebug/utils"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CoreHRRecord 飞书人事记录,字段较多且随租户配置变化,以原始JSON对象保存
+type CoreHRRecord map[string]any
+
+// Get 根据以点分隔的路径获取字段值,如person_info.name
+func (r CoreHRRecord) Get(path string) any {
+	var cur any = map[string]any(r)
+	for _, key := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[key]
+	}
+	return cur
+}
+
+// String 根据路径获取字段值并转为字符串
+func (r CoreHRRecord) String(path string) string {
+	return corehrValueString(r.Get(path))
+}
+
+// Flatten 将嵌套对象展开为以点分隔的键,数组序列化为JSON字符串
+func (r CoreHRRecord) Flatten() map[string]string {
+	result := map[string]string{}
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		if m, ok := v.(map[string]any); ok {
+			for key, value := range m {
+				if prefix != "" {
+					key = prefix + "." + key
+				}
+				walk(key, value)
+			}
+			return
+		}
+		result[prefix] = corehrValueString(v)
+	}
+	walk("", map[string]any(r))
+	return result
+}
+
+// CoreHRColumns 返回所有记录展开后的键,按字母顺序排序
+func CoreHRColumns(records []CoreHRRecord) []string {
+	set := map[string]bool{}
+	for _, record := range records {
+		for key := range record.Flatten() {
+			set[key] = true
+		}
+	}
+	var columns []string
+	for key := range set {
+		columns = append(columns, key)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+func corehrValueString(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	default:
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+}
+
+type CoreHRReqBuilder struct {
+	req  Req
+	body map[string]any
+}
+
+type CoreHRReq struct {
+	req Req
+}
+
+func NewCoreHRReqBuilder(f *Client) *CoreHRReqBuilder {
+	builder := &CoreHRReqBuilder{body: map[string]any{}}
+	builder.req = Req{
+		PathParams:  &plugin.PathParams{},
+		QueryParams: &plugin.QueryParams{},
+		Client:      f,
+	}
+	return builder
+}
+
+// UserIdType 用户ID类型,可选值open_id、union_id、user_id、people_corehr_id
+func (builder *CoreHRReqBuilder) UserIdType(t string) *CoreHRReqBuilder {
+	builder.req.QueryParams.Set("user_id_type", t)
+	return builder
+}
+
+// DepartmentIdType 部门ID类型,可选值open_department_id、department_id、people_corehr_department_id
+func (builder *CoreHRReqBuilder) DepartmentIdType(t string) *CoreHRReqBuilder {
+	builder.req.QueryParams.Set("department_id_type", t)
+	return builder
+}
+
+// PageSize 分页大小,最大100
+func (builder *CoreHRReqBuilder) PageSize(size int) *CoreHRReqBuilder {
+	builder.req.QueryParams.Set("page_size", strconv.Itoa(size))
+	return builder
+}
+
+// Fields 搜索员工时需要返回的字段列表
+func (builder *CoreHRReqBuilder) Fields(fields []string) *CoreHRReqBuilder {
+	builder.body["fields"] = fields
+	return builder
+}
+
+// PersonIds 批量查询的个人信息ID列表,单次最多100个
+func (builder *CoreHRReqBuilder) PersonIds(ids []string) *CoreHRReqBuilder {
+	builder.body["person_ids"] = ids
+	return builder
+}
+
+func (builder *CoreHRReqBuilder) Build() *CoreHRReq {
+	req := &CoreHRReq{}
+	req.req = builder.req
+	req.req.Body = builder.body
+	return req
+}
+
+// SearchEmployees 搜索员工信息,返回的employment_id类型与user_id_type对应
+func (c *corehr) SearchEmployees(req *CoreHRReq) ([]CoreHRRecord, error) {
+	var items []CoreHRRecord
+	var pageToken string
+	for {
+		params := req.req.QueryParams.Encode()
+		if pageToken != "" {
+			params += "&page_token=" + pageToken
+		}
+		request, err := http.NewRequest("POST", searchCoreHREmployeesUrl+"?"+params, utils.ConvertToReader(req.req.Body))
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Content-Type", "application/json; charset=utf-8")
+		token, err := req.req.Client.autoGetTenantAccessToken()
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+		response, err := req.req.Client.http.Do(request)
+		if err != nil {
+			return nil, err
+		}
+		body, err := ghttp.GetResponseBody(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		var tmp struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+			Data struct {
+				HasMore   bool           `json:"has_more"`
+				PageToken string         `json:"page_token"`
+				Items     []CoreHRRecord `json:"items"`
+			} `json:"data"`
+		}
+		err = json.Unmarshal(body, &tmp)
+		if err != nil {
+			return nil, err
+		}
+		if tmp.Code != 0 {
+			return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
+		}
+		items = append(items, tmp.Data.Items...)
+		if !tmp.Data.HasMore || tmp.Data.PageToken == "" {
+			return items, nil
+		}
+		pageToken = tmp.Data.PageToken
+	}
+}
+
+// Persons 批量查询个人信息
+func (c *corehr) Persons(req *CoreHRReq) ([]CoreHRRecord, error) {
+	request, err := http.NewRequest("POST", batchGetCoreHRPersonsUrl+"?"+req.req.QueryParams.Encode(), utils.ConvertToReader(req.req.Body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	token, err := req.req.Client.autoGetTenantAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	response, err := req.req.Client.http.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ghttp.GetResponseBody(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	var tmp struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			Items []CoreHRRecord `json:"items"`
+		} `json:"data"`
+	}
+	err = json.Unmarshal(body, &tmp)
+	if err != nil {
+		return nil, err
+	}
+	if tmp.Code != 0 {
+		return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
+	}
+	return tmp.Data.Items, nil
+}
+
+// JobDatas 批量查询任职信息
+func (c *corehr) JobDatas(req *CoreHRReq) ([]CoreHRRecord, error) {
+	return c.list(getCoreHRJobDatasUrl, req)
+}
+
+// Departments 批量查询人事部门
+func (c *corehr) Departments(req *CoreHRReq) ([]CoreHRRecord, error) {
+	return c.list(getCoreHRDepartmentsUrl, req)
+}
+
+func (c *corehr) list(url string, req *CoreHRReq) ([]CoreHRRecord, error) {
+	var items []CoreHRRecord
+	err := req.req.Client.listAll(url, req.req.QueryParams, func(data json.RawMessage) error {
+		var page []CoreHRRecord
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+		items = append(items, page...)
+		return nil
+	})
+	return items, err
+}
@@ -0,0 +1,137 @@
+package feishu
+
+import (
+	"encoding/json"
+	"github.com/fasnow/ghttp"
+	"idebug/plugin"
+	"net/http"
+	"strconv"
+)
+
+type I18nText struct {
+	Locale string `json:"locale"` // 语言版本,如zh_cn、en_us、ja_jp
+	Value  string `json:"value"`  // 对应语言版本的值
+}
+
+type CustomAttrEntry struct {
+	Id      string `json:"id"`   // 自定义字段ID
+	Type    string `json:"type"` // 自定义字段类型,可选值有TEXT、HREF、ENUMERATION、PICTURE_ENUM、GENERIC_USER
+	Options struct {
+		DefaultOptionId string `json:"default_option_id"` // 默认选项ID
+		OptionType      string `json:"option_type"`       // 选项类型,可选值有TEXT、PICTURE
+		Options         []struct {
+			Id    string `json:"id"`    // 枚举类型选项ID
+			Value string `json:"value"` // 选项值,选项类型为PICTURE时为图片链接
+			Name  string `json:"name"`  // 选项名称,选项类型为PICTURE时有效
+		} `json:"options"` // 选项列表
+	} `json:"options"` // 选项定义,当type为ENUMERATION或者PICTURE_ENUM时有效
+	I18nName []I18nText `json:"i18n_name"` // 自定义字段名称
+}
+
+// Name 返回自定义字段的中文名称,不存在时依次返回英文名称、第一个名称和字段ID
+func (attr *CustomAttrEntry) Name() string {
+	for _, locale := range []string{"zh_cn", "en_us"} {
+		for _, name := range attr.I18nName {
+			if name.Locale == locale && name.Value != "" {
+				return name.Value
+			}
+		}
+	}
+	if len(attr.I18nName) > 0 && attr.I18nName[0].Value != "" {
+		return attr.I18nName[0].Value
+	}
+	return attr.Id
+}
+
+// OptionName 根据选项ID返回枚举选项的名称
+func (attr *CustomAttrEntry) OptionName(optionId string) string {
+	for _, option := range attr.Options.Options {
+		if option.Id == optionId {
+			if option.Name != "" {
+				return option.Name
+			}
+			return option.Value
+		}
+	}
+	return optionId
+}
+
+type ListCustomAttrReqBuilder struct {
+	req Req
+}
+
+type ListCustomAttrReq struct {
+	req Req
+}
+
+func NewListCustomAttrReqBuilder(f *Client) *ListCustomAttrReqBuilder {
+	builder := &ListCustomAttrReqBuilder{}
+	builder.req = Req{
+		PathParams:  &plugin.PathParams{},
+		QueryParams: &plugin.QueryParams{},
+		Client:      f,
+	}
+	return builder
+}
+
+// PageSize 分页大小,最大100
+func (builder *ListCustomAttrReqBuilder) PageSize(size int) *ListCustomAttrReqBuilder {
+	builder.req.QueryParams.Set("page_size", strconv.Itoa(size))
+	return builder
+}
+
+func (builder *ListCustomAttrReqBuilder) Build() *ListCustomAttrReq {
+	req := &ListCustomAttrReq{}
+	req.req = builder.req
+	return req
+}
+
+// List 获取企业自定义用户字段
+func (attr *customAttr) List(req *ListCustomAttrReq) ([]*CustomAttrEntry, error) {
+	var items []*CustomAttrEntry
+	var pageToken string
+	for {
+		params := req.req.QueryParams.Encode()
+		if pageToken != "" {
+			params += "&page_token=" + pageToken
+		}
+		request, err := http.NewRequest("GET", getCustomAttrsUrl+"?"+params, nil)
+		if err != nil {
+			return nil, err
+		}
+		token, err := req.req.Client.autoGetTenantAccessToken()
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+		response, err := req.req.Client.http.Do(request)
+		if err != nil {
+			return nil, err
+		}
+		body, err := ghttp.GetResponseBody(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		var tmp struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+			Data struct {
+				HasMore   bool               `json:"has_more"`
+				PageToken string             `json:"page_token"`
+				Items     []*CustomAttrEntry `json:"items"`
+			} `json:"data"`
+		}
+		err = json.Unmarshal(body, &tmp)
+		if err != nil {
+			return nil, err
+		}
+		if tmp.Code != 0 {
+			return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
+		}
+		items = append(items, tmp.Data.Items...)
+		if !tmp.Data.HasMore {
+			return items, nil
+		}
+		pageToken = tmp.Data.PageToken
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
-	"time"
 )
 
 type DepartmentI18nName struct {
@@ -124,7 +123,7 @@ func (dept *department) Get(req *GetDepartmentReq) (DepartmentEntry, error) {
 		return deptEntry, err
 	}
 	if tmp.Code != 0 {
-		return deptEntry, fmt.Errorf("from server - " + tmp.Msg)
+		return deptEntry, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
 	}
 	return tmp.Data.DepartmentEntry, nil
 }
@@ -208,7 +207,7 @@ func (dept *department) Bath(req *GetBatchDepartmentReq) ([]DepartmentEntry, err
 		return nil, err
 	}
 	if tmp.Code != 0 {
-		return nil, fmt.Errorf("from server - " + tmp.Msg)
+		return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
 	}
 	return tmp.Data.Items, nil
 }
@@ -305,14 +304,13 @@ func (dept *department) moreDepartment(f *Client, req *GetDepartmentChildrenReq,
 		return nil, err
 	}
 	if tmp.Code != 0 {
-		return nil, fmt.Errorf("from server - " + tmp.Msg)
+		return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
 	}
 	var deptItems []*DepartmentEntry
 	deptItems = append(deptItems, tmp.Data.Items...)
 	if !tmp.Data.HasMore {
 		return deptItems, nil
 	}
-	time.Sleep(defaultInterval)
 	moreDepartments, err := dept.moreDepartment(req.req.Client, req, tmp.Data.PageToken)
 	if err != nil {
 		return nil, err
@@ -359,14 +357,13 @@ func (dept *department) Children(req *GetDepartmentChildrenReq) ([]*DepartmentEn
 		return nil, err
 	}
 	if tmp.Code != 0 {
-		return nil, fmt.Errorf("from server - " + tmp.Msg)
+		return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
 	}
 	var deptItems []*DepartmentEntry
 	deptItems = append(deptItems, tmp.Data.Items...)
 	if !tmp.Data.HasMore {
 		return deptItems, nil
 	}
-	time.Sleep(defaultInterval)
 	moreDepartments, err := dept.moreDepartment(req.req.Client, req, tmp.Data.PageToken)
 	if err != nil {
 		return nil, err
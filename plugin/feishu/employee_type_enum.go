@@ -0,0 +1,130 @@
+package feishu
+
+import (
+	"encoding/json"
+	"github.com/fasnow/ghttp"
+	"idebug/plugin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type EmployeeTypeEnumEntry struct {
+	EnumId      string     `json:"enum_id"`      // 枚举值ID
+	EnumValue   string     `json:"enum_value"`   // 枚举的编号值,与用户的employee_type对应
+	Content     string     `json:"content"`      // 枚举内容
+	EnumType    int        `json:"enum_type"`    // 类型,1:内置类型,2:自定义
+	EnumStatus  int        `json:"enum_status"`  // 状态,1:激活,2:未激活
+	I18nContent []I18nText `json:"i18n_content"` // 多语言内容
+}
+
+type ListEmployeeTypeEnumReqBuilder struct {
+	req Req
+}
+
+type ListEmployeeTypeEnumReq struct {
+	req Req
+}
+
+func NewListEmployeeTypeEnumReqBuilder(f *Client) *ListEmployeeTypeEnumReqBuilder {
+	builder := &ListEmployeeTypeEnumReqBuilder{}
+	builder.req = Req{
+		PathParams:  &plugin.PathParams{},
+		QueryParams: &plugin.QueryParams{},
+		Client:      f,
+	}
+	return builder
+}
+
+// PageSize 分页大小,最大100
+func (builder *ListEmployeeTypeEnumReqBuilder) PageSize(size int) *ListEmployeeTypeEnumReqBuilder {
+	builder.req.QueryParams.Set("page_size", strconv.Itoa(size))
+	return builder
+}
+
+func (builder *ListEmployeeTypeEnumReqBuilder) Build() *ListEmployeeTypeEnumReq {
+	req := &ListEmployeeTypeEnumReq{}
+	req.req = builder.req
+	return req
+}
+
+// List 获取租户的人员类型,包括内置类型和自定义类型
+func (e *employeeTypeEnum) List(req *ListEmployeeTypeEnumReq) ([]*EmployeeTypeEnumEntry, error) {
+	var items []*EmployeeTypeEnumEntry
+	var pageToken string
+	for {
+		params := req.req.QueryParams.Encode()
+		if pageToken != "" {
+			params += "&page_token=" + pageToken
+		}
+		request, err := http.NewRequest("GET", getEmployeeTypeEnumsUrl+"?"+params, nil)
+		if err != nil {
+			return nil, err
+		}
+		token, err := req.req.Client.autoGetTenantAccessToken()
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+		response, err := req.req.Client.http.Do(request)
+		if err != nil {
+			return nil, err
+		}
+		body, err := ghttp.GetResponseBody(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		var tmp struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+			Data struct {
+				HasMore   bool                     `json:"has_more"`
+				PageToken string                   `json:"page_token"`
+				Items     []*EmployeeTypeEnumEntry `json:"items"`
+			} `json:"data"`
+		}
+		err = json.Unmarshal(body, &tmp)
+		if err != nil {
+			return nil, err
+		}
+		if tmp.Code != 0 {
+			return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
+		}
+		items = append(items, tmp.Data.Items...)
+		if !tmp.Data.HasMore {
+			return items, nil
+		}
+		pageToken = tmp.Data.PageToken
+	}
+}
+
+// GetEmployeeTypeNames 获取人员类型编号与名称的对应关系,成功后缓存至重新设置应用凭证为止
+func (client *Client) GetEmployeeTypeNames() (map[int]string, error) {
+	if value, ok := client.cache.Get("employeeTypeNames"); ok {
+		if names, ok := value.(map[int]string); ok {
+			return names, nil
+		}
+	}
+	req := NewListEmployeeTypeEnumReqBuilder(client).PageSize(100).Build()
+	items, err := client.EmployeeTypeEnum.List(req)
+	if err != nil {
+		return nil, err
+	}
+	names := map[int]string{}
+	for _, item := range items {
+		value, err := strconv.Atoi(item.EnumValue)
+		if err != nil {
+			continue
+		}
+		name := item.Content
+		for _, content := range item.I18nContent {
+			if content.Locale == "zh_cn" && content.Value != "" {
+				name = content.Value
+				break
+			}
+		}
+		names[value] = name
+	}
+	client.cache.Set("employeeTypeNames", names, 24*time.Hour)
+	return names, nil
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"github.com/fasnow/ghttp"
 	"idebug/plugin"
 	"idebug/utils"
@@ -13,19 +12,48 @@ import (
 )
 
 const (
-	getTenantAccessTokenUrl    = "https://open.feishu.cn/open-apis/auth/v3/tenant_access_token/internal" // 应用将代表租户（企业或团队）执行对应的操作，例如获取一个通讯录用户的信息。API 所能操作的数据资源范围受限于应用的身份所能操作的资源范围。由于商店应用会为多家企业提供服务，所以需要先获取对应企业的授权访问凭证 tenant_access_token，并使用该访问凭证来调用 API 访问企业的数据或者资源
-	getAppAccessTokenUrl       = "https://open.feishu.cn/open-apis/auth/v3/app_access_token/internal"
-	getUserAccessToken         = "" // 应用以用户的身份进行相关的操作，访问的数据范围、可以执行的操作将会受到该用户的权限影响。
-	getAuthScopeUrl            = "https://open.feishu.cn/open-apis/contact/v3/scopes"
-	getDepartmentUrl           = "https://open.feishu.cn/open-apis/contact/v3/departments/:department_id"
-	getBatchDepartmentUrl      = "https://open.feishu.cn/open-apis/contact/v3/departments/batch"
-	getDepartmentChildrenUrl   = "https://open.feishu.cn/open-apis/contact/v3/departments/:department_id/children"
-	getUserUrl                 = "https://open.feishu.cn/open-apis/contact/v3/users/:user_id"
-	getUsersIdUrl              = "https://open.feishu.cn/open-apis/contact/v3/users/find_by_department"
-	userEmailPasswordChangeUrl = "https://open.feishu.cn/open-apis/admin/v1/password/reset"
+	getTenantAccessTokenUrl      = "https://open.feishu.cn/open-apis/auth/v3/tenant_access_token/internal" // 应用将代表租户（企业或团队）执行对应的操作，例如获取一个通讯录用户的信息。API 所能操作的数据资源范围受限于应用的身份所能操作的资源范围。由于商店应用会为多家企业提供服务，所以需要先获取对应企业的授权访问凭证 tenant_access_token，并使用该访问凭证来调用 API 访问企业的数据或者资源
+	getAppAccessTokenUrl         = "https://open.feishu.cn/open-apis/auth/v3/app_access_token/internal"
+	getUserAccessToken           = "" // 应用以用户的身份进行相关的操作，访问的数据范围、可以执行的操作将会受到该用户的权限影响。
+	getAuthScopeUrl              = "https://open.feishu.cn/open-apis/contact/v3/scopes"
+	getDepartmentUrl             = "https://open.feishu.cn/open-apis/contact/v3/departments/:department_id"
+	getBatchDepartmentUrl        = "https://open.feishu.cn/open-apis/contact/v3/departments/batch"
+	getDepartmentChildrenUrl     = "https://open.feishu.cn/open-apis/contact/v3/departments/:department_id/children"
+	getUserUrl                   = "https://open.feishu.cn/open-apis/contact/v3/users/:user_id"
+	getUsersIdUrl                = "https://open.feishu.cn/open-apis/contact/v3/users/find_by_department"
+	batchGetUserIdUrl            = "https://open.feishu.cn/open-apis/contact/v3/users/batch_get_id"
+	userEmailPasswordChangeUrl   = "https://open.feishu.cn/open-apis/admin/v1/password/reset"
+	getCustomAttrsUrl            = "https://open.feishu.cn/open-apis/contact/v3/custom_attrs"
+	getEmployeeTypeEnumsUrl      = "https://open.feishu.cn/open-apis/contact/v3/employee_type_enums"
+	getGroupListUrl              = "https://open.feishu.cn/open-apis/contact/v3/group/simplelist"
+	getGroupUrl                  = "https://open.feishu.cn/open-apis/contact/v3/group/:group_id"
+	getGroupMembersUrl           = "https://open.feishu.cn/open-apis/contact/v3/group/:group_id/member/simplelist"
+	getChatListUrl               = "https://open.feishu.cn/open-apis/im/v1/chats"
+	getChatUrl                   = "https://open.feishu.cn/open-apis/im/v1/chats/:chat_id"
+	getChatMembersUrl            = "https://open.feishu.cn/open-apis/im/v1/chats/:chat_id/members"
+	sendMessageUrl               = "https://open.feishu.cn/open-apis/im/v1/messages"
+	messageUrl                   = "https://open.feishu.cn/open-apis/im/v1/messages/:message_id"
+	replyMessageUrl              = "https://open.feishu.cn/open-apis/im/v1/messages/:message_id/reply"
+	uploadImageUrl               = "https://open.feishu.cn/open-apis/im/v1/images"
+	getTenantUrl                 = "https://open.feishu.cn/open-apis/tenant/v2/tenant/query"
+	getBotInfoUrl                = "https://open.feishu.cn/open-apis/bot/v3/info"
+	getAppScopesUrl              = "https://open.feishu.cn/open-apis/application/v6/scopes"
+	getAuditInfosUrl             = "https://open.feishu.cn/open-apis/admin/v1/audit_infos"
+	getAdminDeptStatsUrl         = "https://open.feishu.cn/open-apis/admin/v1/admin_dept_stats"
+	getAdminUserStatsUrl         = "https://open.feishu.cn/open-apis/admin/v1/admin_user_stats"
+	searchCoreHREmployeesUrl     = "https://open.feishu.cn/open-apis/corehr/v2/employees/search"
+	batchGetCoreHRPersonsUrl     = "https://open.feishu.cn/open-apis/corehr/v2/persons/batch_get"
+	getCoreHRJobDatasUrl         = "https://open.feishu.cn/open-apis/corehr/v1/job_datas"
+	getCoreHRDepartmentsUrl      = "https://open.feishu.cn/open-apis/corehr/v1/departments"
+	bitableTablesUrl             = "https://open.feishu.cn/open-apis/bitable/v1/apps/:app_token/tables"
+	bitableFieldsUrl             = "https://open.feishu.cn/open-apis/bitable/v1/apps/:app_token/tables/:table_id/fields"
+	bitableRecordsUrl            = "https://open.feishu.cn/open-apis/bitable/v1/apps/:app_token/tables/:table_id/records"
+	bitableBatchCreateRecordsUrl = "https://open.feishu.cn/open-apis/bitable/v1/apps/:app_token/tables/:table_id/records/batch_create"
+	bitableBatchUpdateRecordsUrl = "https://open.feishu.cn/open-apis/bitable/v1/apps/:app_token/tables/:table_id/records/batch_update"
 )
 
-var defaultInterval = 200 * time.Millisecond
+// baseDomain 接口域名,call命令的接口路径会拼接至该域名
+var baseDomain = "https://open.feishu.cn"
 
 type config struct {
 	AppId             *string
@@ -34,6 +62,9 @@ type config struct {
 	DepartmentScope   map[string]string
 	GroupScope        map[string]string
 	UserScope         map[string]string
+	Tenant            *TenantEntry     // 企业信息
+	Bot               *BotEntry        // 机器人信息
+	AppScopes         []*AppScopeEntry // 应用权限
 }
 
 type department struct {
@@ -44,24 +75,81 @@ type user struct {
 	client *Client
 }
 
+type customAttr struct {
+	client *Client
+}
+
+type employeeTypeEnum struct {
+	client *Client
+}
+
+type group struct {
+	client *Client
+}
+
+type chat struct {
+	client *Client
+}
+
+type message struct {
+	client *Client
+}
+
+type admin struct {
+	client *Client
+}
+
+type corehr struct {
+	client *Client
+}
+
+type bitable struct {
+	client *Client
+}
+
 type Client struct {
-	config     *config
-	Department *department
-	User       *user
-	cache      *utils.Cache // 保存access_token
-	http       *ghttp.Client
+	config           *config
+	Department       *department
+	User             *user
+	CustomAttr       *customAttr
+	EmployeeTypeEnum *employeeTypeEnum
+	Group            *group
+	Chat             *chat
+	Message          *message
+	Admin            *admin
+	CoreHR           *corehr
+	Bitable          *bitable
+	cache            *utils.Cache // 保存access_token
+	http             *plugin.HttpClient
 }
 
 func NewClient() *Client {
 	f := &Client{
-		config:     &config{},
-		cache:      utils.NewCache(3 * time.Second),
-		http:       &ghttp.Client{},
-		User:       &user{},
-		Department: &department{},
+		config:           &config{},
+		cache:            utils.NewCache(3 * time.Second),
+		http:             plugin.NewHttpClient(plugin.PlatformFeishu),
+		User:             &user{},
+		Department:       &department{},
+		CustomAttr:       &customAttr{},
+		EmployeeTypeEnum: &employeeTypeEnum{},
+		Group:            &group{},
+		Chat:             &chat{},
+		Message:          &message{},
+		Admin:            &admin{},
+		CoreHR:           &corehr{},
+		Bitable:          &bitable{},
 	}
 	f.User.client = f
 	f.Department.client = f
+	f.CustomAttr.client = f
+	f.EmployeeTypeEnum.client = f
+	f.Group.client = f
+	f.Chat.client = f
+	f.Message.client = f
+	f.Admin.client = f
+	f.CoreHR.client = f
+	f.Bitable.client = f
+	f.http.RefreshToken = f.refreshToken
 	return f
 }
 
@@ -73,6 +161,16 @@ func (client *Client) StopWhenContextCanceled(enable bool) {
 	client.http.StopWhenContextCanceled = enable
 }
 
+// Limiter 返回请求限速器,用于调整各接口类别的QPS
+func (client *Client) Limiter() *plugin.RateLimiter {
+	return client.http.Limiter
+}
+
+// RetryPolicy 返回请求重试策略,用于调整重试次数和总时长
+func (client *Client) RetryPolicy() *plugin.RetryPolicy {
+	return client.http.Retry
+}
+
 func (client *Client) Set(appId, appSecret string) {
 	conf := &config{
 		AppId:     &appId,
@@ -106,8 +204,9 @@ func (client *Client) SetTenantAccessTokenFromServer() error {
 // GetNewAuthScope 获取新的tenant_access_token和新的权限范围,并设置两者的缓存,返回新的config
 func (client *Client) GetNewAuthScope(req *GetAuthScopeReq) (*config, error) {
 	client.config.DepartmentScope = map[string]string{}
+	client.config.GroupScope = map[string]string{}
 	client.config.UserScope = map[string]string{}
-	departmentScope, _, userScope, err := client.getNewAuthScope(req)
+	departmentScope, groupScope, userScope, err := client.getNewAuthScope(req)
 	if err != nil {
 		return nil, err
 	}
@@ -124,11 +223,20 @@ func (client *Client) GetNewAuthScope(req *GetAuthScopeReq) (*config, error) {
 		dpetInfo, err := client.Department.Get(req1)
 		if err != nil {
 			client.config.DepartmentScope[*deptId] = ""
-			time.Sleep(defaultInterval)
 			continue
 		}
 		client.config.DepartmentScope[*deptId] = dpetInfo.Name
-		time.Sleep(defaultInterval)
+	}
+	for _, groupId := range groupScope {
+		req1 := NewGetGroupReqBuilder(client).
+			GroupId(*groupId).
+			Build()
+		groupInfo, err := client.Group.Get(req1)
+		if err != nil || groupInfo == nil {
+			client.config.GroupScope[*groupId] = ""
+			continue
+		}
+		client.config.GroupScope[*groupId] = groupInfo.Name
 	}
 	for _, userId := range userScope {
 		req1 := NewGetUserReqBuilder(client).
@@ -138,11 +246,9 @@ func (client *Client) GetNewAuthScope(req *GetAuthScopeReq) (*config, error) {
 		userInfo, err := client.User.Get(req1)
 		if err != nil {
 			client.config.UserScope[*userId] = ""
-			time.Sleep(defaultInterval)
 			continue
 		}
 		client.config.UserScope[*userId] = userInfo.Name
-		time.Sleep(defaultInterval)
 	}
 
 	return client.config, nil
@@ -187,7 +293,7 @@ func (client *Client) getNewAuthScope(req *GetAuthScopeReq) ([]*string, []*strin
 		return nil, nil, nil, err
 	}
 	if tmp.Code != 0 {
-		return nil, nil, nil, fmt.Errorf("from server - " + tmp.Msg)
+		return nil, nil, nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
 	}
 	var (
 		departmentIds []*string
@@ -200,14 +306,13 @@ func (client *Client) getNewAuthScope(req *GetAuthScopeReq) ([]*string, []*strin
 	if !tmp.Data.HasMore {
 		return departmentIds, groupIds, userIds, nil
 	}
-	time.Sleep(defaultInterval)
-	deptIds, groupIds, userIds, err := client.getAuthScopeMore(tmp.Data.PageToken, req)
+	moreDeptIds, moreGroupIds, moreUserIds, err := client.getAuthScopeMore(tmp.Data.PageToken, req)
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	departmentIds = append(departmentIds, deptIds...)
-	groupIds = append(groupIds, groupIds...)
-	userIds = append(userIds, userIds...)
+	departmentIds = append(departmentIds, moreDeptIds...)
+	groupIds = append(groupIds, moreGroupIds...)
+	userIds = append(userIds, moreUserIds...)
 	return departmentIds, groupIds, userIds, nil
 }
 
@@ -259,7 +364,7 @@ func (client *Client) getAccessTokenByUrl(url string) (string, int, error) {
 		return "", 0, err
 	}
 	if tmp.Code != 0 {
-		return "", 0, fmt.Errorf("from server - " + tmp.Msg)
+		return "", 0, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
 	}
 	return tmp.TenantAccessToken, tmp.Expire, nil
 }
@@ -302,21 +407,27 @@ func (client *Client) getAuthScopeMore(pageToken string, req *GetAuthScopeReq) (
 		return nil, nil, nil, err
 	}
 	if tmp.Code != 0 {
-		return nil, nil, nil, fmt.Errorf("from server - " + tmp.Msg)
+		return nil, nil, nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
 	}
 	var (
 		departmentIds []*string
 		groupIds      []*string
 		userIds       []*string
 	)
-	departmentIds = append(departmentIds, tmp.Data.GroupIds...)
+	departmentIds = append(departmentIds, tmp.Data.DepartmentIds...)
 	groupIds = append(groupIds, tmp.Data.GroupIds...)
 	userIds = append(userIds, tmp.Data.UserIds...)
 	if !tmp.Data.HasMore {
 		return departmentIds, groupIds, userIds, nil
 	}
-	time.Sleep(defaultInterval)
-	return client.getAuthScopeMore(tmp.Data.PageToken, req)
+	moreDeptIds, moreGroupIds, moreUserIds, err := client.getAuthScopeMore(tmp.Data.PageToken, req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	departmentIds = append(departmentIds, moreDeptIds...)
+	groupIds = append(groupIds, moreGroupIds...)
+	userIds = append(userIds, moreUserIds...)
+	return departmentIds, groupIds, userIds, nil
 }
 
 // 从缓存中取出tenant_access_token,没有缓存的话则添加新的
@@ -332,6 +443,19 @@ func (client *Client) autoGetTenantAccessToken() (string, error) {
 	return "", errors.New("获取tenant_access_token时出错")
 }
 
+// refreshToken tenant_access_token失效时重新获取并写入请求头
+func (client *Client) refreshToken(request *http.Request) bool {
+	if request.Header.Get("Authorization") == "" {
+		return false
+	}
+	token, err := client.getNewTenantAccessToken()
+	if err != nil {
+		return false
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	return true
+}
+
 // 获取新的tenant_access_token并设置缓存
 func (client *Client) getNewTenantAccessToken() (string, error) {
 	token, expire, err := client.getAccessTokenByUrl(getTenantAccessTokenUrl)
@@ -0,0 +1,282 @@
+package feishu
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/fasnow/ghttp"
+	"idebug/plugin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type GroupEntry struct {
+	Id                    string `json:"id"`                      // 用户组ID
+	Name                  string `json:"name"`                    // 用户组名字
+	Description           string `json:"description"`             // 用户组描述
+	MemberUserCount       int    `json:"member_user_count"`       // 用户组成员中用户的数量
+	MemberDepartmentCount int    `json:"member_department_count"` // 用户组成员中部门的数量
+	Type                  int    `json:"type"`                    // 用户组的类型,1:普通用户组,2:动态用户组
+}
+
+type GroupMemberEntry struct {
+	MemberId     string `json:"member_id"`      // 成员ID
+	MemberType   string `json:"member_type"`    // 成员类型,user或者department
+	MemberIdType string `json:"member_id_type"` // 成员ID类型
+}
+
+type ListGroupReqBuilder struct {
+	req Req
+}
+
+type ListGroupReq struct {
+	req Req
+}
+
+func NewListGroupReqBuilder(f *Client) *ListGroupReqBuilder {
+	builder := &ListGroupReqBuilder{}
+	builder.req = Req{
+		PathParams:  &plugin.PathParams{},
+		QueryParams: &plugin.QueryParams{},
+		Client:      f,
+	}
+	return builder
+}
+
+// Type 用户组类型,1:普通用户组,2:动态用户组,默认为1
+func (builder *ListGroupReqBuilder) Type(t int) *ListGroupReqBuilder {
+	builder.req.QueryParams.Set("type", strconv.Itoa(t))
+	return builder
+}
+
+// PageSize 分页大小,最大100
+func (builder *ListGroupReqBuilder) PageSize(size int) *ListGroupReqBuilder {
+	builder.req.QueryParams.Set("page_size", strconv.Itoa(size))
+	return builder
+}
+
+func (builder *ListGroupReqBuilder) Build() *ListGroupReq {
+	req := &ListGroupReq{}
+	req.req = builder.req
+	return req
+}
+
+// List 查询应用在通讯录授权范围内的用户组列表
+func (g *group) List(req *ListGroupReq) ([]*GroupEntry, error) {
+	var items []*GroupEntry
+	var pageToken string
+	for {
+		params := req.req.QueryParams.Encode()
+		if pageToken != "" {
+			params += "&page_token=" + pageToken
+		}
+		request, err := http.NewRequest("GET", getGroupListUrl+"?"+params, nil)
+		if err != nil {
+			return nil, err
+		}
+		token, err := req.req.Client.autoGetTenantAccessToken()
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+		response, err := req.req.Client.http.Do(request)
+		if err != nil {
+			return nil, err
+		}
+		body, err := ghttp.GetResponseBody(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		var tmp struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+			Data struct {
+				HasMore   bool          `json:"has_more"`
+				PageToken string        `json:"page_token"`
+				GroupList []*GroupEntry `json:"grouplist"`
+			} `json:"data"`
+		}
+		err = json.Unmarshal(body, &tmp)
+		if err != nil {
+			return nil, err
+		}
+		if tmp.Code != 0 {
+			return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
+		}
+		items = append(items, tmp.Data.GroupList...)
+		if !tmp.Data.HasMore {
+			return items, nil
+		}
+		pageToken = tmp.Data.PageToken
+	}
+}
+
+type GetGroupReqBuilder struct {
+	req Req
+}
+
+type GetGroupReq struct {
+	req Req
+}
+
+func NewGetGroupReqBuilder(f *Client) *GetGroupReqBuilder {
+	builder := &GetGroupReqBuilder{}
+	builder.req = Req{
+		PathParams:  &plugin.PathParams{},
+		QueryParams: &plugin.QueryParams{},
+		Client:      f,
+	}
+	return builder
+}
+
+func (builder *GetGroupReqBuilder) GroupId(id string) *GetGroupReqBuilder {
+	builder.req.PathParams.Set(":group_id", id)
+	return builder
+}
+
+func (builder *GetGroupReqBuilder) Build() *GetGroupReq {
+	req := &GetGroupReq{}
+	req.req = builder.req
+	return req
+}
+
+// Get 查询指定用户组详情
+func (g *group) Get(req *GetGroupReq) (*GroupEntry, error) {
+	id := strings.TrimSpace(req.req.PathParams.Get(":group_id"))
+	if id == "" {
+		return nil, errors.New("用户组ID不能为空")
+	}
+	request, err := http.NewRequest("GET", strings.Replace(getGroupUrl, ":group_id", id, 1), nil)
+	if err != nil {
+		return nil, err
+	}
+	token, err := req.req.Client.autoGetTenantAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	response, err := req.req.Client.http.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ghttp.GetResponseBody(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	var tmp struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			Group *GroupEntry `json:"group"`
+		} `json:"data"`
+	}
+	err = json.Unmarshal(body, &tmp)
+	if err != nil {
+		return nil, err
+	}
+	if tmp.Code != 0 {
+		return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
+	}
+	return tmp.Data.Group, nil
+}
+
+type GetGroupMembersReqBuilder struct {
+	req Req
+}
+
+type GetGroupMembersReq struct {
+	req Req
+}
+
+func NewGetGroupMembersReqBuilder(f *Client) *GetGroupMembersReqBuilder {
+	builder := &GetGroupMembersReqBuilder{}
+	builder.req = Req{
+		PathParams:  &plugin.PathParams{},
+		QueryParams: &plugin.QueryParams{},
+		Client:      f,
+	}
+	return builder
+}
+
+func (builder *GetGroupMembersReqBuilder) GroupId(id string) *GetGroupMembersReqBuilder {
+	builder.req.PathParams.Set(":group_id", id)
+	return builder
+}
+
+// MemberIdType 成员ID类型,可选值open_id、union_id、user_id、department_id
+func (builder *GetGroupMembersReqBuilder) MemberIdType(t string) *GetGroupMembersReqBuilder {
+	builder.req.QueryParams.Set("member_id_type", t)
+	return builder
+}
+
+// MemberType 成员类型,可选值user、department,默认为user
+func (builder *GetGroupMembersReqBuilder) MemberType(t string) *GetGroupMembersReqBuilder {
+	builder.req.QueryParams.Set("member_type", t)
+	return builder
+}
+
+// PageSize 分页大小,最大100
+func (builder *GetGroupMembersReqBuilder) PageSize(size int) *GetGroupMembersReqBuilder {
+	builder.req.QueryParams.Set("page_size", strconv.Itoa(size))
+	return builder
+}
+
+func (builder *GetGroupMembersReqBuilder) Build() *GetGroupMembersReq {
+	req := &GetGroupMembersReq{}
+	req.req = builder.req
+	return req
+}
+
+// Members 查询用户组成员列表
+func (g *group) Members(req *GetGroupMembersReq) ([]*GroupMemberEntry, error) {
+	id := strings.TrimSpace(req.req.PathParams.Get(":group_id"))
+	if id == "" {
+		return nil, errors.New("用户组ID不能为空")
+	}
+	var items []*GroupMemberEntry
+	var pageToken string
+	for {
+		params := req.req.QueryParams.Encode()
+		if pageToken != "" {
+			params += "&page_token=" + pageToken
+		}
+		request, err := http.NewRequest("GET", strings.Replace(getGroupMembersUrl, ":group_id", id, 1)+"?"+params, nil)
+		if err != nil {
+			return nil, err
+		}
+		token, err := req.req.Client.autoGetTenantAccessToken()
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+		response, err := req.req.Client.http.Do(request)
+		if err != nil {
+			return nil, err
+		}
+		body, err := ghttp.GetResponseBody(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		var tmp struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+			Data struct {
+				HasMore    bool                `json:"has_more"`
+				PageToken  string              `json:"page_token"`
+				MemberList []*GroupMemberEntry `json:"memberlist"`
+			} `json:"data"`
+		}
+		err = json.Unmarshal(body, &tmp)
+		if err != nil {
+			return nil, err
+		}
+		if tmp.Code != 0 {
+			return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
+		}
+		items = append(items, tmp.Data.MemberList...)
+		if !tmp.Data.HasMore {
+			return items, nil
+		}
+		pageToken = tmp.Data.PageToken
+	}
+}
@@ -0,0 +1,258 @@
+package feishu
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/fasnow/ghttp"
+	"idebug/plugin"
+	"idebug/utils"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type MessageEntry struct {
+	MessageId  string `json:"message_id"`  // 消息ID
+	RootId     string `json:"root_id"`     // 根消息ID
+	ParentId   string `json:"parent_id"`   // 父消息ID
+	MsgType    string `json:"msg_type"`    // 消息类型
+	CreateTime string `json:"create_time"` // 消息生成的时间戳(毫秒)
+	UpdateTime string `json:"update_time"` // 消息更新的时间戳(毫秒)
+	ChatId     string `json:"chat_id"`     // 所属的群
+	Sender     struct {
+		Id         string `json:"id"`          // 发送者ID
+		IdType     string `json:"id_type"`     // 发送者ID类型
+		SenderType string `json:"sender_type"` // 发送者类型
+		TenantKey  string `json:"tenant_key"`  // 租户Key
+	} `json:"sender"` // 发送者
+}
+
+type SendMessageReqBuilder struct {
+	req  Req
+	body map[string]string
+}
+
+type SendMessageReq struct {
+	req Req
+}
+
+func NewSendMessageReqBuilder(f *Client) *SendMessageReqBuilder {
+	builder := &SendMessageReqBuilder{body: map[string]string{}}
+	builder.req = Req{
+		PathParams:  &plugin.PathParams{},
+		QueryParams: &plugin.QueryParams{},
+		Client:      f,
+	}
+	return builder
+}
+
+// ReceiveIdType 接收者ID类型,可选值open_id、user_id、union_id、email、chat_id
+func (builder *SendMessageReqBuilder) ReceiveIdType(t string) *SendMessageReqBuilder {
+	builder.req.QueryParams.Set("receive_id_type", t)
+	return builder
+}
+
+// ReceiveId 接收者ID,类型与ReceiveIdType对应
+func (builder *SendMessageReqBuilder) ReceiveId(id string) *SendMessageReqBuilder {
+	builder.body["receive_id"] = id
+	return builder
+}
+
+// MessageId 回复或者更新的消息ID
+func (builder *SendMessageReqBuilder) MessageId(id string) *SendMessageReqBuilder {
+	builder.req.PathParams.Set(":message_id", id)
+	return builder
+}
+
+// MsgType 消息类型,可选值text、post、image、interactive等
+func (builder *SendMessageReqBuilder) MsgType(t string) *SendMessageReqBuilder {
+	builder.body["msg_type"] = t
+	return builder
+}
+
+// Content 消息内容,JSON结构序列化后的字符串
+func (builder *SendMessageReqBuilder) Content(content string) *SendMessageReqBuilder {
+	builder.body["content"] = content
+	return builder
+}
+
+func (builder *SendMessageReqBuilder) Build() *SendMessageReq {
+	req := &SendMessageReq{}
+	req.req = builder.req
+	req.req.Body = builder.body
+	return req
+}
+
+// Send 发送消息
+func (m *message) Send(req *SendMessageReq) (*MessageEntry, error) {
+	if req.req.QueryParams.Get("receive_id_type") == "" {
+		return nil, errors.New("接收者ID类型不能为空")
+	}
+	return m.do(req, "POST", sendMessageUrl+"?"+req.req.QueryParams.Encode())
+}
+
+// Reply 回复指定消息
+func (m *message) Reply(req *SendMessageReq) (*MessageEntry, error) {
+	id := strings.TrimSpace(req.req.PathParams.Get(":message_id"))
+	if id == "" {
+		return nil, errors.New("消息ID不能为空")
+	}
+	return m.do(req, "POST", strings.Replace(replyMessageUrl, ":message_id", id, 1))
+}
+
+// Update 更新已发送的消息卡片,仅支持interactive类型的消息
+func (m *message) Update(req *SendMessageReq) (*MessageEntry, error) {
+	id := strings.TrimSpace(req.req.PathParams.Get(":message_id"))
+	if id == "" {
+		return nil, errors.New("消息ID不能为空")
+	}
+	return m.do(req, "PATCH", strings.Replace(messageUrl, ":message_id", id, 1))
+}
+
+// Edit 编辑已发送的消息,仅支持text和post类型的消息
+func (m *message) Edit(req *SendMessageReq) (*MessageEntry, error) {
+	id := strings.TrimSpace(req.req.PathParams.Get(":message_id"))
+	if id == "" {
+		return nil, errors.New("消息ID不能为空")
+	}
+	return m.do(req, "PUT", strings.Replace(messageUrl, ":message_id", id, 1))
+}
+
+func (m *message) do(req *SendMessageReq, method, url string) (*MessageEntry, error) {
+	request, err := http.NewRequest(method, url, utils.ConvertToReader(req.req.Body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	token, err := req.req.Client.autoGetTenantAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	response, err := req.req.Client.http.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ghttp.GetResponseBody(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	var tmp struct {
+		Code int           `json:"code"`
+		Msg  string        `json:"msg"`
+		Data *MessageEntry `json:"data"`
+	}
+	err = json.Unmarshal(body, &tmp)
+	if err != nil {
+		return nil, err
+	}
+	if tmp.Code != 0 {
+		return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
+	}
+	if tmp.Data == nil {
+		tmp.Data = &MessageEntry{MessageId: req.req.PathParams.Get(":message_id")}
+	}
+	return tmp.Data, nil
+}
+
+type UploadImageReqBuilder struct {
+	req Req
+}
+
+type UploadImageReq struct {
+	req Req
+}
+
+func NewUploadImageReqBuilder(f *Client) *UploadImageReqBuilder {
+	builder := &UploadImageReqBuilder{}
+	builder.req = Req{
+		PathParams:  &plugin.PathParams{},
+		QueryParams: &plugin.QueryParams{},
+		Client:      f,
+	}
+	builder.req.QueryParams.Set("image_type", "message")
+	return builder
+}
+
+// ImageType 图片类型,message:用于发送消息,avatar:用于设置头像,默认为message
+func (builder *UploadImageReqBuilder) ImageType(t string) *UploadImageReqBuilder {
+	builder.req.QueryParams.Set("image_type", t)
+	return builder
+}
+
+// Filename 要上传的图片文件路径
+func (builder *UploadImageReqBuilder) Filename(filename string) *UploadImageReqBuilder {
+	builder.req.Body = filename
+	return builder
+}
+
+func (builder *UploadImageReqBuilder) Build() *UploadImageReq {
+	req := &UploadImageReq{}
+	req.req = builder.req
+	return req
+}
+
+// UploadImage 上传图片,返回image_key
+func (m *message) UploadImage(req *UploadImageReq) (string, error) {
+	filename, _ := req.req.Body.(string)
+	if filename == "" {
+		return "", errors.New("图片路径不能为空")
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err = writer.WriteField("image_type", req.req.QueryParams.Get("image_type")); err != nil {
+		return "", err
+	}
+	part, err := writer.CreateFormFile("image", filepath.Base(filename))
+	if err != nil {
+		return "", err
+	}
+	if _, err = io.Copy(part, file); err != nil {
+		return "", err
+	}
+	if err = writer.Close(); err != nil {
+		return "", err
+	}
+	request, err := http.NewRequest("POST", uploadImageUrl, &buf)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	token, err := req.req.Client.autoGetTenantAccessToken()
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	response, err := req.req.Client.http.Do(request)
+	if err != nil {
+		return "", err
+	}
+	body, err := ghttp.GetResponseBody(response.Body)
+	if err != nil {
+		return "", err
+	}
+	var tmp struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			ImageKey string `json:"image_key"`
+		} `json:"data"`
+	}
+	err = json.Unmarshal(body, &tmp)
+	if err != nil {
+		return "", err
+	}
+	if tmp.Code != 0 {
+		return "", plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
+	}
+	return tmp.Data.ImageKey, nil
+}
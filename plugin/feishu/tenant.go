@@ -0,0 +1,140 @@
+package feishu
+
+import (
+	"encoding/json"
+	"github.com/fasnow/ghttp"
+	"idebug/plugin"
+	"idebug/utils"
+	"net/http"
+)
+
+type TenantEntry struct {
+	Name      string `json:"name"`       // 企业名称
+	DisplayId string `json:"display_id"` // 企业编号,平台内唯一
+	TenantTag int    `json:"tenant_tag"` // 个人版/团队版标志,0:团队版,2:个人版
+	TenantKey string `json:"tenant_key"` // 企业标识
+	Avatar    struct {
+		AvatarOrigin string `json:"avatar_origin"` // 企业头像
+		Avatar72     string `json:"avatar_72"`     // 企业头像72x72
+		Avatar240    string `json:"avatar_240"`    // 企业头像240x240
+		Avatar640    string `json:"avatar_640"`    // 企业头像640x640
+	} `json:"avatar"` // 企业头像
+}
+
+type BotEntry struct {
+	ActivateStatus int      `json:"activate_status"` // 机器人激活状态,0:初始化,租户待安装;1:租户停用;2:租户启用;3:安装后待启用;4:升级待启用;5:license过期停用;6:Lark套餐到期或降级停用
+	AppName        string   `json:"app_name"`        // 应用名称
+	AvatarUrl      string   `json:"avatar_url"`      // 应用头像
+	IpWhiteList    []string `json:"ip_white_list"`   // 应用的IP白名单地址
+	OpenId         string   `json:"open_id"`         // 机器人的open_id
+}
+
+type AppScopeEntry struct {
+	ScopeName   string `json:"scope_name"`   // 权限名称
+	GrantStatus int    `json:"grant_status"` // 租户授予状态,1:已授权,2:未授权
+	ScopeType   string `json:"scope_type"`   // 权限类型,tenant:应用身份权限,user:用户身份权限
+}
+
+// GetTenant 获取企业信息
+func (client *Client) GetTenant() (*TenantEntry, error) {
+	var tmp struct {
+		Data struct {
+			Tenant *TenantEntry `json:"tenant"`
+		} `json:"data"`
+	}
+	if err := client.getWithTenantAccessToken(getTenantUrl, &tmp); err != nil {
+		return nil, err
+	}
+	return tmp.Data.Tenant, nil
+}
+
+// GetBotInfo 获取机器人信息,应用未开启机器人能力时返回错误
+func (client *Client) GetBotInfo() (*BotEntry, error) {
+	var tmp struct {
+		Bot *BotEntry `json:"bot"`
+	}
+	if err := client.getWithTenantAccessToken(getBotInfoUrl, &tmp); err != nil {
+		return nil, err
+	}
+	return tmp.Bot, nil
+}
+
+// GetAppScopes 获取应用在该租户下申请的权限及授予状态
+func (client *Client) GetAppScopes() ([]*AppScopeEntry, error) {
+	var tmp struct {
+		Data struct {
+			Scopes []*AppScopeEntry `json:"scopes"`
+		} `json:"data"`
+	}
+	if err := client.getWithTenantAccessToken(getAppScopesUrl, &tmp); err != nil {
+		return nil, err
+	}
+	return tmp.Data.Scopes, nil
+}
+
+// RefreshIdentity 获取企业信息、机器人信息和应用权限并保存至config,获取失败的项保持为空
+func (client *Client) RefreshIdentity() *config {
+	client.config.Tenant, _ = client.GetTenant()
+	client.config.Bot, _ = client.GetBotInfo()
+	client.config.AppScopes, _ = client.GetAppScopes()
+	return client.config
+}
+
+func (client *Client) getWithTenantAccessToken(url string, v any) error {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	token, err := client.autoGetTenantAccessToken()
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	response, err := client.http.Do(request)
+	if err != nil {
+		return err
+	}
+	body, err := ghttp.GetResponseBody(response.Body)
+	if err != nil {
+		return err
+	}
+	return decodeResult(response, body, v)
+}
+
+// postWithTenantAccessToken 使用tenant_access_token发送JSON格式的POST请求并解析响应
+func (client *Client) postWithTenantAccessToken(url string, body any, v any) error {
+	request, err := http.NewRequest("POST", url, utils.ConvertToReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	token, err := client.autoGetTenantAccessToken()
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	response, err := client.http.Do(request)
+	if err != nil {
+		return err
+	}
+	data, err := ghttp.GetResponseBody(response.Body)
+	if err != nil {
+		return err
+	}
+	return decodeResult(response, data, v)
+}
+
+// decodeResult 解析响应至v,code不为0时返回*plugin.APIError
+func decodeResult(response *http.Response, body []byte, v any) error {
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return err
+	}
+	if result.Code != 0 {
+		return plugin.NewFeishuError(result.Code, result.Msg, response)
+	}
+	return json.Unmarshal(body, v)
+}
@@ -6,15 +6,16 @@ import (
 	"fmt"
 	"github.com/fasnow/ghttp"
 	"idebug/plugin"
+	"idebug/utils"
 	"net/http"
 	"strconv"
 	"strings"
-	"time"
 )
 
 type UserEntry struct {
 	UserId        string `json:"user_id"`        // 用户的user_id，租户内用户的唯一标识，不同ID的说明参见 [用户相关的 ID 概念](https://open.feishu.cn/document/home/user-identity-introduction/introduction)
 	OpenId        string `json:"open_id"`        // 用户的open_id，应用内用户的唯一标识，不同ID的说明参见 [用户相关的 ID 概念](https://open.feishu.cn/document/home/user-identity-introduction/introduction)
+	UnionId       string `json:"union_id"`       // 用户的union_id，应用开发商发布的不同应用中同一用户的标识，不同ID的说明参见 [用户相关的 ID 概念](https://open.feishu.cn/document/home/user-identity-introduction/introduction)
 	Name          string `json:"name"`           // 用户名
 	EnName        string `json:"en_name"`        // 英文名
 	Nickname      string `json:"nickname"`       // 别名
@@ -43,6 +44,54 @@ type UserEntry struct {
 
 	JobTitle string `json:"job_title"` // 职务
 	IsFrozen bool   `json:"is_frozen"` // 是否暂停用户
+
+	Avatar                  UserAvatar        `json:"avatar"`                      // 用户头像信息
+	Orders                  []*UserOrder      `json:"orders"`                      // 用户排序信息,用于标记通讯录下组织架构的人员顺序,人员可能存在多个部门中,且有不同的排序
+	CustomAttrs             []*UserCustomAttr `json:"custom_attrs"`                // 自定义字段,字段名称可通过 [获取企业自定义用户字段](https://open.feishu.cn/document/uAjLw4CM/ukTMukTMukTM/reference/contact-v3/custom_attr/list) 获取
+	Positions               []*UserPosition   `json:"positions"`                   // 用户岗位信息
+	Description             string            `json:"description"`                 // 个性签名
+	DottedLineLeaderUserIds []string          `json:"dotted_line_leader_user_ids"` // 用户的虚线上级的用户ID,ID值与查询参数中的user_id_type 对应
+}
+
+type UserAvatar struct {
+	Avatar72     string `json:"avatar_72"`     // 72*72像素头像链接
+	Avatar240    string `json:"avatar_240"`    // 240*240像素头像链接
+	Avatar640    string `json:"avatar_640"`    // 640*640像素头像链接
+	AvatarOrigin string `json:"avatar_origin"` // 原始头像链接
+}
+
+type UserOrder struct {
+	DepartmentId    string `json:"department_id"`    // 排序信息对应的部门ID,ID值与查询参数中的department_id_type 对应
+	UserOrder       int    `json:"user_order"`       // 用户在其直属部门内的排序,数值越大,排序越靠前
+	DepartmentOrder int    `json:"department_order"` // 用户所属的多个部门间的排序,数值越大,排序越靠前
+	IsPrimaryDept   bool   `json:"is_primary_dept"`  // 是否为用户的主部门
+}
+
+type UserCustomAttr struct {
+	Type  string `json:"type"` // 自定义字段类型,可选值有TEXT、HREF、ENUMERATION、PICTURE_ENUM、GENERIC_USER
+	Id    string `json:"id"`   // 自定义字段ID
+	Value struct {
+		Text        string `json:"text"`         // 字段类型为TEXT时该参数定义字段值
+		Url         string `json:"url"`          // 字段类型为HREF时,该参数定义默认URL
+		PcUrl       string `json:"pc_url"`       // 字段类型为HREF时,该参数定义PC端URL
+		OptionId    string `json:"option_id"`    // 字段类型为ENUMERATION或PICTURE_ENUM时,该参数定义选项值
+		OptionValue string `json:"option_value"` // 选项值
+		Name        string `json:"name"`         // 名称
+		PictureUrl  string `json:"picture_url"`  // 图片链接
+		GenericUser struct {
+			Id   string `json:"id"`   // 用户的user_id
+			Type int    `json:"type"` // 用户类型,1:用户
+		} `json:"generic_user"` // 字段类型为GENERIC_USER时,该参数定义引用人员
+	} `json:"value"` // 自定义字段取值
+}
+
+type UserPosition struct {
+	PositionCode       string `json:"position_code"`        // 岗位Code
+	PositionName       string `json:"position_name"`        // 岗位名称
+	DepartmentId       string `json:"department_id"`        // 岗位部门ID
+	LeaderUserId       string `json:"leader_user_id"`       // 上级领导ID
+	LeaderPositionCode string `json:"leader_position_code"` // 本岗位领导的岗位code
+	IsMajor            bool   `json:"is_major"`             // 是否主岗
 }
 
 type GetUsersByDepartmentIdReqBuilder struct {
@@ -128,14 +177,13 @@ func (u *user) GetUsersByDepartmentId(req *GetUsersByDepartmentIdReq) ([]*UserEn
 		return nil, err
 	}
 	if tmp.Code != 0 {
-		return nil, fmt.Errorf("from server - " + tmp.Msg)
+		return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
 	}
 	var users []*UserEntry
 	users = append(users, tmp.Data.Items...)
 	if !tmp.Data.HasMore {
 		return users, nil
 	}
-	time.Sleep(defaultInterval)
 	user, err := u.moreUser(req.req.Client, req, tmp.Data.PageToken)
 	if err != nil {
 		return nil, err
@@ -179,14 +227,13 @@ func (u *user) moreUser(f *Client, req *GetUsersByDepartmentIdReq, pageToken str
 		return nil, err
 	}
 	if tmp.Code != 0 {
-		return nil, fmt.Errorf("from server - " + tmp.Msg)
+		return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
 	}
 	var users []*UserEntry
 	users = append(users, tmp.Data.Items...)
 	if !tmp.Data.HasMore {
 		return users, nil
 	}
-	time.Sleep(defaultInterval)
 	moreUsers, err := u.moreUser(f, req, tmp.Data.PageToken)
 	if err != nil {
 		return nil, err
@@ -270,7 +317,7 @@ func (u *user) Get(req *GetUserReq) (*UserEntry, error) {
 		return nil, err
 	}
 	if tmp.Code != 0 {
-		return nil, fmt.Errorf("from server - " + tmp.Msg)
+		return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
 	}
 	userEntry = &tmp.Data.User
 	return userEntry, nil
@@ -360,7 +407,110 @@ func (u *user) EmailPasswordUpdate(req *UserEmailPasswordChangeReq) error {
 		return err
 	}
 	if tmp.Code != 0 {
-		return fmt.Errorf("from server - " + tmp.Msg)
+		return plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
 	}
 	return nil
 }
+
+type UserIdEntry struct {
+	UserId string `json:"user_id"` // 用户ID,类型与查询参数中的user_id_type对应,未找到用户时为空
+	Mobile string `json:"mobile"`  // 手机号,通过手机号查询时返回
+	Email  string `json:"email"`   // 邮箱,通过邮箱查询时返回
+	Status struct {
+		IsFrozen    bool `json:"is_frozen"`
+		IsResigned  bool `json:"is_resigned"`
+		IsActivated bool `json:"is_activated"`
+		IsExited    bool `json:"is_exited"`
+		IsUnjoin    bool `json:"is_unjoin"`
+	} `json:"status"`
+}
+
+type BatchGetUserIdReqBuilder struct {
+	req  Req
+	body struct {
+		Emails          []string `json:"emails,omitempty"`
+		Mobiles         []string `json:"mobiles,omitempty"`
+		IncludeResigned bool     `json:"include_resigned"`
+	}
+}
+
+type BatchGetUserIdReq struct {
+	req Req
+}
+
+func NewBatchGetUserIdReqBuilder(f *Client) *BatchGetUserIdReqBuilder {
+	builder := &BatchGetUserIdReqBuilder{}
+	builder.req = Req{
+		PathParams:  &plugin.PathParams{},
+		QueryParams: &plugin.QueryParams{},
+		Client:      f,
+	}
+	return builder
+}
+
+func (builder *BatchGetUserIdReqBuilder) UserIdType(t string) *BatchGetUserIdReqBuilder {
+	builder.req.QueryParams.Set("user_id_type", t)
+	return builder
+}
+
+// Emails 要查询的邮箱,单次最多50个
+func (builder *BatchGetUserIdReqBuilder) Emails(emails []string) *BatchGetUserIdReqBuilder {
+	builder.body.Emails = emails
+	return builder
+}
+
+// Mobiles 要查询的手机号,单次最多50个
+func (builder *BatchGetUserIdReqBuilder) Mobiles(mobiles []string) *BatchGetUserIdReqBuilder {
+	builder.body.Mobiles = mobiles
+	return builder
+}
+
+// IncludeResigned 查询结果是否包含离职员工
+func (builder *BatchGetUserIdReqBuilder) IncludeResigned(include bool) *BatchGetUserIdReqBuilder {
+	builder.body.IncludeResigned = include
+	return builder
+}
+
+func (builder *BatchGetUserIdReqBuilder) Build() *BatchGetUserIdReq {
+	req := &BatchGetUserIdReq{}
+	req.req = builder.req
+	req.req.Body = builder.body
+	return req
+}
+
+// BatchGetId 通过手机号或邮箱获取用户ID
+func (u *user) BatchGetId(req *BatchGetUserIdReq) ([]*UserIdEntry, error) {
+	request, err := http.NewRequest("POST", fmt.Sprintf("%s?%s", batchGetUserIdUrl, req.req.QueryParams.Encode()), utils.ConvertToReader(req.req.Body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	token, err := req.req.Client.autoGetTenantAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	response, err := req.req.Client.http.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ghttp.GetResponseBody(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	var tmp struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			UserList []*UserIdEntry `json:"user_list"`
+		} `json:"data"`
+	}
+	err = json.Unmarshal(body, &tmp)
+	if err != nil {
+		return nil, err
+	}
+	if tmp.Code != 0 {
+		return nil, plugin.NewFeishuError(tmp.Code, tmp.Msg, response)
+	}
+	return tmp.Data.UserList, nil
+}
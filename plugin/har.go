@@ -0,0 +1,107 @@
+package plugin
+
+import (
+	"encoding/json"
+	"idebug/config"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func harHeaders(header http.Header) []harNameValue {
+	var keys []string
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	headers := []harNameValue{}
+	for _, key := range keys {
+		for _, value := range header[key] {
+			headers = append(headers, harNameValue{Name: key, Value: value})
+		}
+	}
+	return headers
+}
+
+func harMimeType(header http.Header) string {
+	mimeType := header.Get("Content-Type")
+	if mimeType == "" {
+		return "application/octet-stream"
+	}
+	return mimeType
+}
+
+// HAR 将请求记录导出为HAR 1.2格式,二进制请求体不导出内容
+func HAR(calls []*Call) ([]byte, error) {
+	entries := []map[string]any{}
+	for _, call := range calls {
+		queryString := []harNameValue{}
+		if u, err := url.Parse(call.Url); err == nil && u.RawQuery != "" {
+			for _, pair := range strings.Split(u.RawQuery, "&") {
+				key, value, _ := strings.Cut(pair, "=")
+				queryString = append(queryString, harNameValue{Name: key, Value: value})
+			}
+		}
+		request := map[string]any{
+			"method":      call.Method,
+			"url":         call.Url,
+			"httpVersion": "HTTP/1.1",
+			"headers":     harHeaders(call.Header),
+			"queryString": queryString,
+			"cookies":     []any{},
+			"headersSize": -1,
+			"bodySize":    len(call.Body),
+		}
+		if len(call.Body) > 0 {
+			postData := map[string]any{"mimeType": harMimeType(call.Header), "text": ""}
+			if isTextBody(call) {
+				postData["text"] = string(call.Body)
+			}
+			request["postData"] = postData
+		}
+		// Status为"200 OK"格式
+		_, statusText, _ := strings.Cut(call.Status, " ")
+		content := map[string]any{"size": len(call.ResponseBody), "mimeType": harMimeType(call.ResponseHeader)}
+		if len(call.ResponseBody) > 0 {
+			content["text"] = string(call.ResponseBody)
+		}
+		response := map[string]any{
+			"status":      call.StatusCode,
+			"statusText":  statusText,
+			"httpVersion": "HTTP/1.1",
+			"headers":     harHeaders(call.ResponseHeader),
+			"cookies":     []any{},
+			"content":     content,
+			"redirectURL": "",
+			"headersSize": -1,
+			"bodySize":    len(call.ResponseBody),
+		}
+		if call.Err != nil {
+			response["_error"] = call.Err.Error()
+		}
+		wait := float64(call.Duration) / float64(time.Millisecond)
+		entries = append(entries, map[string]any{
+			"startedDateTime": call.Time.Format("2006-01-02T15:04:05.000Z07:00"),
+			"time":            wait,
+			"request":         request,
+			"response":        response,
+			"cache":           map[string]any{},
+			"timings":         map[string]any{"send": 0, "wait": wait, "receive": 0},
+		})
+	}
+	har := map[string]any{
+		"log": map[string]any{
+			"version": "1.2",
+			"creator": map[string]any{"name": "idebug", "version": config.Version},
+			"entries": entries,
+		},
+	}
+	return json.MarshalIndent(har, "", "  ")
+}
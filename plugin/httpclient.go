@@ -0,0 +1,168 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"github.com/fasnow/ghttp"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Call 一次经过HttpClient的请求记录
+type Call struct {
+	Time           time.Time
+	Method         string
+	Url            string
+	Header         http.Header
+	Body           []byte
+	StatusCode     int           // 请求失败时为0
+	Status         string        // 响应状态,如200 OK
+	ResponseHeader http.Header   // 响应头
+	ResponseBody   []byte        // 响应体
+	Duration       time.Duration // 请求耗时,包含读取响应体
+	Err            error
+}
+
+// Hook 请求完成后调用,不能修改call
+type Hook func(call *Call)
+
+var (
+	hooks     []Hook
+	hooksLock sync.RWMutex
+)
+
+// AddHook 注册请求完成后的回调,对所有模块的HttpClient生效
+func AddHook(hook Hook) {
+	hooksLock.Lock()
+	defer hooksLock.Unlock()
+	hooks = append(hooks, hook)
+}
+
+// HttpClient 包装ghttp.Client,各模块的请求都通过Do发送,以便统一记录请求、限速和重试
+type HttpClient struct {
+	*ghttp.Client
+	Limiter *RateLimiter
+	Retry   *RetryPolicy
+	// RefreshToken 凭证过期时获取新的凭证并写入request,返回false表示无法刷新
+	RefreshToken func(request *http.Request) bool
+	platform     string
+}
+
+// NewHttpClient 创建使用平台默认限速和重试策略的HttpClient,platform为wechat或feishu
+func NewHttpClient(platform string) *HttpClient {
+	return &HttpClient{
+		Client:   &ghttp.Client{},
+		Limiter:  NewRateLimiter(platform),
+		Retry:    NewRetryPolicy(),
+		platform: platform,
+	}
+}
+
+// Do 发送请求,按Retry重试网络错误、5xx和频率限制,凭证过期时刷新凭证后重试一次
+func (c *HttpClient) Do(request *http.Request) (*http.Response, error) {
+	var body []byte
+	if request.Body != nil {
+		var err error
+		body, err = io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	ctx := request.Context()
+	if c.Client.Context != nil && c.Client.StopWhenContextCanceled {
+		ctx = *c.Client.Context
+	}
+	start := time.Now()
+	refreshed := false
+	retries := 0
+	for {
+		if body != nil {
+			request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		response, responseBody, err := c.do(ctx, request, body)
+		class := classify(c.platform, request, response, responseBody, err)
+		switch class {
+		case ErrorClassNone, ErrorClassPermanent:
+			return response, err
+		case ErrorClassTokenExpired:
+			if refreshed || c.RefreshToken == nil || !c.RefreshToken(request) {
+				return response, err
+			}
+			refreshed = true
+			continue
+		}
+		if c.Retry == nil || retries >= c.Retry.Retries {
+			return response, err
+		}
+		retries++
+		delay := c.Retry.backoff(retries)
+		// 限速器已经暂停该类别的请求时,下次请求会在Wait中等待暂停结束,不再额外退避
+		var paused time.Duration
+		if class == ErrorClassRateLimit && c.Limiter != nil {
+			paused = c.Limiter.Paused(request.URL)
+		}
+		if paused > delay {
+			delay = paused
+		}
+		if c.Retry.Deadline > 0 && time.Since(start)+delay > c.Retry.Deadline {
+			return response, err
+		}
+		if c.Retry.OnRetry != nil {
+			c.Retry.OnRetry(class, retries, delay)
+		}
+		if paused > 0 {
+			continue
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// do 发送一次请求并调用hooks,返回的响应体已读取并重新放回response
+func (c *HttpClient) do(ctx context.Context, request *http.Request, body []byte) (*http.Response, []byte, error) {
+	if c.Limiter != nil {
+		if err := c.Limiter.Wait(ctx, request.URL); err != nil {
+			return nil, nil, err
+		}
+	}
+	call := &Call{
+		Time:   time.Now(),
+		Method: request.Method,
+		Url:    request.URL.String(),
+		Header: request.Header.Clone(),
+		Body:   body,
+	}
+	response, err := c.Client.Do(request)
+	if response != nil {
+		call.StatusCode = response.StatusCode
+		call.Status = response.Status
+		call.ResponseHeader = response.Header.Clone()
+		// 读取响应体后重新放回,调用方仍然可以正常读取
+		data, readErr := io.ReadAll(response.Body)
+		response.Body.Close()
+		response.Body = io.NopCloser(bytes.NewReader(data))
+		call.ResponseBody = data
+		if c.Limiter != nil {
+			c.Limiter.Observe(request.URL, response, data)
+		}
+		if err == nil {
+			err = readErr
+		}
+	}
+	call.Duration = time.Since(call.Time)
+	call.Err = err
+	hooksLock.RLock()
+	for _, hook := range hooks {
+		hook(call)
+	}
+	hooksLock.RUnlock()
+	return response, call.ResponseBody, err
+}
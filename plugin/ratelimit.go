@@ -0,0 +1,224 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultEndpointClass 未匹配任何路径前缀的接口类别
+const DefaultEndpointClass = "default"
+
+// maxBackoffShift 连续触发频率限制时的最大退避时间为1s<<maxBackoffShift
+const maxBackoffShift = 5
+
+// defaultQPS 各平台接口类别的默认QPS,参考官方文档的频率限制并留有余量
+var defaultQPS = map[string]map[string]float64{
+	PlatformFeishu: {DefaultEndpointClass: 10, "auth": 5, "contact": 10, "im": 5, "bitable": 10, "corehr": 5},
+	PlatformWechat: {DefaultEndpointClass: 20, "token": 5, "contact": 20},
+}
+
+// endpointClasses 接口路径前缀与接口类别的对应关系
+var endpointClasses = map[string][][2]string{
+	PlatformFeishu: {
+		{"/open-apis/auth/", "auth"},
+		{"/open-apis/contact/", "contact"},
+		{"/open-apis/im/", "im"},
+		{"/open-apis/bitable/", "bitable"},
+		{"/open-apis/corehr/", "corehr"},
+	},
+	PlatformWechat: {
+		{"/cgi-bin/gettoken", "token"},
+		{"/cgi-bin/service/get_", "token"},
+		{"/cgi-bin/user/", "contact"},
+		{"/cgi-bin/department/", "contact"},
+	},
+}
+
+// rateLimitCodes 表示触发频率限制的错误码
+var rateLimitCodes = map[string][]int{
+	PlatformFeishu: {99991400},
+	PlatformWechat: {45009, 45011, 45033},
+}
+
+type bucket struct {
+	qps         float64 // 为0时不限速
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time // 触发频率限制后暂停至该时间
+	hits        int       // 连续触发频率限制的次数
+}
+
+// reserve 取出一个令牌,返回需要等待的时间
+func (b *bucket) reserve(now time.Time) time.Duration {
+	start := now
+	if b.pausedUntil.After(start) {
+		start = b.pausedUntil
+	}
+	if b.qps <= 0 {
+		return start.Sub(now)
+	}
+	burst := math.Max(1, b.qps)
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else if elapsed := start.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed*b.qps)
+	}
+	b.last = start
+	b.tokens--
+	wait := start.Sub(now)
+	if b.tokens < 0 {
+		wait += time.Duration(-b.tokens / b.qps * float64(time.Second))
+	}
+	return wait
+}
+
+// RateLimiter 按接口类别分别限速的令牌桶,触发频率限制时暂停该类别的请求
+type RateLimiter struct {
+	platform string
+	buckets  map[string]*bucket
+	lock     sync.Mutex
+	// OnBackoff 触发频率限制时调用,可用于提示
+	OnBackoff func(class string, wait time.Duration)
+}
+
+// NewRateLimiter 创建使用平台默认QPS的限速器,platform为wechat或feishu
+func NewRateLimiter(platform string) *RateLimiter {
+	l := &RateLimiter{platform: platform, buckets: map[string]*bucket{}}
+	for class, qps := range defaultQPS[platform] {
+		l.buckets[class] = &bucket{qps: qps}
+	}
+	return l
+}
+
+// Classes 返回所有接口类别
+func (l *RateLimiter) Classes() []string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	var classes []string
+	for class := range l.buckets {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	return classes
+}
+
+// QPS 返回接口类别的QPS,0表示不限速
+func (l *RateLimiter) QPS(class string) float64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if b, ok := l.buckets[class]; ok {
+		return b.qps
+	}
+	return 0
+}
+
+// SetQPS 设置接口类别的QPS,class为空时设置所有类别,qps为0时不限速
+func (l *RateLimiter) SetQPS(class string, qps float64) error {
+	if qps < 0 {
+		return fmt.Errorf("QPS不能小于0")
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if class == "" {
+		for _, b := range l.buckets {
+			b.qps = qps
+		}
+		return nil
+	}
+	b, ok := l.buckets[class]
+	if !ok {
+		return fmt.Errorf("不支持的接口类别: %s", class)
+	}
+	b.qps = qps
+	return nil
+}
+
+// Reset 恢复平台默认QPS
+func (l *RateLimiter) Reset() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for class, qps := range defaultQPS[l.platform] {
+		l.buckets[class].qps = qps
+	}
+}
+
+// Class 返回接口所属的类别
+func (l *RateLimiter) Class(u *url.URL) string {
+	for _, prefix := range endpointClasses[l.platform] {
+		if strings.HasPrefix(u.Path, prefix[0]) {
+			return prefix[1]
+		}
+	}
+	return DefaultEndpointClass
+}
+
+// Wait 等待直到可以发送请求,ctx取消时返回ctx.Err()
+func (l *RateLimiter) Wait(ctx context.Context, u *url.URL) error {
+	l.lock.Lock()
+	wait := l.buckets[l.Class(u)].reserve(time.Now())
+	l.lock.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Paused 返回接口所属类别因频率限制剩余的暂停时间,没有暂停时返回0
+func (l *RateLimiter) Paused(u *url.URL) time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if wait := time.Until(l.buckets[l.Class(u)].pausedUntil); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// Observe 根据响应判断是否触发频率限制,触发时按x-ogw-ratelimit-reset或者指数退避暂停该类别的请求
+func (l *RateLimiter) Observe(u *url.URL, response *http.Response, body []byte) {
+	class := l.Class(u)
+	limited := response.StatusCode == http.StatusTooManyRequests || l.isRateLimitBody(body)
+	l.lock.Lock()
+	b := l.buckets[class]
+	if !limited {
+		b.hits = 0
+		l.lock.Unlock()
+		return
+	}
+	shift := b.hits
+	if shift > maxBackoffShift {
+		shift = maxBackoffShift
+	}
+	b.hits++
+	wait := time.Second << shift
+	if reset, err := strconv.Atoi(response.Header.Get("x-ogw-ratelimit-reset")); err == nil && reset > 0 {
+		wait = time.Duration(reset) * time.Second
+	}
+	if until := time.Now().Add(wait); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+	onBackoff := l.OnBackoff
+	l.lock.Unlock()
+	if onBackoff != nil {
+		onBackoff(class, wait)
+	}
+}
+
+func (l *RateLimiter) isRateLimitBody(body []byte) bool {
+	code, ok := resultCode(body)
+	return ok && inCodes(rateLimitCodes[l.platform], code)
+}
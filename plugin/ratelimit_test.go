@@ -0,0 +1,140 @@
+package plugin
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestBucketReserve(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		bucket bucket
+		at     []time.Duration // 相对t0的请求时间
+		want   []time.Duration
+	}{
+		{
+			name:   "先消耗突发令牌再按QPS等待",
+			bucket: bucket{qps: 2},
+			at:     []time.Duration{0, 0, 0, 0},
+			want:   []time.Duration{0, 0, 500 * time.Millisecond, time.Second},
+		},
+		{
+			name:   "等待后按经过的时间补充令牌",
+			bucket: bucket{qps: 2},
+			at:     []time.Duration{0, 0, 0, 0, time.Second, 2 * time.Second},
+			want:   []time.Duration{0, 0, 500 * time.Millisecond, time.Second, 500 * time.Millisecond, 0},
+		},
+		{
+			name:   "QPS小于1时突发为1",
+			bucket: bucket{qps: 0.5},
+			at:     []time.Duration{0, 0},
+			want:   []time.Duration{0, 2 * time.Second},
+		},
+		{
+			name:   "QPS为0时不限速",
+			bucket: bucket{qps: 0},
+			at:     []time.Duration{0, 0, 0},
+			want:   []time.Duration{0, 0, 0},
+		},
+		{
+			name:   "暂停期间等待至暂停结束",
+			bucket: bucket{qps: 10, pausedUntil: t0.Add(3 * time.Second)},
+			at:     []time.Duration{0, time.Second, 4 * time.Second},
+			want:   []time.Duration{3 * time.Second, 2 * time.Second, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.bucket
+			for i, at := range tt.at {
+				if got := b.reserve(t0.Add(at)); got != tt.want[i] {
+					t.Errorf("第%d次请求等待%v,期望%v", i+1, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterObserve(t *testing.T) {
+	u, _ := url.Parse("https://open.feishu.cn/open-apis/im/v1/messages")
+	limited := func(status int, reset string) *http.Response {
+		header := http.Header{}
+		if reset != "" {
+			header.Set("x-ogw-ratelimit-reset", reset)
+		}
+		return &http.Response{StatusCode: status, Header: header}
+	}
+	tests := []struct {
+		name      string
+		responses []*http.Response
+		bodies    []string
+		want      []time.Duration // 每次触发频率限制时的暂停时间
+	}{
+		{
+			name:      "连续触发时指数退避",
+			responses: []*http.Response{limited(429, ""), limited(429, ""), limited(429, "")},
+			bodies:    []string{"", "", ""},
+			want:      []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+		},
+		{
+			name:      "x-ogw-ratelimit-reset优先于指数退避",
+			responses: []*http.Response{limited(429, ""), limited(429, "7")},
+			bodies:    []string{"", ""},
+			want:      []time.Duration{time.Second, 7 * time.Second},
+		},
+		{
+			name:      "响应体中的频率限制错误码",
+			responses: []*http.Response{limited(200, "3")},
+			bodies:    []string{`{"code":99991400,"msg":"request trigger frequency limit"}`},
+			want:      []time.Duration{3 * time.Second},
+		},
+		{
+			name:      "请求成功后重新计算退避",
+			responses: []*http.Response{limited(429, ""), limited(200, ""), limited(429, "")},
+			bodies:    []string{"", `{"code":0}`, ""},
+			want:      []time.Duration{time.Second, time.Second},
+		},
+		{
+			name:      "其它错误不暂停",
+			responses: []*http.Response{limited(500, "5"), limited(200, "5")},
+			bodies:    []string{"", `{"code":99991663}`},
+			want:      nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewRateLimiter(PlatformFeishu)
+			var got []time.Duration
+			l.OnBackoff = func(class string, wait time.Duration) {
+				if class != "im" {
+					t.Errorf("接口类别为%s,期望im", class)
+				}
+				got = append(got, wait)
+			}
+			for i, response := range tt.responses {
+				l.Observe(u, response, []byte(tt.bodies[i]))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("暂停时间为%v,期望%v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("第%d次暂停%v,期望%v", i+1, got[i], tt.want[i])
+				}
+			}
+			paused := l.Paused(u)
+			if len(tt.want) == 0 {
+				if paused != 0 {
+					t.Errorf("剩余暂停时间%v,期望0", paused)
+				}
+				return
+			}
+			if last := tt.want[len(tt.want)-1]; paused <= 0 || paused > last {
+				t.Errorf("剩余暂停时间%v,期望不超过%v", paused, last)
+			}
+		})
+	}
+}
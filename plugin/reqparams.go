@@ -1,6 +1,9 @@
 package plugin
 
-import "net/url"
+import (
+	"net/url"
+	"strings"
+)
 
 type PathParams map[string]string
 
@@ -35,3 +38,16 @@ func (u QueryParams) Encode() string {
 func (u QueryParams) Add(key, value string) {
 	u[key] = append(u[key], value)
 }
+
+// SameOrigin 判断完整URL与接口域名的协议和主机是否一致,不一致时不能携带凭证
+func SameOrigin(rawUrl, base string) bool {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return false
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == b.Scheme && strings.EqualFold(u.Host, b.Host)
+}
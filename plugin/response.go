@@ -0,0 +1,20 @@
+package plugin
+
+import (
+	"net/http"
+	"time"
+)
+
+// RawResponse 原始接口响应,用于调试任意接口
+type RawResponse struct {
+	Method     string
+	Url        string
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+	Duration   time.Duration // 请求耗时,包含读取响应体
+}
+
+// CallFunc 调用任意接口,由各模块负责注入凭证和拼接接口域名
+type CallFunc func(method, path string, query [][2]string, body []byte) (*RawResponse, error)
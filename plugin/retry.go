@@ -0,0 +1,131 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// ErrorClass 请求失败的类别,决定是否重试
+type ErrorClass string
+
+const (
+	ErrorClassNone         ErrorClass = ""              // 请求成功或者接口返回的业务错误
+	ErrorClassNetwork      ErrorClass = "network"       // 网络错误
+	ErrorClassServer       ErrorClass = "server"        // HTTP 5xx或者服务端繁忙
+	ErrorClassRateLimit    ErrorClass = "rate_limit"    // 触发频率限制
+	ErrorClassTokenExpired ErrorClass = "token_expired" // 凭证过期或者失效
+	ErrorClassPermanent    ErrorClass = "permanent"     // 重试无效的错误,如请求被取消
+)
+
+// tokenExpiredCodes 表示凭证过期或者失效的错误码
+var tokenExpiredCodes = map[string][]int{
+	PlatformFeishu: {99991663, 99991677},
+	PlatformWechat: {40014, 42001},
+}
+
+// serverBusyCodes 表示服务端繁忙的错误码
+var serverBusyCodes = map[string][]int{
+	PlatformWechat: {-1},
+}
+
+// RetryPolicy 重试策略,使用带随机抖动的指数退避
+type RetryPolicy struct {
+	Retries   int           // 最大重试次数,0表示不重试
+	Deadline  time.Duration // 包含重试在内的总时长上限,0表示不限制
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// OnRetry 重试前调用,可用于提示
+	OnRetry func(class ErrorClass, n int, delay time.Duration)
+}
+
+const (
+	DefaultRetries       = 3
+	DefaultRetryDeadline = time.Minute
+)
+
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		Retries:   DefaultRetries,
+		Deadline:  DefaultRetryDeadline,
+		BaseDelay: 500 * time.Millisecond,
+		MaxDelay:  10 * time.Second,
+	}
+}
+
+// backoff 第n次重试前的等待时间,在指数退避时间的50%~100%之间随机
+func (p *RetryPolicy) backoff(n int) time.Duration {
+	delay := p.MaxDelay
+	if shift := n - 1; shift < 16 && p.BaseDelay<<shift < p.MaxDelay {
+		delay = p.BaseDelay << shift
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// classify 根据响应判断请求失败的类别
+func classify(platform string, request *http.Request, response *http.Response, body []byte, err error) ErrorClass {
+	if err != nil && response == nil {
+		// 非幂等请求可能已经被服务端处理,重试可能导致重复执行
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || !idempotent(request.Method) {
+			return ErrorClassPermanent
+		}
+		return ErrorClassNetwork
+	}
+	if response == nil {
+		return ErrorClassNone
+	}
+	code, ok := resultCode(body)
+	switch {
+	case response.StatusCode == http.StatusTooManyRequests || (ok && inCodes(rateLimitCodes[platform], code)):
+		return ErrorClassRateLimit
+	case ok && inCodes(tokenExpiredCodes[platform], code):
+		return ErrorClassTokenExpired
+	case response.StatusCode >= 500 || (ok && inCodes(serverBusyCodes[platform], code)):
+		if !idempotent(request.Method) {
+			return ErrorClassPermanent
+		}
+		return ErrorClassServer
+	}
+	return ErrorClassNone
+}
+
+// resultCode 解析飞书的code或者企业微信的errcode
+func resultCode(body []byte) (int, bool) {
+	if len(body) == 0 || body[0] != '{' {
+		return 0, false
+	}
+	var result struct {
+		Code    *int `json:"code"`
+		ErrCode *int `json:"errcode"`
+	}
+	if json.Unmarshal(body, &result) != nil {
+		return 0, false
+	}
+	if result.Code != nil {
+		return *result.Code, true
+	}
+	if result.ErrCode != nil {
+		return *result.ErrCode, true
+	}
+	return 0, false
+}
+
+func inCodes(codes []int, code int) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	errNetwork := errors.New("connection reset by peer")
+	tests := []struct {
+		name     string
+		platform string
+		method   string
+		status   int // 为0时表示没有响应
+		body     string
+		err      error
+		want     ErrorClass
+	}{
+		{"GET网络错误", PlatformFeishu, http.MethodGet, 0, "", errNetwork, ErrorClassNetwork},
+		{"POST网络错误", PlatformFeishu, http.MethodPost, 0, "", errNetwork, ErrorClassPermanent},
+		{"请求被取消", PlatformFeishu, http.MethodGet, 0, "", fmt.Errorf("do: %w", context.Canceled), ErrorClassPermanent},
+		{"请求超时", PlatformWechat, http.MethodGet, 0, "", context.DeadlineExceeded, ErrorClassPermanent},
+		{"GET 5xx", PlatformFeishu, http.MethodGet, http.StatusBadGateway, "", nil, ErrorClassServer},
+		{"POST 5xx", PlatformFeishu, http.MethodPost, http.StatusBadGateway, "", nil, ErrorClassPermanent},
+		{"PATCH 5xx", PlatformFeishu, http.MethodPatch, http.StatusServiceUnavailable, "", nil, ErrorClassPermanent},
+		{"PUT 5xx", PlatformFeishu, http.MethodPut, http.StatusInternalServerError, "", nil, ErrorClassServer},
+		{"HTTP 429", PlatformFeishu, http.MethodPost, http.StatusTooManyRequests, "", nil, ErrorClassRateLimit},
+		{"飞书频率限制错误码", PlatformFeishu, http.MethodGet, http.StatusOK, `{"code":99991400,"msg":"frequency limit"}`, nil, ErrorClassRateLimit},
+		{"企业微信频率限制错误码", PlatformWechat, http.MethodGet, http.StatusOK, `{"errcode":45009,"errmsg":"api freq out of limit"}`, nil, ErrorClassRateLimit},
+		{"飞书凭证过期", PlatformFeishu, http.MethodPost, http.StatusOK, `{"code":99991663,"msg":"invalid token"}`, nil, ErrorClassTokenExpired},
+		{"企业微信凭证过期", PlatformWechat, http.MethodGet, http.StatusOK, `{"errcode":42001,"errmsg":"access_token expired"}`, nil, ErrorClassTokenExpired},
+		{"企业微信系统繁忙GET", PlatformWechat, http.MethodGet, http.StatusOK, `{"errcode":-1,"errmsg":"system busy"}`, nil, ErrorClassServer},
+		{"企业微信系统繁忙POST", PlatformWechat, http.MethodPost, http.StatusOK, `{"errcode":-1,"errmsg":"system busy"}`, nil, ErrorClassPermanent},
+		{"错误码只匹配对应平台", PlatformFeishu, http.MethodGet, http.StatusOK, `{"code":42001}`, nil, ErrorClassNone},
+		{"业务错误不重试", PlatformFeishu, http.MethodGet, http.StatusOK, `{"code":99991672,"msg":"no permission"}`, nil, ErrorClassNone},
+		{"请求成功", PlatformWechat, http.MethodGet, http.StatusOK, `{"errcode":0}`, nil, ErrorClassNone},
+		{"非JSON响应", PlatformWechat, http.MethodGet, http.StatusOK, `<html></html>`, nil, ErrorClassNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, _ := http.NewRequest(tt.method, "https://example.com/api", nil)
+			var response *http.Response
+			if tt.status != 0 {
+				response = &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			}
+			if got := classify(tt.platform, request, response, []byte(tt.body), tt.err); got != tt.want {
+				t.Errorf("classify() = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := &RetryPolicy{BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second}
+	tests := []struct {
+		n        int
+		min, max time.Duration
+	}{
+		{1, 250 * time.Millisecond, 500 * time.Millisecond},
+		{2, 500 * time.Millisecond, time.Second},
+		{5, 4 * time.Second, 8 * time.Second},
+		{6, 5 * time.Second, 10 * time.Second},  // 超过MaxDelay
+		{64, 5 * time.Second, 10 * time.Second}, // 位移溢出
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("第%d次重试", tt.n), func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := p.backoff(tt.n); got < tt.min || got > tt.max {
+					t.Fatalf("backoff(%d) = %v, 期望在%v~%v之间", tt.n, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SnippetFormats 支持生成的代码片段格式
+var SnippetFormats = []string{"curl", "httpie", "go"}
+
+// secretKeys 查询参数和JSON请求体中需要隐藏的凭证字段
+var secretKeys = []string{"access_token", "corpsecret", "suite_secret", "suite_ticket", "provider_secret", "permanent_code",
+	"suite_access_token", "provider_access_token", "app_secret", "app_access_token", "tenant_access_token", "password"}
+
+// secretHeaders 需要隐藏的请求头和响应头
+var secretHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+const maskedValue = "****"
+
+func isSecretKey(key string) bool {
+	for _, k := range secretKeys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskCall 返回隐藏凭证后的请求记录副本,请求和响应中的凭证都会隐藏
+func MaskCall(call *Call) *Call {
+	masked := *call
+	masked.Url = maskUrl(call.Url)
+	masked.Header = maskHeader(call.Header)
+	masked.Body = maskBody(call.Body)
+	masked.ResponseHeader = maskHeader(call.ResponseHeader)
+	masked.ResponseBody = maskBody(call.ResponseBody)
+	return &masked
+}
+
+func maskHeader(header http.Header) http.Header {
+	if header == nil {
+		return nil
+	}
+	header = header.Clone()
+	for _, key := range secretHeaders {
+		value := header.Get(key)
+		if value == "" {
+			continue
+		}
+		if scheme, _, ok := strings.Cut(value, " "); ok && key == "Authorization" {
+			header.Set(key, scheme+" "+maskedValue)
+		} else {
+			header.Set(key, maskedValue)
+		}
+	}
+	return header
+}
+
+// maskUrl 隐藏查询参数中的凭证,其余参数保持原样
+func maskUrl(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.RawQuery == "" {
+		return raw
+	}
+	u.RawQuery, _ = maskQuery(u.RawQuery)
+	return u.String()
+}
+
+// maskQuery 隐藏查询字符串或者表单中的凭证,保持参数的顺序和编码,返回是否有参数被隐藏
+func maskQuery(raw string) (string, bool) {
+	var masked bool
+	pairs := strings.Split(raw, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil && isSecretKey(name) {
+			pairs[i] = key + "=" + maskedValue
+			masked = true
+		}
+	}
+	return strings.Join(pairs, "&"), masked
+}
+
+// maskBody 隐藏JSON或者application/x-www-form-urlencoded请求体中的凭证,其它请求体保持原样
+func maskBody(body []byte) []byte {
+	var v any
+	if len(body) == 0 {
+		return body
+	}
+	if json.Unmarshal(body, &v) != nil {
+		return maskForm(body)
+	}
+	var walk func(v any) any
+	walk = func(v any) any {
+		switch value := v.(type) {
+		case map[string]any:
+			for key, item := range value {
+				if isSecretKey(key) {
+					value[key] = maskedValue
+				} else {
+					value[key] = walk(item)
+				}
+			}
+		case []any:
+			for i, item := range value {
+				value[i] = walk(item)
+			}
+		}
+		return v
+	}
+	data, err := json.Marshal(walk(v))
+	if err != nil {
+		return body
+	}
+	return data
+}
+
+// maskForm 按表单解析请求体并隐藏凭证,不是表单或者不含凭证时保持原样
+func maskForm(body []byte) []byte {
+	if _, err := url.ParseQuery(string(body)); err != nil {
+		return body
+	}
+	masked, ok := maskQuery(string(body))
+	if !ok {
+		return body
+	}
+	return []byte(masked)
+}
+
+// isTextBody 判断请求体能否作为文本输出,上传文件等二进制请求体不输出
+func isTextBody(call *Call) bool {
+	return utf8.Valid(call.Body) && !strings.HasPrefix(call.Header.Get("Content-Type"), "multipart/")
+}
+
+// snippetHeaders 返回需要输出的请求头,按名称排序,忽略空值
+func snippetHeaders(call *Call) [][2]string {
+	var keys []string
+	for key := range call.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var headers [][2]string
+	for _, key := range keys {
+		for _, value := range call.Header[key] {
+			if value != "" {
+				headers = append(headers, [2]string{key, value})
+			}
+		}
+	}
+	return headers
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Snippet 根据格式生成可复现请求的命令或者代码
+func Snippet(format string, call *Call) (string, error) {
+	switch format {
+	case "curl":
+		return Curl(call), nil
+	case "httpie":
+		return HTTPie(call), nil
+	case "go":
+		return GoSnippet(call), nil
+	}
+	return "", fmt.Errorf("不支持的格式: %s,可选值: %s", format, strings.Join(SnippetFormats, "、"))
+}
+
+// Curl 生成curl命令
+func Curl(call *Call) string {
+	lines := []string{fmt.Sprintf("curl -X %s %s", call.Method, shellQuote(call.Url))}
+	for _, header := range snippetHeaders(call) {
+		lines = append(lines, "-H "+shellQuote(header[0]+": "+header[1]))
+	}
+	if len(call.Body) > 0 {
+		if isTextBody(call) {
+			lines = append(lines, "--data-raw "+shellQuote(string(call.Body)))
+		} else {
+			lines = append(lines, fmt.Sprintf("--data-binary @body.bin # 请求体为二进制数据(%d字节),已省略", len(call.Body)))
+		}
+	}
+	return strings.Join(lines, " \\\n  ")
+}
+
+// HTTPie 生成HTTPie命令
+func HTTPie(call *Call) string {
+	lines := []string{"http"}
+	if len(call.Body) > 0 {
+		if isTextBody(call) {
+			lines[0] += " --raw " + shellQuote(string(call.Body))
+		} else {
+			lines[0] += " @body.bin"
+		}
+	}
+	lines[0] += fmt.Sprintf(" %s %s", call.Method, shellQuote(call.Url))
+	for _, header := range snippetHeaders(call) {
+		lines = append(lines, shellQuote(header[0]+":"+header[1]))
+	}
+	return strings.Join(lines, " \\\n  ")
+}
+
+// GoSnippet 生成使用net/http发送请求的Go代码
+func GoSnippet(call *Call) string {
+	var b strings.Builder
+	b.WriteString("package main\n\nimport (\n\t\"fmt\"\n\t\"io\"\n\t\"net/http\"\n")
+	hasBody := len(call.Body) > 0 && isTextBody(call)
+	if hasBody {
+		b.WriteString("\t\"strings\"\n")
+	}
+	b.WriteString(")\n\nfunc main() {\n")
+	if hasBody {
+		fmt.Fprintf(&b, "\tbody := strings.NewReader(%q)\n", string(call.Body))
+		fmt.Fprintf(&b, "\trequest, err := http.NewRequest(%q, %q, body)\n", call.Method, call.Url)
+	} else {
+		if len(call.Body) > 0 {
+			fmt.Fprintf(&b, "\t// 请求体为二进制数据(%d字节),已省略\n", len(call.Body))
+		}
+		fmt.Fprintf(&b, "\trequest, err := http.NewRequest(%q, %q, nil)\n", call.Method, call.Url)
+	}
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	for _, header := range snippetHeaders(call) {
+		fmt.Fprintf(&b, "\trequest.Header.Set(%q, %q)\n", header[0], header[1])
+	}
+	b.WriteString("\tresponse, err := http.DefaultClient.Do(request)\n\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	b.WriteString("\tdefer response.Body.Close()\n\tdata, err := io.ReadAll(response.Body)\n\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	b.WriteString("\tfmt.Println(response.Status)\n\tfmt.Println(string(data))\n}")
+	return b.String()
+}
+
+// PostmanCollection 将请求记录导出为Postman Collection v2.1
+func PostmanCollection(name string, calls []*Call) ([]byte, error) {
+	type kv struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	var items []map[string]any
+	for i, call := range calls {
+		u, err := url.Parse(call.Url)
+		if err != nil {
+			return nil, err
+		}
+		var query []kv
+		if u.RawQuery != "" {
+			for _, pair := range strings.Split(u.RawQuery, "&") {
+				key, value, _ := strings.Cut(pair, "=")
+				query = append(query, kv{Key: key, Value: value})
+			}
+		}
+		var headers []kv
+		for _, header := range snippetHeaders(call) {
+			headers = append(headers, kv{Key: header[0], Value: header[1]})
+		}
+		request := map[string]any{
+			"method": call.Method,
+			"header": headers,
+			"url": map[string]any{
+				"raw":      call.Url,
+				"protocol": u.Scheme,
+				"host":     strings.Split(u.Hostname(), "."),
+				"port":     u.Port(),
+				"path":     strings.Split(strings.TrimPrefix(u.Path, "/"), "/"),
+				"query":    query,
+			},
+		}
+		if len(call.Body) > 0 && isTextBody(call) {
+			request["body"] = map[string]any{
+				"mode":    "raw",
+				"raw":     string(call.Body),
+				"options": map[string]any{"raw": map[string]any{"language": "json"}},
+			}
+		}
+		items = append(items, map[string]any{
+			"name":    fmt.Sprintf("%d %s %s", i+1, call.Method, u.Path),
+			"request": request,
+		})
+	}
+	collection := map[string]any{
+		"info": map[string]any{
+			"name":   name,
+			"schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json",
+		},
+		"item": items,
+	}
+	return json.MarshalIndent(collection, "", "  ")
+}
@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestMaskUrl(t *testing.T) {
+	for _, key := range secretKeys {
+		tests := []struct {
+			name string
+			raw  string
+		}{
+			{"单个参数", "https://qyapi.weixin.qq.com/cgi-bin/user/get?" + key + "=secret-value&userid=zhangsan"},
+			{"大写参数名", "https://qyapi.weixin.qq.com/cgi-bin/user/get?userid=zhangsan&" + strings.ToUpper(key) + "=secret-value"},
+			{"重复参数", "https://open.feishu.cn/open-apis/x?" + key + "=secret-value&" + key + "=secret-value&userid=zhangsan"},
+		}
+		for _, tt := range tests {
+			t.Run(key+"/"+tt.name, func(t *testing.T) {
+				got := maskUrl(tt.raw)
+				if strings.Contains(got, "secret-value") {
+					t.Fatalf("maskUrl() = %s, 凭证未隐藏", got)
+				}
+				u, err := url.Parse(got)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if u.Query().Get("userid") != "zhangsan" {
+					t.Errorf("maskUrl() = %s, 非凭证参数被修改", got)
+				}
+			})
+		}
+	}
+	for _, raw := range []string{"https://open.feishu.cn/open-apis/x", "https://open.feishu.cn/open-apis/x?page_size=50", "::"} {
+		if got := maskUrl(raw); got != raw {
+			t.Errorf("maskUrl(%q) = %q, 期望保持原样", raw, got)
+		}
+	}
+}
+
+func TestMaskBody(t *testing.T) {
+	for _, key := range secretKeys {
+		tests := []struct {
+			name string
+			body string
+		}{
+			{"顶层字段", `{"` + key + `":"secret-value","name":"keep"}`},
+			{"嵌套对象", `{"data":{"auth":{"` + key + `":"secret-value"}},"name":"keep"}`},
+			{"数组中的对象", `{"items":[{"name":"keep"},{"` + key + `":"secret-value"}],"name":"keep"}`},
+			{"顶层数组", `[{"` + key + `":"secret-value","name":"keep"}]`},
+			{"非字符串值", `{"` + key + `":{"value":"secret-value"},"name":"keep"}`},
+		}
+		for _, tt := range tests {
+			t.Run(key+"/"+tt.name, func(t *testing.T) {
+				got := maskBody([]byte(tt.body))
+				if bytes.Contains(got, []byte("secret-value")) {
+					t.Fatalf("maskBody() = %s, 凭证未隐藏", got)
+				}
+				if !bytes.Contains(got, []byte(maskedValue)) || !bytes.Contains(got, []byte(`"keep"`)) {
+					t.Errorf("maskBody() = %s", got)
+				}
+				if !json.Valid(got) {
+					t.Errorf("maskBody() = %s, 不是合法的JSON", got)
+				}
+			})
+		}
+	}
+	for _, body := range []string{"", "name=keep", "plain text", `{"name":"keep"`} {
+		if got := maskBody([]byte(body)); string(got) != body {
+			t.Errorf("maskBody(%q) = %q, 不含凭证的请求体期望保持原样", body, got)
+		}
+	}
+}
+
+func TestMaskFormBody(t *testing.T) {
+	for _, key := range secretKeys {
+		tests := []struct {
+			name string
+			body string
+			want string
+		}{
+			{"单个参数", key + "=secret-value", key + "=" + maskedValue},
+			{"保持参数顺序", "name=keep&" + key + "=secret-value&page=1", "name=keep&" + key + "=" + maskedValue + "&page=1"},
+			{"大写参数名", strings.ToUpper(key) + "=secret-value&name=keep", strings.ToUpper(key) + "=" + maskedValue + "&name=keep"},
+			{"编码后的值", key + "=secret-value%2B%2F&name=%E4%BF%9D%E7%95%99", key + "=" + maskedValue + "&name=%E4%BF%9D%E7%95%99"},
+		}
+		for _, tt := range tests {
+			t.Run(key+"/"+tt.name, func(t *testing.T) {
+				if got := string(maskBody([]byte(tt.body))); got != tt.want {
+					t.Errorf("maskBody() = %s, 期望 %s", got, tt.want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package wechat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/fasnow/ghttp"
+	"idebug/plugin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type CallReq struct {
+	req  *Req
+	body []byte
+}
+
+type CallReqBuilder struct {
+	req  *Req
+	body []byte
+}
+
+func NewCallReqBuilder(client *Client) *CallReqBuilder {
+	builder := &CallReqBuilder{}
+	builder.req = &Req{
+		Client:      client,
+		QueryParams: &plugin.QueryParams{},
+		PathParams:  &plugin.PathParams{},
+	}
+	return builder
+}
+
+// Method 请求方法,默认GET
+func (builder *CallReqBuilder) Method(method string) *CallReqBuilder {
+	builder.req.HttpMethod = strings.ToUpper(method)
+	return builder
+}
+
+// Path 接口路径,如/user/get,完整URL必须属于接口域名,不以http开头时会拼接至接口域名的/cgi-bin下
+func (builder *CallReqBuilder) Path(path string) *CallReqBuilder {
+	builder.req.ApiPath = path
+	return builder
+}
+
+// Query 追加查询参数
+func (builder *CallReqBuilder) Query(key, value string) *CallReqBuilder {
+	builder.req.QueryParams.Add(key, value)
+	return builder
+}
+
+// Body 原始请求体,按JSON发送
+func (builder *CallReqBuilder) Body(body []byte) *CallReqBuilder {
+	builder.body = body
+	return builder
+}
+
+func (builder *CallReqBuilder) Build() *CallReq {
+	req := &CallReq{}
+	req.req = builder.req
+	req.body = builder.body
+	return req
+}
+
+// ResolveUrl 将接口路径解析为完整的接口地址,完整URL必须与接口域名一致,避免凭证发送至其它主机
+func ResolveUrl(path string) (string, error) {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		if !plugin.SameOrigin(path, baseUrl) {
+			return "", fmt.Errorf("只能调用接口域名%s下的接口: %s", baseUrl, path)
+		}
+		return path, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if !strings.HasPrefix(path, "/cgi-bin/") {
+		path = "/cgi-bin" + path
+	}
+	return baseUrl + path, nil
+}
+
+// Call 使用access_token调用任意接口,provider模式使用provider_access_token,返回原始响应,不校验响应中的errcode
+func (client *Client) Call(req *CallReq) (*plugin.RawResponse, error) {
+	if req.req.ApiPath == "" {
+		return nil, errors.New("接口路径不能为空")
+	}
+	method := req.req.HttpMethod
+	if method == "" {
+		method = "GET"
+	}
+	// 优先使用set token设置的access_token
+	token := client.GetAccessTokenFromCache()
+	if token == "" {
+		var err error
+		token, err = client.getAccessTokenFromCache()
+		if err != nil {
+			return nil, err
+		}
+	}
+	// provider模式获取的是provider_access_token,服务商接口使用同名参数
+	tokenParam := "access_token"
+	if client.config.AuthMode == AuthModeProvider {
+		tokenParam = "provider_access_token"
+	}
+	if req.req.QueryParams.Get(tokenParam) == "" {
+		req.req.QueryParams.Set(tokenParam, token)
+	}
+	url, err := ResolveUrl(req.req.ApiPath)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(url, "?") {
+		url += "&" + req.req.QueryParams.Encode()
+	} else {
+		url += "?" + req.req.QueryParams.Encode()
+	}
+	request, err := http.NewRequest(method, url, bytes.NewReader(req.body))
+	if err != nil {
+		return nil, err
+	}
+	if len(req.body) > 0 {
+		request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	start := time.Now()
+	response, err := client.http.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ghttp.GetResponseBody(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.RawResponse{
+		Method:     method,
+		Url:        url,
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Header:     response.Header,
+		Body:       body,
+		Duration:   time.Since(start),
+	}, nil
+}
@@ -90,7 +90,7 @@ func (d *department) Get(req *GetDepartmentReq) (*DepartmentEntry, error) {
 		return nil, err
 	}
 	if tmp.ErrCode != 0 {
-		return nil, fmt.Errorf("from server - " + tmp.ErrMsg)
+		return nil, plugin.NewWechatError(tmp.ErrCode, tmp.ErrMsg, response)
 	}
 	return &tmp.Department, nil
 }
@@ -162,7 +162,7 @@ func (d *department) GetList(req *GetDepartmentListReq) ([]*DepartmentEntry, err
 		return nil, err
 	}
 	if tmp.ErrCode != 0 {
-		return nil, fmt.Errorf("from server - " + tmp.ErrMsg)
+		return nil, plugin.NewWechatError(tmp.ErrCode, tmp.ErrMsg, response)
 	}
 	return tmp.Department, nil
 }
@@ -234,7 +234,7 @@ func (d *department) GetIdList(req *GetDepartmentIdListReq) ([]*DepartmentEntryS
 		return nil, err
 	}
 	if res.ErrCode != 0 {
-		return res.Department, fmt.Errorf("from server - " + res.ErrMsg)
+		return res.Department, plugin.NewWechatError(res.ErrCode, res.ErrMsg, response)
 	}
 	return res.Department, nil
 }
@@ -142,7 +142,7 @@ func (u *user) Get(req *GetUserReq) (*UserEntry, error) {
 		return nil, err
 	}
 	if res.ErrCode != 0 {
-		return res.UserEntry, fmt.Errorf("from server - " + res.ErrMsg)
+		return res.UserEntry, plugin.NewWechatError(res.ErrCode, res.ErrMsg, response)
 	}
 	return res.UserEntry, nil
 }
@@ -224,7 +224,7 @@ func (u *user) GetUsersByDepartmentId(req *GetUsersByDepartmentIdReq) ([]*UserEn
 		return nil, err
 	}
 	if res.ErrCode != 0 {
-		return res.UserList, fmt.Errorf("from server - " + res.ErrMsg)
+		return res.UserList, plugin.NewWechatError(res.ErrCode, res.ErrMsg, response)
 	}
 	return res.UserList, nil
 }
@@ -306,7 +306,7 @@ func (u *user) GetUsersSimplifiedByDepartmentId(req *GetUsersSimplifiedByDepartm
 		return nil, err
 	}
 	if res.ErrCode != 0 {
-		return res.UserList, fmt.Errorf("from server - " + res.ErrMsg)
+		return res.UserList, plugin.NewWechatError(res.ErrCode, res.ErrMsg, response)
 	}
 	return res.UserList, nil
 }
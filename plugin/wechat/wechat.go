@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/fasnow/ghttp"
+	"idebug/plugin"
 	"idebug/utils"
 	"net/http"
 	"net/url"
@@ -57,6 +58,15 @@ type apiConfig struct {
 
 	// 获取企业微信接口IP段 ?access_token=ACCESS_TOKEN
 	getAPIDomainCIDRUrl string
+
+	// 获取第三方应用凭证 POST {suite_id,suite_secret,suite_ticket}
+	getSuiteTokenUrl string
+
+	// 获取授权企业凭证 ?suite_access_token=SUITE_ACCESS_TOKEN POST {auth_corpid,permanent_code}
+	getCorpTokenUrl string
+
+	// 获取服务商凭证 POST {corpid,provider_secret}
+	getProviderTokenUrl string
 }
 
 var (
@@ -81,6 +91,9 @@ func initApi(baseDomain string) apiConfig {
 		getUserIDByPhone:           baseDomain + "/cgi-bin/user/getuserid",
 		getUserIDByEmail:           baseDomain + "/cgi-bin/user/get_userid_by_email",
 		getAPIDomainCIDRUrl:        baseDomain + "/cgi-bin/get_api_domain_ip",
+		getSuiteTokenUrl:           baseDomain + "/cgi-bin/service/get_suite_token",
+		getCorpTokenUrl:            baseDomain + "/cgi-bin/service/get_corp_token",
+		getProviderTokenUrl:        baseDomain + "/cgi-bin/service/get_provider_token",
 	}
 }
 
@@ -125,11 +138,32 @@ type AccessTokenAuthScope struct {
 	RoleName  string `json:"rolename"`  // 应用名称
 	item      *AccessTokenAuthItem
 }
+
+// AuthMode access_token的获取方式
+type AuthMode string
+
+const (
+	AuthModeCorp     AuthMode = "corp"     // 企业自建应用,corpid+corpsecret
+	AuthModeSuite    AuthMode = "suite"    // 第三方应用,suite_access_token+permanent_code换取授权企业access_token
+	AuthModeProvider AuthMode = "provider" // 服务商,corpid+provider_secret获取provider_access_token
+)
+
+var AuthModes = []AuthMode{AuthModeCorp, AuthModeSuite, AuthModeProvider}
+
 type config struct {
-	CorpId      *string
-	CorpSecret  *string
-	AccessToken *string
-	ExpireIn    *int
+	AuthMode            AuthMode
+	CorpId              *string // 企业ID,provider模式下为服务商corpid
+	CorpSecret          *string
+	SuiteId             *string // 第三方应用suite_id
+	SuiteSecret         *string // 第三方应用suite_secret
+	SuiteTicket         *string // 企业微信后台推送的suite_ticket
+	AuthCorpId          *string // 授权企业corpid
+	PermanentCode       *string // 授权企业永久授权码
+	ProviderSecret      *string // 服务商secret
+	SuiteAccessToken    *string
+	ProviderAccessToken *string
+	AccessToken         *string
+	ExpireIn            *int
 	//authScope   *AccessTokenAuthScope
 }
 
@@ -146,19 +180,20 @@ type Client struct {
 	Department *department
 	User       *user
 	cache      *utils.Cache // 保存access_token
-	http       *ghttp.Client
+	http       *plugin.HttpClient
 }
 
 func NewWxClient() *Client {
 	client := &Client{
-		config:     &config{},
+		config:     &config{AuthMode: AuthModeCorp},
 		cache:      utils.NewCache(3 * time.Second),
-		http:       &ghttp.Client{},
+		http:       plugin.NewHttpClient(plugin.PlatformWechat),
 		User:       &user{},
 		Department: &department{},
 	}
 	client.User.client = client
 	client.Department.client = client
+	client.http.RefreshToken = client.refreshToken
 	return client
 }
 
@@ -170,37 +205,111 @@ func (client *Client) StopWhenContextCanceled(enable bool) {
 	client.http.StopWhenContextCanceled = enable
 }
 
+// Limiter 返回请求限速器,用于调整各接口类别的QPS
+func (client *Client) Limiter() *plugin.RateLimiter {
+	return client.http.Limiter
+}
+
+// RetryPolicy 返回请求重试策略,用于调整重试次数和总时长
+func (client *Client) RetryPolicy() *plugin.RetryPolicy {
+	return client.http.Retry
+}
+
 func (client *Client) Set(corpId, corpSecret string) {
-	conf := &config{
-		CorpId:     &corpId,
-		CorpSecret: &corpSecret,
-	}
-	client.config = conf
-	client.cache = utils.NewCache(3 * time.Second)
+	client.updateConfig(func(conf *config) {
+		conf.CorpId = &corpId
+		conf.CorpSecret = &corpSecret
+	})
 }
 
 func (client *Client) SetCorpId(corpId string) {
-	conf := &config{
-		CorpId:     &corpId,
-		CorpSecret: client.config.CorpSecret,
-	}
-	client.config = conf
-	client.cache = utils.NewCache(3 * time.Second)
+	client.updateConfig(func(conf *config) {
+		conf.CorpId = &corpId
+	})
 }
 
 func (client *Client) SetAccessToken(token string) {
-	client.config = &config{AccessToken: &token}
+	client.config = &config{AuthMode: client.config.AuthMode, AccessToken: &token}
 }
 
 func (client *Client) SetCorpSecret(corpSecret string) {
-	conf := &config{
-		CorpId:     client.config.CorpId,
-		CorpSecret: &corpSecret,
+	client.updateConfig(func(conf *config) {
+		conf.CorpSecret = &corpSecret
+	})
+}
+
+// SetAuthMode 切换access_token的获取方式,已获取的凭证会失效
+func (client *Client) SetAuthMode(mode AuthMode) error {
+	for _, m := range AuthModes {
+		if m == mode {
+			client.updateConfig(func(conf *config) {
+				conf.AuthMode = mode
+			})
+			return nil
+		}
 	}
+	return fmt.Errorf("未知的认证模式: %s", mode)
+}
+
+func (client *Client) SetSuiteId(suiteId string) {
+	client.updateConfig(func(conf *config) {
+		conf.SuiteId = &suiteId
+	})
+}
+
+func (client *Client) SetSuiteSecret(suiteSecret string) {
+	client.updateConfig(func(conf *config) {
+		conf.SuiteSecret = &suiteSecret
+	})
+}
+
+func (client *Client) SetSuiteTicket(suiteTicket string) {
+	client.updateConfig(func(conf *config) {
+		conf.SuiteTicket = &suiteTicket
+	})
+}
+
+func (client *Client) SetAuthCorpId(authCorpId string) {
+	client.updateConfig(func(conf *config) {
+		conf.AuthCorpId = &authCorpId
+	})
+}
+
+func (client *Client) SetPermanentCode(permanentCode string) {
+	client.updateConfig(func(conf *config) {
+		conf.PermanentCode = &permanentCode
+	})
+}
+
+func (client *Client) SetProviderSecret(providerSecret string) {
+	client.updateConfig(func(conf *config) {
+		conf.ProviderSecret = &providerSecret
+	})
+}
+
+// updateConfig 在当前认证参数的副本上修改并替换,同时清空已获取的凭证
+func (client *Client) updateConfig(update func(conf *config)) {
+	conf := client.copyConfig()
+	update(conf)
 	client.config = conf
 	client.cache = utils.NewCache(3 * time.Second)
 }
 
+// copyConfig 复制当前的认证参数,不包含已获取的凭证
+func (client *Client) copyConfig() *config {
+	return &config{
+		AuthMode:       client.config.AuthMode,
+		CorpId:         client.config.CorpId,
+		CorpSecret:     client.config.CorpSecret,
+		SuiteId:        client.config.SuiteId,
+		SuiteSecret:    client.config.SuiteSecret,
+		SuiteTicket:    client.config.SuiteTicket,
+		AuthCorpId:     client.config.AuthCorpId,
+		PermanentCode:  client.config.PermanentCode,
+		ProviderSecret: client.config.ProviderSecret,
+	}
+}
+
 func (client *Client) GetAccessToken() (string, error) {
 	token, err := client.getAccessTokenFromCache()
 	if err != nil {
@@ -246,7 +355,41 @@ func (client *Client) getAccessTokenFromCache() (string, error) {
 	return "", errors.New("获取access_token时出错")
 }
 
+// refreshToken access_token或provider_access_token失效时重新获取并写入请求参数
+func (client *Client) refreshToken(request *http.Request) bool {
+	query := request.URL.Query()
+	key := "access_token"
+	if query.Get(key) == "" {
+		key = "provider_access_token"
+	}
+	if query.Get(key) == "" {
+		return false
+	}
+	token, err := client.GetAccessTokenFromServer()
+	if err != nil {
+		return false
+	}
+	query.Set(key, token)
+	request.URL.RawQuery = query.Encode()
+	return true
+}
+
+// getAccessToken 根据认证模式获取调用通讯录等接口使用的access_token
 func (client *Client) getAccessToken() (string, int, error) {
+	switch client.config.AuthMode {
+	case AuthModeSuite:
+		return client.getAuthCorpAccessToken()
+	case AuthModeProvider:
+		return client.getProviderAccessToken()
+	default:
+		return client.getCorpAccessToken()
+	}
+}
+
+func (client *Client) getCorpAccessToken() (string, int, error) {
+	if client.config.CorpId == nil || client.config.CorpSecret == nil {
+		return "", 0, errors.New("请先设置corpid和corpsecret")
+	}
 	params := url.Values{}
 	params.Add("corpid", *client.config.CorpId)
 	params.Add("corpsecret", *client.config.CorpSecret)
@@ -255,28 +398,122 @@ func (client *Client) getAccessToken() (string, int, error) {
 		return "", 0, err
 	}
 	request.Header.Set("User-Agent", "")
+	res, err := client.doTokenRequest(request)
+	if err != nil {
+		return "", 0, err
+	}
+	return res.AccessToken, res.ExpiresIn, nil
+}
+
+// getSuiteAccessToken 获取第三方应用凭证,有缓存则使用缓存
+func (client *Client) getSuiteAccessToken() (string, error) {
+	if value, ok := client.cache.Get("suiteAccessToken"); ok {
+		if token, ok := value.(string); ok {
+			return token, nil
+		}
+	}
+	if client.config.SuiteId == nil || client.config.SuiteSecret == nil || client.config.SuiteTicket == nil {
+		return "", errors.New("请先设置suiteid、suitesecret和suiteticket")
+	}
+	postData := map[string]string{
+		"suite_id":     *client.config.SuiteId,
+		"suite_secret": *client.config.SuiteSecret,
+		"suite_ticket": *client.config.SuiteTicket,
+	}
+	request, err := http.NewRequest("POST", api.getSuiteTokenUrl, utils.ConvertToReader(postData))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res, err := client.doTokenRequest(request)
+	if err != nil {
+		return "", err
+	}
+	client.cache.Set("suiteAccessToken", res.SuiteAccessToken, time.Duration(res.ExpiresIn)*time.Second)
+	client.config.SuiteAccessToken = &res.SuiteAccessToken
+	return res.SuiteAccessToken, nil
+}
+
+// getAuthCorpAccessToken 使用suite_access_token和永久授权码获取授权企业的access_token
+func (client *Client) getAuthCorpAccessToken() (string, int, error) {
+	if client.config.AuthCorpId == nil || client.config.PermanentCode == nil {
+		return "", 0, errors.New("请先设置authcorpid和permanentcode")
+	}
+	suiteToken, err := client.getSuiteAccessToken()
+	if err != nil {
+		return "", 0, err
+	}
+	params := url.Values{}
+	params.Add("suite_access_token", suiteToken)
+	postData := map[string]string{
+		"auth_corpid":    *client.config.AuthCorpId,
+		"permanent_code": *client.config.PermanentCode,
+	}
+	request, err := http.NewRequest("POST", fmt.Sprintf("%s?%s", api.getCorpTokenUrl, params.Encode()), utils.ConvertToReader(postData))
+	if err != nil {
+		return "", 0, err
+	}
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res, err := client.doTokenRequest(request)
+	if err != nil {
+		return "", 0, err
+	}
+	return res.AccessToken, res.ExpiresIn, nil
+}
+
+// getProviderAccessToken 获取服务商凭证,该凭证仅能调用服务商相关接口
+func (client *Client) getProviderAccessToken() (string, int, error) {
+	if client.config.CorpId == nil || client.config.ProviderSecret == nil {
+		return "", 0, errors.New("请先设置corpid和providersecret")
+	}
+	postData := map[string]string{
+		"corpid":          *client.config.CorpId,
+		"provider_secret": *client.config.ProviderSecret,
+	}
+	request, err := http.NewRequest("POST", api.getProviderTokenUrl, utils.ConvertToReader(postData))
+	if err != nil {
+		return "", 0, err
+	}
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res, err := client.doTokenRequest(request)
+	if err != nil {
+		return "", 0, err
+	}
+	client.config.ProviderAccessToken = &res.ProviderAccessToken
+	return res.ProviderAccessToken, res.ExpiresIn, nil
+}
+
+type tokenResponse struct {
+	ErrCode             *int   `json:"errcode"`
+	ErrMsg              string `json:"errmsg"`
+	AccessToken         string `json:"access_token"`
+	SuiteAccessToken    string `json:"suite_access_token"`
+	ProviderAccessToken string `json:"provider_access_token"`
+	ExpiresIn           int    `json:"expires_in"`
+}
+
+func (client *Client) doTokenRequest(request *http.Request) (*tokenResponse, error) {
 	response, err := client.http.Do(request)
+	if err != nil {
+		return nil, err
+	}
 	if response.StatusCode != 200 {
-		return "", 0, errors.New(response.Status)
+		return nil, errors.New(response.Status)
 	}
 	body, err := ghttp.GetResponseBody(response.Body)
 	if err != nil {
-		return "", 0, err
-	}
-	var res struct {
-		ErrCode     *int    `json:"errcode"`
-		ErrMsg      *string `json:"errmsg"`
-		AccessToken *string `json:"access_token"`
-		ExpiresIn   *int    `json:"expires_in"`
+		return nil, err
 	}
+	var res tokenResponse
 	err = json.Unmarshal(body, &res)
 	if err != nil {
-		return "", 0, errors.New("获取 access_token 时出错")
+		return nil, errors.New("获取 access_token 时出错")
 	}
-	if *res.ErrCode != 0 {
-		return "", 0, fmt.Errorf("from server - " + *res.ErrMsg)
+	// get_corp_token等接口成功时可能不返回errcode
+	if res.ErrCode != nil && *res.ErrCode != 0 {
+		return nil, plugin.NewWechatError(*res.ErrCode, res.ErrMsg, response)
 	}
-	return *res.AccessToken, *res.ExpiresIn, nil
+	return &res, nil
 }
 
 func (client *Client) GetConfig() *config {
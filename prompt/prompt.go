@@ -28,7 +28,17 @@ func (client *Client) Run() {
 	cmd.Proxy = client.proxy
 	*client.module = cmd.NoModule
 	cmd.CurrentModule = client.module
-	line, err := readline.NewEx(&readline.Config{})
+	line, err := readline.NewEx(&readline.Config{
+		AutoComplete: readline.NewPrefixCompleter(
+			readline.PcItem("api",
+				readline.PcItem("ls"),
+				readline.PcItem("reload"),
+				readline.PcItemDynamic(func(string) []string {
+					return cmd.CatalogNames(*client.module)
+				}),
+			),
+		),
+	})
 	if err != nil {
 		logger.Error(logger.FormatError(err))
 		return
@@ -49,18 +59,12 @@ func (client *Client) Run() {
 	}
 }
 
+// executor 执行一条命令,Ctrl+C只取消上下文,命令保存中断前的结果并返回后才读取下一行,
+// 避免上一条命令仍在写入时下一条命令重置全局变量
 func (client *Client) executor(in string) {
 	cmd.SetContext()
-	go func() {
-		client.exec(in)
-		cmd.Cancel()
-	}()
-	for {
-		select {
-		case <-cmd.Context.Done():
-			return
-		}
-	}
+	defer cmd.Cancel()
+	client.exec(in)
 }
 
 func (client *Client) exec(in string) {
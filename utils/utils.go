@@ -14,6 +14,22 @@ func IsFileExists(filename string) bool {
 	return err == nil
 }
 
+// ReadLines 按行读取文件,忽略空行和首尾空白
+func ReadLines(filename string) ([]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
 func ConvertToReader(data any) io.Reader {
 	marshal, err := json.Marshal(data)
 	if err != nil {